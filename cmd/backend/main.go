@@ -96,7 +96,7 @@ func run() error {
 	}
 
 	// Initialize scheduler (token will be retrieved from context on first API call)
-	maxConcurrent := 5 // Default max concurrent renders
+	maxConcurrent := 5 // Default worker pool size; the main org's settings can change it at runtime
 	log.Printf("Initializing scheduler (max concurrent: %d)", maxConcurrent)
 	scheduler := cron.NewScheduler(st, grafanaURL, artifactsPath, maxConcurrent)
 
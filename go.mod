@@ -3,6 +3,7 @@ module github.com/yourusername/sheduled-reports-app
 go 1.24.6
 
 require (
+	github.com/SebastiaanKlippert/go-wkhtmltopdf v1.9.3
 	github.com/go-rod/rod v0.116.2
 	github.com/grafana/grafana-plugin-sdk-go v0.280.0
 	github.com/jung-kurt/gofpdf v1.16.2
@@ -12,7 +13,6 @@ require (
 )
 
 require (
-	github.com/SebastiaanKlippert/go-wkhtmltopdf v1.9.3 // indirect
 	github.com/apache/arrow-go/v18 v18.4.1 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cenkalti/backoff/v5 v5.0.3 // indirect
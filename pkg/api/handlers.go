@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -11,11 +12,15 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/yourusername/sheduled-reports-app/pkg/calendar"
+	"github.com/yourusername/sheduled-reports-app/pkg/condition"
 	"github.com/yourusername/sheduled-reports-app/pkg/cron"
 	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
 	"github.com/yourusername/sheduled-reports-app/pkg/store"
 )
 
@@ -44,8 +49,12 @@ func NewHandler(st *store.Store, scheduler *cron.Scheduler) *Handler {
 func (h *Handler) registerRoutes() {
 	h.mux.HandleFunc("/api/schedules", h.handleSchedules)
 	h.mux.HandleFunc("/api/schedules/", h.handleSchedule)
+	h.mux.HandleFunc("/api/schedules/preview-times", h.handlePreviewTimes)
 	h.mux.HandleFunc("/api/runs/", h.handleRun)
+	h.mux.HandleFunc("/api/triggers/", h.handleTrigger)
 	h.mux.HandleFunc("/api/settings", h.handleSettings)
+	h.mux.HandleFunc("/api/calendars", h.handleCalendars)
+	h.mux.HandleFunc("/api/calendars/", h.handleCalendar)
 }
 
 // CallResource implements backend.CallResourceHandler
@@ -85,14 +94,23 @@ func (h *Handler) handleSchedules(w http.ResponseWriter, r *http.Request) {
 		schedule.OrgID = orgID
 		schedule.OwnerUserID = getUserID(r)
 
+		if err := h.validateSchedule(&schedule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		// Calculate and set next run time
-		nextRun := h.scheduler.CalculateNextRun(&schedule)
-		schedule.NextRunAt = &nextRun
+		schedule.NextRunAt = h.scheduler.CalculateNextRun(&schedule)
+		if schedule.Enabled && schedule.NextRunAt == nil {
+			http.Error(w, "schedule has no occurrences left before its end date or occurrence limit", http.StatusBadRequest)
+			return
+		}
 
 		if err := h.store.CreateSchedule(&schedule); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		h.scheduler.TrackSchedule(&schedule)
 
 		respondJSON(w, schedule)
 
@@ -110,7 +128,8 @@ func (h *Handler) handleSchedule(w http.ResponseWriter, r *http.Request) {
 	var scheduleID int64
 	var action string
 
-	// Path format: /api/schedules/{id} or /api/schedules/{id}/runs or /api/schedules/{id}/run
+	// Path format: /api/schedules/{id}, /api/schedules/{id}/{runs,run,next,backfill,backfills,triggers}
+	// or /api/schedules/{id}/triggers/{tokenId}
 	if _, err := fmt.Sscanf(path, "/api/schedules/%d/%s", &scheduleID, &action); err != nil {
 		// Try without action
 		if _, err := fmt.Sscanf(path, "/api/schedules/%d", &scheduleID); err != nil {
@@ -127,13 +146,28 @@ func (h *Handler) handleSchedule(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		h.scheduler.ExecuteSchedule(schedule)
-		respondJSON(w, map[string]string{"status": "started"})
+		run, err := h.scheduler.ExecuteSchedule(schedule)
+		if errors.Is(err, store.ErrDuplicateOccurrence) {
+			http.Error(w, "This occurrence of the schedule is already queued", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := h.setQueuePositions(run); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]interface{}{"status": "queued", "run_id": run.ID, "queue_position": run.QueuePosition})
 		return
 	}
 
 	if action == "runs" && r.Method == http.MethodGet {
 		runs, err := h.store.ListRuns(orgID, scheduleID)
+		if err == nil {
+			err = h.setQueuePositions(runs...)
+		}
 		if err != nil {
 			fmt.Printf("Error loading runs for schedule %d, org %d: %v\n", scheduleID, orgID, err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -143,6 +177,61 @@ func (h *Handler) handleSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if action == "backfill" && r.Method == http.MethodPost {
+		schedule, err := h.store.GetSchedule(orgID, scheduleID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		var req struct {
+			From      time.Time `json:"from"`
+			To        time.Time `json:"to"`
+			SendEmail bool      `json:"send_email"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		backfill, runs, err := h.scheduler.Backfill(schedule, req.From, req.To, req.SendEmail)
+		if errors.Is(err, cron.ErrInvalidBackfill) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]interface{}{"backfill": backfill, "runs": runs})
+		return
+	}
+
+	if action == "backfills" && r.Method == http.MethodGet {
+		backfills, err := h.store.ListBackfills(orgID, scheduleID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]interface{}{"backfills": backfills})
+		return
+	}
+
+	if action == "triggers" || strings.HasPrefix(action, "triggers/") {
+		h.handleTriggerTokens(w, r, orgID, scheduleID, strings.TrimPrefix(action, "triggers"))
+		return
+	}
+
+	if action == "next" && r.Method == http.MethodGet {
+		schedule, err := h.store.GetSchedule(orgID, scheduleID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.respondUpcomingRuns(w, r, schedule)
+		return
+	}
+
 	// Handle CRUD operations
 	switch r.Method {
 	case http.MethodGet:
@@ -163,22 +252,45 @@ func (h *Handler) handleSchedule(w http.ResponseWriter, r *http.Request) {
 		schedule.ID = scheduleID
 		schedule.OrgID = orgID
 
+		if err := h.validateSchedule(&schedule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The occurrence count is maintained by the scheduler
+		existing, err := h.store.GetSchedule(orgID, scheduleID)
+		if err != nil {
+			http.Error(w, "Schedule not found", http.StatusNotFound)
+			return
+		}
+		schedule.OccurrenceCount = existing.OccurrenceCount
+
 		// Recalculate next run time if interval or cron expression changed
-		nextRun := h.scheduler.CalculateNextRun(&schedule)
-		schedule.NextRunAt = &nextRun
+		schedule.NextRunAt = h.scheduler.CalculateNextRun(&schedule)
+		if schedule.Enabled && schedule.NextRunAt == nil {
+			http.Error(w, "schedule has no occurrences left before its end date or occurrence limit", http.StatusBadRequest)
+			return
+		}
 
 		if err := h.store.UpdateSchedule(&schedule); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		h.scheduler.TrackSchedule(&schedule)
 
 		respondJSON(w, schedule)
 
 	case http.MethodDelete:
+		// Only schedules of this org are deleted, so only their timers are dropped
+		_, err := h.store.GetSchedule(orgID, scheduleID)
+		owned := err == nil
 		if err := h.store.DeleteSchedule(orgID, scheduleID); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if owned {
+			h.scheduler.UntrackSchedule(scheduleID)
+		}
 		w.WriteHeader(http.StatusNoContent)
 
 	default:
@@ -186,6 +298,52 @@ func (h *Handler) handleSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handlePreviewTimes lists the upcoming fire times of a schedule that has not been saved (yet), so
+// the timing can be checked while editing it
+func (h *Handler) handlePreviewTimes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var schedule model.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule.OrgID = getOrgID(r)
+
+	if err := cron.ValidateTiming(&schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := cron.ValidateWindow(&schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.respondUpcomingRuns(w, r, &schedule)
+}
+
+// respondUpcomingRuns responds with the next ?count= (default 5, at most 50) fire times of a schedule
+func (h *Handler) respondUpcomingRuns(w http.ResponseWriter, r *http.Request, schedule *model.Schedule) {
+	count := 5
+	if value := r.URL.Query().Get("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 50 {
+			http.Error(w, "count must be between 1 and 50", http.StatusBadRequest)
+			return
+		}
+		count = parsed
+	}
+
+	upcoming, err := h.scheduler.UpcomingRuns(schedule, count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, map[string]interface{}{"timezone": schedule.Timezone, "runs": upcoming})
+}
+
 // handleRun handles run-related operations
 func (h *Handler) handleRun(w http.ResponseWriter, r *http.Request) {
 	orgID := getOrgID(r)
@@ -194,9 +352,26 @@ func (h *Handler) handleRun(w http.ResponseWriter, r *http.Request) {
 	var runID int64
 	var action string
 
-	// Path format: /api/runs/{id}/artifact
+	// Path format: /api/runs/{id}, /api/runs/{id}/artifact or /api/runs/{id}/cancel
 	if _, err := fmt.Sscanf(path, "/api/runs/%d/%s", &runID, &action); err != nil {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		// Try without action
+		if _, err := fmt.Sscanf(path, "/api/runs/%d", &runID); err != nil {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if action == "" && r.Method == http.MethodGet {
+		run, err := h.store.GetRun(orgID, runID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err := h.setQueuePositions(run); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, run)
 		return
 	}
 
@@ -249,9 +424,178 @@ func (h *Handler) handleRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if action == "cancel" && r.Method == http.MethodPost {
+		if _, err := h.store.GetRun(orgID, runID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		cancelled, err := h.scheduler.CancelRun(orgID, runID)
+		if errors.Is(err, store.ErrRunNotActive) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Running runs are marked cancelled by the instance executing them once they stopped
+		status := "cancelling"
+		if cancelled {
+			status = "cancelled"
+		}
+		respondJSON(w, map[string]interface{}{"status": status, "run_id": runID})
+		return
+	}
+
 	http.Error(w, "Invalid action", http.StatusBadRequest)
 }
 
+// handleTriggerTokens lists, creates and revokes the trigger tokens of a schedule; rest is the path
+// after "triggers", i.e. "" or "/{tokenId}"
+func (h *Handler) handleTriggerTokens(w http.ResponseWriter, r *http.Request, orgID, scheduleID int64, rest string) {
+	if _, err := h.store.GetSchedule(orgID, scheduleID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			tokens, err := h.store.ListTriggerTokens(orgID, scheduleID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			respondJSON(w, map[string]interface{}{"tokens": tokens})
+
+		case http.MethodPost:
+			var token model.TriggerToken
+			if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			token.Name = strings.TrimSpace(token.Name)
+			if token.Name == "" {
+				http.Error(w, "name is required", http.StatusBadRequest)
+				return
+			}
+			token.ScheduleID = scheduleID
+			token.OrgID = orgID
+
+			if err := h.store.CreateTriggerToken(&token); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			respondJSON(w, token)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	var tokenID int64
+	if _, err := fmt.Sscanf(rest, "/%d", &tokenID); err != nil {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.store.DeleteTriggerToken(orgID, scheduleID, tokenID); err != nil {
+		if errors.Is(err, store.ErrTriggerNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTrigger handles POST /api/triggers/{token}, which queues a run of the schedule the token
+// belongs to. The body is optional and may override the run's time range and variables.
+func (h *Handler) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := strings.TrimPrefix(r.URL.Path, "/api/triggers/")
+	if secret == "" || strings.Contains(secret, "/") {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	var req model.TriggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Tokens of other orgs are not found, so the caller's Grafana credentials must belong to the
+	// schedule's org
+	token, err := h.store.FindTriggerToken(getOrgID(r), secret)
+	if errors.Is(err, store.ErrTriggerNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	schedule, err := h.store.GetSchedule(token.OrgID, token.ScheduleID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	run, err := h.scheduler.TriggerSchedule(schedule, token, req)
+	if errors.Is(err, cron.ErrInvalidTrigger) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, cron.ErrScheduleDisabled) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.MarkTriggerTokenUsed(token); err != nil {
+		log.Printf("Failed to record the use of trigger token %d: %v", token.ID, err)
+	}
+	if err := h.setQueuePositions(run); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, map[string]interface{}{
+		"status":         "queued",
+		"run_id":         run.ID,
+		"schedule_id":    schedule.ID,
+		"queue_position": run.QueuePosition,
+	})
+}
+
+// setQueuePositions fills in the queue position of the runs still waiting in the queue
+func (h *Handler) setQueuePositions(runs ...*model.Run) error {
+	for _, run := range runs {
+		if run.Status != "queued" {
+			continue
+		}
+		position, err := h.store.QueuePosition(run.ID)
+		if err != nil {
+			return err
+		}
+		run.QueuePosition = position
+	}
+	return nil
+}
+
 // handleSettings handles settings operations
 func (h *Handler) handleSettings(w http.ResponseWriter, r *http.Request) {
 	orgID := getOrgID(r)
@@ -281,6 +625,7 @@ func (h *Handler) handleSettings(w http.ResponseWriter, r *http.Request) {
 					MaxAttachmentSizeMB:  25,
 					MaxConcurrentRenders: 5,
 					RetentionDays:        30,
+					RunTimeoutSeconds:    600,
 				},
 			}
 		}
@@ -295,10 +640,28 @@ func (h *Handler) handleSettings(w http.ResponseWriter, r *http.Request) {
 
 		settings.OrgID = orgID
 
+		if err := cron.ValidateRetryPolicy(settings.RetryPolicy); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := cron.ValidateRunTimeout(settings.Limits.RunTimeoutSeconds); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := cron.ValidateSpread(settings.Limits.SpreadMinutes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := cron.ValidateConcurrency(settings.Limits); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		if err := h.store.UpsertSettings(&settings); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		h.scheduler.ApplySettings(&settings)
 
 		respondJSON(w, settings)
 
@@ -307,6 +670,209 @@ func (h *Handler) handleSettings(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// validateSchedule checks the timing, delivery options and condition of a schedule and that its
+// calendar belongs to the org
+func (h *Handler) validateSchedule(schedule *model.Schedule) error {
+	if err := cron.ValidateTiming(schedule); err != nil {
+		return err
+	}
+	if err := cron.ValidateSkipUnchanged(schedule.SkipUnchanged); err != nil {
+		return err
+	}
+	if err := cron.ValidateRetryPolicy(schedule.RetryPolicy); err != nil {
+		return err
+	}
+	if err := cron.ValidateRunTimeout(schedule.RunTimeoutSeconds); err != nil {
+		return err
+	}
+	if err := cron.ValidateWindow(schedule); err != nil {
+		return err
+	}
+	if err := cron.ValidateSpread(schedule.SpreadMinutes); err != nil {
+		return err
+	}
+	settings, err := h.store.GetSettings(schedule.OrgID)
+	if err != nil {
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	backend := render.BackendChromium
+	if settings != nil && settings.RendererConfig.Backend != "" {
+		backend = render.BackendType(settings.RendererConfig.Backend)
+	}
+	if err := cron.ValidatePanels(schedule, backend); err != nil {
+		return err
+	}
+	if schedule.CalendarID != nil {
+		if _, err := h.store.GetCalendar(schedule.OrgID, *schedule.CalendarID); err != nil {
+			return fmt.Errorf("invalid calendar_id %d: %w", *schedule.CalendarID, err)
+		}
+	}
+	if schedule.Condition != nil {
+		if err := condition.Validate(schedule.Condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleCalendars handles GET /api/calendars and POST /api/calendars
+func (h *Handler) handleCalendars(w http.ResponseWriter, r *http.Request) {
+	orgID := getOrgID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		calendars, err := h.store.ListCalendars(orgID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]interface{}{"calendars": calendars})
+
+	case http.MethodPost:
+		var cal model.Calendar
+		if err := json.NewDecoder(r.Body).Decode(&cal); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cal.OrgID = orgID
+		if err := calendar.Validate(&cal); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		calendar.Merge(&cal, nil, nil)
+
+		if err := h.store.CreateCalendar(&cal); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, cal)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCalendar handles operations on a specific calendar
+func (h *Handler) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	orgID := getOrgID(r)
+	path := r.URL.Path
+
+	var calendarID int64
+	var action string
+
+	// Path format: /api/calendars/{id} or /api/calendars/{id}/import
+	if _, err := fmt.Sscanf(path, "/api/calendars/%d/%s", &calendarID, &action); err != nil {
+		if _, err := fmt.Sscanf(path, "/api/calendars/%d", &calendarID); err != nil {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Import holidays from an iCalendar file sent as the request body
+	if action == "import" && r.Method == http.MethodPost {
+		cal, err := h.store.GetCalendar(orgID, calendarID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		holidays, blackouts, err := calendar.ParseICS(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid iCalendar file: %v", err), http.StatusBadRequest)
+			return
+		}
+		addedHolidays, addedBlackouts := calendar.Merge(cal, holidays, blackouts)
+
+		if err := h.store.UpdateCalendar(cal); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.rescheduleCalendar(orgID, calendarID, nil)
+
+		respondJSON(w, map[string]interface{}{
+			"calendar":        cal,
+			"added_holidays":  addedHolidays,
+			"added_blackouts": addedBlackouts,
+		})
+		return
+	}
+
+	if action != "" {
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		cal, err := h.store.GetCalendar(orgID, calendarID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, cal)
+
+	case http.MethodPut:
+		var cal model.Calendar
+		if err := json.NewDecoder(r.Body).Decode(&cal); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cal.ID = calendarID
+		cal.OrgID = orgID
+		if err := calendar.Validate(&cal); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		calendar.Merge(&cal, nil, nil)
+
+		if err := h.store.UpdateCalendar(&cal); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.rescheduleCalendar(orgID, calendarID, nil)
+
+		respondJSON(w, cal)
+
+	case http.MethodDelete:
+		// Schedules using the calendar are detached from it and rescheduled without it
+		schedules, err := h.store.ListSchedulesByCalendar(orgID, calendarID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := h.store.DeleteCalendar(orgID, calendarID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, schedule := range schedules {
+			schedule.CalendarID = nil
+		}
+		h.rescheduleCalendar(orgID, calendarID, schedules)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// rescheduleCalendar recalculates the next run times of the schedules using a changed calendar
+// (loaded when schedules is nil)
+func (h *Handler) rescheduleCalendar(orgID, calendarID int64, schedules []*model.Schedule) {
+	var err error
+	if schedules == nil {
+		schedules, err = h.store.ListSchedulesByCalendar(orgID, calendarID)
+	}
+	if err == nil {
+		err = h.scheduler.Reschedule(schedules)
+	}
+	if err != nil {
+		log.Printf("Failed to reschedule schedules using calendar %d: %v", calendarID, err)
+	}
+}
+
 // Helper functions
 
 func getOrgID(r *http.Request) int64 {
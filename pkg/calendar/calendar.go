@@ -0,0 +1,145 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// Policies for occurrences that fall on a blocked day
+const (
+	PolicySkip        = "skip"         // Drop the occurrence (default)
+	PolicyPostpone    = "postpone"     // Move it to the next business day at the same time
+	PolicyPullForward = "pull_forward" // Move it to the previous business day at the same time
+)
+
+// DateLayout is the format of calendar dates
+const DateLayout = "2006-01-02"
+
+// maxShiftDays bounds the search for a business day when postponing or pulling forward
+const maxShiftDays = 366
+
+// Validate checks the dates of a calendar
+func Validate(cal *model.Calendar) error {
+	if cal.Name == "" {
+		return fmt.Errorf("calendar name is required")
+	}
+	for _, day := range cal.Holidays {
+		if _, err := time.Parse(DateLayout, day.Date); err != nil {
+			return fmt.Errorf("invalid holiday date %q: expected YYYY-MM-DD", day.Date)
+		}
+	}
+	for _, window := range cal.Blackouts {
+		if _, err := time.Parse(DateLayout, window.Start); err != nil {
+			return fmt.Errorf("invalid blackout start %q: expected YYYY-MM-DD", window.Start)
+		}
+		if _, err := time.Parse(DateLayout, window.End); err != nil {
+			return fmt.Errorf("invalid blackout end %q: expected YYYY-MM-DD", window.End)
+		}
+		if window.End < window.Start {
+			return fmt.Errorf("blackout window %s to %s ends before it starts", window.Start, window.End)
+		}
+	}
+	return nil
+}
+
+// ValidatePolicy checks a schedule's calendar policy
+func ValidatePolicy(policy string) error {
+	switch policy {
+	case "", PolicySkip, PolicyPostpone, PolicyPullForward:
+		return nil
+	}
+	return fmt.Errorf("unknown calendar_policy %q", policy)
+}
+
+// IsBlocked reports whether the date of t (in t's location) is a holiday or inside a blackout window
+func IsBlocked(cal *model.Calendar, t time.Time) bool {
+	if cal == nil {
+		return false
+	}
+
+	// YYYY-MM-DD strings order the same way as the dates they represent
+	date := t.Format(DateLayout)
+	for _, day := range cal.Holidays {
+		if day.Date == date {
+			return true
+		}
+	}
+	for _, window := range cal.Blackouts {
+		if date >= window.Start && date <= window.End {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBusinessDay reports whether t falls on Monday to Friday and is not blocked by the calendar
+func IsBusinessDay(cal *model.Calendar, t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !IsBlocked(cal, t)
+}
+
+// Adjust applies a calendar policy to a schedule occurrence. Occurrences on unblocked days are
+// returned unchanged; blocked ones are moved to a business day at the same wall-clock time, or
+// dropped (ok is false) under the skip policy.
+func Adjust(cal *model.Calendar, policy string, occurrence time.Time) (adjusted time.Time, ok bool) {
+	if !IsBlocked(cal, occurrence) {
+		return occurrence, true
+	}
+
+	step := 0
+	switch policy {
+	case PolicyPostpone:
+		step = 1
+	case PolicyPullForward:
+		step = -1
+	default:
+		return time.Time{}, false
+	}
+
+	for days := step; days*step <= maxShiftDays; days += step {
+		candidate := time.Date(occurrence.Year(), occurrence.Month(), occurrence.Day()+days,
+			occurrence.Hour(), occurrence.Minute(), occurrence.Second(), 0, occurrence.Location())
+		if IsBusinessDay(cal, candidate) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Merge adds holidays and blackout windows to a calendar, skipping ones it already contains,
+// and keeps both lists sorted by date
+func Merge(cal *model.Calendar, holidays model.CalendarDays, blackouts model.BlackoutWindows) (addedHolidays, addedBlackouts int) {
+	known := make(map[string]bool, len(cal.Holidays))
+	for _, day := range cal.Holidays {
+		known[day.Date] = true
+	}
+	for _, day := range holidays {
+		if !known[day.Date] {
+			known[day.Date] = true
+			cal.Holidays = append(cal.Holidays, day)
+			addedHolidays++
+		}
+	}
+
+	knownWindows := make(map[string]bool, len(cal.Blackouts))
+	for _, window := range cal.Blackouts {
+		knownWindows[window.Start+"/"+window.End] = true
+	}
+	for _, window := range blackouts {
+		key := window.Start + "/" + window.End
+		if !knownWindows[key] {
+			knownWindows[key] = true
+			cal.Blackouts = append(cal.Blackouts, window)
+			addedBlackouts++
+		}
+	}
+
+	sort.Slice(cal.Holidays, func(i, j int) bool { return cal.Holidays[i].Date < cal.Holidays[j].Date })
+	sort.Slice(cal.Blackouts, func(i, j int) bool { return cal.Blackouts[i].Start < cal.Blackouts[j].Start })
+	return addedHolidays, addedBlackouts
+}
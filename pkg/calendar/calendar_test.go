@@ -0,0 +1,152 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestAdjust(t *testing.T) {
+	cal := &model.Calendar{
+		Name:      "finance",
+		Holidays:  model.CalendarDays{{Date: "2025-12-25", Name: "Christmas Day"}, {Date: "2025-12-26"}},
+		Blackouts: model.BlackoutWindows{{Start: "2025-03-28", End: "2025-04-02", Name: "Quarter close"}},
+	}
+	christmas := time.Date(2025, 12, 25, 8, 0, 0, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		name       string
+		policy     string
+		occurrence time.Time
+		want       time.Time
+		wantOK     bool
+	}{
+		{
+			name:       "unblocked day is unchanged",
+			policy:     PolicySkip,
+			occurrence: time.Date(2025, 12, 24, 8, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 12, 24, 8, 0, 0, 0, time.UTC),
+			wantOK:     true,
+		},
+		{
+			name:       "skip drops holiday",
+			policy:     "",
+			occurrence: christmas,
+			wantOK:     false,
+		},
+		{
+			name:       "postpone skips holidays and weekend",
+			policy:     PolicyPostpone,
+			occurrence: christmas,
+			want:       time.Date(2025, 12, 29, 8, 0, 0, 0, time.UTC),
+			wantOK:     true,
+		},
+		{
+			name:       "pull forward to previous business day",
+			policy:     PolicyPullForward,
+			occurrence: christmas,
+			want:       time.Date(2025, 12, 24, 8, 0, 0, 0, time.UTC),
+			wantOK:     true,
+		},
+		{
+			name:       "postpone past blackout window",
+			policy:     PolicyPostpone,
+			occurrence: time.Date(2025, 3, 31, 9, 30, 0, 0, time.UTC),
+			want:       time.Date(2025, 4, 3, 9, 30, 0, 0, time.UTC),
+			wantOK:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Adjust(cal, tt.policy, tt.occurrence)
+			if ok != tt.wantOK || (ok && !got.Equal(tt.want)) {
+				t.Errorf("Adjust() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20250101",
+		"DTEND;VALUE=DATE:20250102",
+		"SUMMARY:New Year\\'s Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20250328",
+		"DTEND;VALUE=DATE:20250403",
+		"SUMMARY:Quarter close\\, Q1",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20251225",
+		"RRULE:FREQ=YEARLY;COUNT=2",
+		"SUMMARY:Christmas",
+		" Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250106T090000Z",
+		"RRULE:FREQ=WEEKLY",
+		"SUMMARY:Standup",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	holidays, blackouts, err := ParseICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("ParseICS() error = %v", err)
+	}
+
+	wantDates := []string{"2025-01-01", "2025-12-25", "2026-12-25"}
+	if len(holidays) != len(wantDates) {
+		t.Fatalf("ParseICS() = %d holidays, want %d: %+v", len(holidays), len(wantDates), holidays)
+	}
+	for i, date := range wantDates {
+		if holidays[i].Date != date {
+			t.Errorf("holiday %d = %s, want %s", i, holidays[i].Date, date)
+		}
+	}
+	if holidays[1].Name != "ChristmasDay" {
+		t.Errorf("folded summary = %q, want %q", holidays[1].Name, "ChristmasDay")
+	}
+
+	if len(blackouts) != 1 || blackouts[0].Start != "2025-03-28" || blackouts[0].End != "2025-04-02" || blackouts[0].Name != "Quarter close, Q1" {
+		t.Errorf("ParseICS() blackouts = %+v, want quarter close 2025-03-28 to 2025-04-02", blackouts)
+	}
+}
+
+func TestICSYearlyRecurrences(t *testing.T) {
+	start := time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rrule   string
+		want    int
+		wantErr bool
+	}{
+		{rrule: "", want: 1},
+		{rrule: "FREQ=WEEKLY", want: 0},
+		{rrule: "FREQ=YEARLY;COUNT=3", want: 3},
+		{rrule: "FREQ=YEARLY;COUNT=100000000", want: maxYearlyRecurrences},
+		{rrule: "FREQ=YEARLY;COUNT=-1", wantErr: true},
+		{rrule: "FREQ=YEARLY;UNTIL=20271225", want: 3},
+		{rrule: "FREQ=YEARLY;UNTIL=99991225", want: maxYearlyRecurrences},
+		{rrule: "FREQ=YEARLY;UNTIL=20201225", wantErr: true},
+		{rrule: "FREQ=YEARLY", want: maxYearlyRecurrences},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rrule, func(t *testing.T) {
+			got, err := icsYearlyRecurrences(tt.rrule, start)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("icsYearlyRecurrences() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("icsYearlyRecurrences() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
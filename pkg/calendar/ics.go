@@ -0,0 +1,196 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// maxYearlyRecurrences bounds the expansion of yearly recurring events, also those whose COUNT or
+// UNTIL reaches further
+const maxYearlyRecurrences = 10
+
+// icsEvent holds the properties of a VEVENT relevant to blocked days
+type icsEvent struct {
+	summary string
+	start   string
+	end     string
+	endDate bool // DTEND is a DATE (exclusive) rather than a DATE-TIME
+	rrule   string
+}
+
+// ParseICS extracts blocked days from an iCalendar (.ics) file such as a public holiday feed.
+// Events covering a single day become holidays and longer events become blackout windows.
+// Yearly recurring events are expanded; events with other recurrence rules are ignored.
+func ParseICS(r io.Reader) (model.CalendarDays, model.BlackoutWindows, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	holidays := model.CalendarDays{}
+	blackouts := model.BlackoutWindows{}
+	var event *icsEvent
+
+	for _, line := range lines {
+		name, value := splitICSLine(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &icsEvent{}
+		case name == "END" && value == "VEVENT" && event != nil:
+			days, windows, err := expandICSEvent(event)
+			if err != nil {
+				return nil, nil, err
+			}
+			holidays = append(holidays, days...)
+			blackouts = append(blackouts, windows...)
+			event = nil
+		case event == nil:
+			continue
+		case name == "SUMMARY":
+			event.summary = unescapeICSText(value)
+		case name == "DTSTART":
+			event.start = value
+		case name == "DTEND":
+			event.end = value
+			event.endDate = !strings.Contains(value, "T")
+		case name == "RRULE":
+			event.rrule = value
+		}
+	}
+
+	return holidays, blackouts, nil
+}
+
+// expandICSEvent converts an event (and its yearly recurrences) into holidays or blackout windows
+func expandICSEvent(event *icsEvent) (model.CalendarDays, model.BlackoutWindows, error) {
+	if event.start == "" {
+		return nil, nil, nil
+	}
+
+	start, err := icsDate(event.start)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid DTSTART %q: %w", event.start, err)
+	}
+	end := start
+	if event.end != "" {
+		end, err = icsDate(event.end)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid DTEND %q: %w", event.end, err)
+		}
+		// All-day events end on the (exclusive) following day, as do timed events ending at midnight
+		if event.endDate || strings.Contains(event.end, "T000000") {
+			end = end.AddDate(0, 0, -1)
+		}
+		if end.Before(start) {
+			end = start
+		}
+	}
+
+	years, err := icsYearlyRecurrences(event.rrule, start)
+	if err != nil || years == 0 {
+		return nil, nil, err
+	}
+
+	var holidays model.CalendarDays
+	var blackouts model.BlackoutWindows
+	for year := 0; year < years; year++ {
+		from, to := start.AddDate(year, 0, 0), end.AddDate(year, 0, 0)
+		if from.Equal(to) {
+			holidays = append(holidays, model.CalendarDay{Date: from.Format(DateLayout), Name: event.summary})
+		} else {
+			blackouts = append(blackouts, model.BlackoutWindow{
+				Start: from.Format(DateLayout),
+				End:   to.Format(DateLayout),
+				Name:  event.summary,
+			})
+		}
+	}
+	return holidays, blackouts, nil
+}
+
+// icsYearlyRecurrences returns how many yearly occurrences an event has, at most
+// maxYearlyRecurrences: 1 without a recurrence rule and 0 for recurrence rules other than FREQ=YEARLY
+func icsYearlyRecurrences(rrule string, start time.Time) (int, error) {
+	if rrule == "" {
+		return 1, nil
+	}
+
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		if key, value, found := strings.Cut(part, "="); found {
+			parts[strings.ToUpper(key)] = value
+		}
+	}
+	if parts["FREQ"] != "YEARLY" || (parts["INTERVAL"] != "" && parts["INTERVAL"] != "1") {
+		return 0, nil
+	}
+
+	if count, ok := parts["COUNT"]; ok {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid RRULE COUNT %q", count)
+		}
+		return min(n, maxYearlyRecurrences), nil
+	}
+	if until, ok := parts["UNTIL"]; ok {
+		last, err := icsDate(until)
+		if err != nil {
+			return 0, fmt.Errorf("invalid RRULE UNTIL %q: %w", until, err)
+		}
+		if last.Before(start) {
+			return 0, fmt.Errorf("invalid RRULE UNTIL %q: before the start of the event", until)
+		}
+		return min(last.Year()-start.Year()+1, maxYearlyRecurrences), nil
+	}
+	return maxYearlyRecurrences, nil
+}
+
+// icsDate parses the date part of an iCalendar DATE or DATE-TIME value. Times are ignored: blocked
+// days are whole days in the schedule's timezone.
+func icsDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("expected YYYYMMDD")
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// unfoldICSLines reads content lines, joining folded continuation lines (RFC 5545 section 3.1)
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// splitICSLine splits "NAME;PARAM=X:VALUE" into its upper-cased name and value
+func splitICSLine(line string) (name, value string) {
+	head, value, _ := strings.Cut(line, ":")
+	name, _, _ = strings.Cut(head, ";")
+	return strings.ToUpper(name), value
+}
+
+// unescapeICSText reverses iCalendar TEXT escaping
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
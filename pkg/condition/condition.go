@@ -0,0 +1,250 @@
+package condition
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// queryTimeout bounds a condition query so a slow datasource cannot stall the worker
+const queryTimeout = 60 * time.Second
+
+// Result is the outcome of evaluating a condition
+type Result struct {
+	Value  float64 // Reduced query value (NaN when the query returned no data)
+	Met    bool    // Whether Value satisfies the condition; false without data
+	NoData bool
+}
+
+// Describe explains the result for run history, e.g. "condition not met: mean(value) = 1.3, threshold > 2"
+func (r *Result) Describe(cond *model.Condition) string {
+	if r.NoData {
+		return "condition query returned no data"
+	}
+	verdict := "not met"
+	if r.Met {
+		verdict = "met"
+	}
+	return fmt.Sprintf("condition %s: %s(value) = %s, threshold %s %s",
+		verdict, reducerName(cond.Reducer), formatFloat(r.Value), cond.Operator, formatFloat(cond.Threshold))
+}
+
+// Validate checks that a condition can be evaluated
+func Validate(cond *model.Condition) error {
+	if cond.DatasourceUID == "" {
+		return fmt.Errorf("condition datasource_uid is required")
+	}
+	if len(cond.Query) == 0 {
+		return fmt.Errorf("condition query is required")
+	}
+	switch cond.Reducer {
+	case "", "last", "mean", "min", "max", "sum", "count":
+	default:
+		return fmt.Errorf("unknown condition reducer %q", cond.Reducer)
+	}
+	if _, err := compare(0, cond.Operator, 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Evaluator evaluates schedule conditions through Grafana's datasource query API
+type Evaluator struct {
+	grafanaURL string
+	client     *http.Client
+}
+
+// NewEvaluator creates an evaluator for the given Grafana base URL
+func NewEvaluator(grafanaURL string, skipTLSVerify bool) *Evaluator {
+	return &Evaluator{
+		grafanaURL: strings.TrimSuffix(grafanaURL, "/"),
+		client: &http.Client{
+			Timeout: queryTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: skipTLSVerify},
+			},
+		},
+	}
+}
+
+// Evaluate runs the condition's query over the time range of the report and compares the reduced
+// value with the threshold
+func (e *Evaluator) Evaluate(ctx context.Context, token string, orgID int64, cond *model.Condition, from, to string) (*Result, error) {
+	query := make(map[string]interface{}, len(cond.Query)+2)
+	for key, value := range cond.Query {
+		query[key] = value
+	}
+	query["datasource"] = map[string]string{"uid": cond.DatasourceUID}
+	if _, ok := query["refId"]; !ok {
+		query["refId"] = "A"
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"from":    from,
+		"to":      to,
+		"queries": []interface{}{query},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.grafanaURL+"/api/ds/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(orgID, 10))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("query returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	values, err := numericValues(respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := reduce(cond.Reducer, values)
+	if !ok {
+		return &Result{Value: math.NaN(), NoData: true}, nil
+	}
+	met, err := compare(value, cond.Operator, cond.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Value: value, Met: met}, nil
+}
+
+// queryResponse is the part of an /api/ds/query response holding data frames
+type queryResponse struct {
+	Results map[string]struct {
+		Error  string `json:"error"`
+		Frames []struct {
+			Schema struct {
+				Fields []struct {
+					Type string `json:"type"`
+				} `json:"fields"`
+			} `json:"schema"`
+			Data struct {
+				Values [][]interface{} `json:"values"`
+			} `json:"data"`
+		} `json:"frames"`
+	} `json:"results"`
+}
+
+// numericValues collects the non-null values of all number fields in the response, in order
+func numericValues(body []byte) ([]float64, error) {
+	var response queryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode query response: %w", err)
+	}
+
+	var values []float64
+	for refID, result := range response.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("query %s failed: %s", refID, result.Error)
+		}
+		for _, frame := range result.Frames {
+			for i, field := range frame.Schema.Fields {
+				if field.Type != "number" || i >= len(frame.Data.Values) {
+					continue
+				}
+				for _, v := range frame.Data.Values[i] {
+					if f, ok := v.(float64); ok {
+						values = append(values, f)
+					}
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// reduce reduces the values to a single number; ok is false when there is nothing to reduce
+func reduce(reducer string, values []float64) (float64, bool) {
+	if reducer == "count" {
+		return float64(len(values)), true
+	}
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	switch reducer {
+	case "mean", "sum":
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		if reducer == "mean" {
+			return sum / float64(len(values)), true
+		}
+		return sum, true
+	case "min":
+		min := values[0]
+		for _, v := range values[1:] {
+			min = math.Min(min, v)
+		}
+		return min, true
+	case "max":
+		max := values[0]
+		for _, v := range values[1:] {
+			max = math.Max(max, v)
+		}
+		return max, true
+	default:
+		return values[len(values)-1], true
+	}
+}
+
+// compare evaluates "value operator threshold"
+func compare(value float64, operator string, threshold float64) (bool, error) {
+	switch operator {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	}
+	return false, fmt.Errorf("unknown condition operator %q", operator)
+}
+
+// reducerName returns the effective name of a reducer
+func reducerName(reducer string) string {
+	if reducer == "" {
+		return "last"
+	}
+	return reducer
+}
+
+// formatFloat formats a value compactly for run history
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
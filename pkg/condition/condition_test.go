@@ -0,0 +1,96 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+const errorRateResponse = `{
+	"results": {
+		"A": {
+			"status": 200,
+			"frames": [{
+				"schema": {"fields": [{"name": "Time", "type": "time"}, {"name": "error_rate", "type": "number"}]},
+				"data": {"values": [[1735689600000, 1735693200000, 1735696800000], [1.5, null, 3.5]]}
+			}]
+		}
+	}
+}`
+
+func TestEvaluate(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/ds/query" || r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Grafana-Org-Id") != "2" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(errorRateResponse))
+	}))
+	defer server.Close()
+
+	evaluator := NewEvaluator(server.URL, false)
+
+	tests := []struct {
+		name      string
+		reducer   string
+		operator  string
+		threshold float64
+		wantValue float64
+		wantMet   bool
+	}{
+		{name: "last above threshold", reducer: "", operator: ">", threshold: 2, wantValue: 3.5, wantMet: true},
+		{name: "mean below threshold", reducer: "mean", operator: ">", threshold: 2.5, wantValue: 2.5, wantMet: false},
+		{name: "max", reducer: "max", operator: ">=", threshold: 3.5, wantValue: 3.5, wantMet: true},
+		{name: "count ignores nulls", reducer: "count", operator: "==", threshold: 2, wantValue: 2, wantMet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := &model.Condition{
+				DatasourceUID: "prom",
+				Query:         map[string]interface{}{"expr": "error_rate"},
+				Reducer:       tt.reducer,
+				Operator:      tt.operator,
+				Threshold:     tt.threshold,
+			}
+			result, err := evaluator.Evaluate(context.Background(), "token", 2, cond, "now-7d", "now")
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result.Value != tt.wantValue || result.Met != tt.wantMet {
+				t.Errorf("Evaluate() = %v (met %v), want %v (met %v)", result.Value, result.Met, tt.wantValue, tt.wantMet)
+			}
+		})
+	}
+
+	queries, _ := received["queries"].([]interface{})
+	if received["from"] != "now-7d" || len(queries) != 1 {
+		t.Fatalf("request body = %v, want one query from now-7d", received)
+	}
+	query := queries[0].(map[string]interface{})
+	if query["refId"] != "A" || query["expr"] != "error_rate" || query["datasource"].(map[string]interface{})["uid"] != "prom" {
+		t.Errorf("query = %v, want expr with datasource prom and refId A", query)
+	}
+}
+
+func TestEvaluate_NoData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": {"A": {"status": 200, "frames": []}}}`))
+	}))
+	defer server.Close()
+
+	cond := &model.Condition{DatasourceUID: "prom", Query: map[string]interface{}{"expr": "up"}, Operator: ">"}
+	result, err := NewEvaluator(server.URL, false).Evaluate(context.Background(), "token", 1, cond, "now-1h", "now")
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !result.NoData || result.Met {
+		t.Errorf("Evaluate() = %+v, want no data and not met", result)
+	}
+}
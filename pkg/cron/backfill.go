@@ -0,0 +1,231 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/mail"
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// maxBackfillRuns caps how many past occurrences one backfill generates
+const maxBackfillRuns = 100
+
+// bundleTimeout bounds reading the artifacts of a backfill and emailing them as one bundle
+const bundleTimeout = 10 * time.Minute
+
+// ErrInvalidBackfill is returned for backfill requests that cannot be run, e.g. because their range
+// holds no occurrence of the schedule
+var ErrInvalidBackfill = errors.New("invalid backfill")
+
+// backfillOccurrences lists the fire times of a schedule from from to to (both inclusive), following
+// its timezone and holiday calendar. The validity window and occurrence limit do not apply: they
+// govern future deliveries, not the regeneration of past ones.
+func backfillOccurrences(schedule *model.Schedule, cal *model.Calendar, from, to time.Time) ([]time.Time, error) {
+	var occurrences []time.Time
+	after := from.Add(-time.Nanosecond)
+	for {
+		next, err := nextRunTime(schedule, cal, after)
+		if err != nil {
+			return nil, err
+		}
+		if next.After(to) {
+			return occurrences, nil
+		}
+		if len(occurrences) == maxBackfillRuns {
+			return nil, fmt.Errorf("%w: the range holds more than %d occurrences", ErrInvalidBackfill, maxBackfillRuns)
+		}
+		occurrences = append(occurrences, next)
+		after = next
+	}
+}
+
+// Backfill queues one run per past occurrence of a schedule between from and to. Each run renders
+// the absolute time range its occurrence covered and stores the report without emailing it; when
+// sendEmail is set, the reports are emailed as one bundle once all runs have finished.
+func (s *Scheduler) Backfill(schedule *model.Schedule, from, to time.Time, sendEmail bool) (*model.Backfill, []*model.Run, error) {
+	if !from.Before(to) {
+		return nil, nil, fmt.Errorf("%w: from must be before to", ErrInvalidBackfill)
+	}
+	if to.After(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: to must not be in the future", ErrInvalidBackfill)
+	}
+
+	occurrences, err := backfillOccurrences(schedule, s.scheduleCalendar(schedule), from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(occurrences) == 0 {
+		return nil, nil, fmt.Errorf("%w: the schedule has no occurrences in the range", ErrInvalidBackfill)
+	}
+
+	runs := make([]*model.Run, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		scheduledFor := occurrence
+		run := &model.Run{
+			ScheduleID:   schedule.ID,
+			OrgID:        schedule.OrgID,
+			ScheduledFor: &scheduledFor,
+		}
+		if err := pinTimeRange(schedule, run); err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve time range of occurrence %s: %w", occurrence.Format(time.RFC3339), err)
+		}
+		runs = append(runs, run)
+	}
+
+	backfill := &model.Backfill{
+		ScheduleID: schedule.ID,
+		OrgID:      schedule.OrgID,
+		From:       from,
+		To:         to,
+		SendEmail:  sendEmail,
+	}
+	if err := s.store.CreateBackfill(backfill, runs); err != nil {
+		return nil, nil, fmt.Errorf("failed to queue backfill: %w", err)
+	}
+
+	s.signalDispatcher()
+	return backfill, runs, nil
+}
+
+// finishBackfill completes a backfill once all of its runs have finished and emails its reports as
+// one bundle when requested. It may be called for every finished run: only one caller delivers.
+func (s *Scheduler) finishBackfill(orgID, backfillID int64) {
+	backfill, err := s.store.GetBackfill(orgID, backfillID)
+	if err != nil {
+		log.Printf("Failed to load backfill %d: %v", backfillID, err)
+		return
+	}
+
+	status := "completed"
+	if backfill.SendEmail {
+		status = "delivering"
+	}
+	finished, err := s.store.FinishBackfillRuns(backfill.ID, status)
+	if err != nil {
+		log.Printf("Failed to finish backfill %d: %v", backfill.ID, err)
+		return
+	}
+	if !finished || !backfill.SendEmail {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.baseCtx, bundleTimeout)
+	defer cancel()
+
+	status, errorText := "completed", ""
+	if err := s.deliverBackfill(ctx, backfill); err != nil {
+		status, errorText = "failed", err.Error()
+		log.Printf("Failed to deliver backfill %d of schedule %d: %v", backfill.ID, backfill.ScheduleID, err)
+	}
+	if err := s.store.UpdateBackfillResult(backfill.ID, status, errorText); err != nil {
+		log.Printf("Failed to update backfill %d: %v", backfill.ID, err)
+	}
+}
+
+// finishBackfills completes the running backfills whose runs ended without finishing them, e.g.
+// runs cancelled before they started or failed by queue recovery
+func (s *Scheduler) finishBackfills() {
+	backfills, err := s.store.ListRunningBackfills()
+	if err != nil {
+		log.Printf("Failed to list running backfills: %v", err)
+		return
+	}
+	for _, backfill := range backfills {
+		s.finishBackfill(backfill.OrgID, backfill.ID)
+	}
+}
+
+// deliverBackfill emails the reports generated by a backfill as one bundle to the schedule's recipients
+func (s *Scheduler) deliverBackfill(ctx context.Context, backfill *model.Backfill) error {
+	settings, err := s.store.GetSettings(backfill.OrgID)
+	if err != nil {
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	if settings == nil {
+		return fmt.Errorf("no settings configured for org %d", backfill.OrgID)
+	}
+	schedule, err := s.store.GetSchedule(backfill.OrgID, backfill.ScheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedule %d: %w", backfill.ScheduleID, err)
+	}
+	runs, err := s.store.ListBackfillRuns(backfill.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list backfill runs: %w", err)
+	}
+	loc, err := scheduleLocation(schedule)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	var attachments []mail.Attachment
+	var size int64
+	var included, missing []string
+	for _, run := range runs {
+		period := fmt.Sprintf("%s to %s", formatRangeBound(run.RangeFrom, loc), formatRangeBound(run.RangeTo, loc))
+		if run.Status != "completed" || run.ArtifactPath == "" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", period, run.Status))
+			continue
+		}
+		data, err := os.ReadFile(run.ArtifactPath)
+		if err != nil {
+			return fmt.Errorf("failed to read report of run %d: %w", run.ID, err)
+		}
+		attachments = append(attachments, mail.Attachment{Filename: filepath.Base(run.ArtifactPath), Data: data})
+		size += int64(len(data))
+		included = append(included, period)
+	}
+	if len(attachments) == 0 {
+		return fmt.Errorf("none of the %d report(s) was generated", len(runs))
+	}
+	if limit := settings.Limits.MaxAttachmentSizeMB; limit > 0 && size > int64(limit)<<20 {
+		return fmt.Errorf("the %d report(s) total %.1f MB, over the attachment limit of %d MB; download them from Run History",
+			len(attachments), float64(size)/(1<<20), limit)
+	}
+
+	smtpConfig, err := resolveSMTPConfig(settings)
+	if err != nil {
+		return err
+	}
+
+	vars := map[string]string{
+		"schedule.name":   schedule.Name,
+		"dashboard.title": schedule.DashboardTitle,
+		"timerange":       fmt.Sprintf("%s to %s", backfill.From.In(loc).Format("2006-01-02 15:04 MST"), backfill.To.In(loc).Format("2006-01-02 15:04 MST")),
+		"run.started_at":  backfill.CreatedAt.Format(time.RFC1123),
+	}
+	subject := fmt.Sprintf("%s (%d reports)", mail.InterpolateTemplate(schedule.EmailSubject, vars), len(attachments))
+	body := backfillBody(schedule, included, missing)
+
+	err = mail.NewMailer(smtpConfig).SendBundleOnce(ctx, s.store, fmt.Sprintf("backfill:%d", backfill.ID),
+		schedule.Recipients, subject, body, attachments)
+	if errors.Is(err, mail.ErrAlreadyDelivered) {
+		return nil
+	}
+	return err
+}
+
+// backfillBody lists the periods covered by a backfill bundle and the ones missing from it
+func backfillBody(schedule *model.Schedule, included, missing []string) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "<p>Attached are %d past report(s) of <b>%s</b>:</p><ul>", len(included), html.EscapeString(schedule.Name))
+	for _, period := range included {
+		fmt.Fprintf(&body, "<li>%s</li>", html.EscapeString(period))
+	}
+	body.WriteString("</ul>")
+	if len(missing) > 0 {
+		body.WriteString("<p>These periods could not be generated:</p><ul>")
+		for _, period := range missing {
+			fmt.Fprintf(&body, "<li>%s</li>", html.EscapeString(period))
+		}
+		body.WriteString("</ul>")
+	}
+	return body.String()
+}
@@ -0,0 +1,63 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestBackfillOccurrences(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	monthly := &model.Schedule{IntervalType: "monthly", TimeOfDay: "08:00", DayOfMonth: 1, Timezone: "Europe/Berlin"}
+	hourly := &model.Schedule{IntervalType: "cron", CronExpr: "0 * * * *", Timezone: "UTC"}
+
+	tests := []struct {
+		name      string
+		schedule  *model.Schedule
+		from, to  time.Time
+		wantCount int
+		wantFirst time.Time
+		wantLast  time.Time
+		wantErr   error
+	}{
+		{
+			name:      "last 12 monthly reports, bounds inclusive",
+			schedule:  monthly,
+			from:      time.Date(2024, 7, 1, 8, 0, 0, 0, berlin),
+			to:        time.Date(2025, 6, 1, 8, 0, 0, 0, berlin),
+			wantCount: 12,
+			wantFirst: time.Date(2024, 7, 1, 8, 0, 0, 0, berlin),
+			wantLast:  time.Date(2025, 6, 1, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "no occurrence in range",
+			schedule: monthly,
+			from:     time.Date(2025, 6, 2, 0, 0, 0, 0, berlin),
+			to:       time.Date(2025, 6, 30, 0, 0, 0, 0, berlin),
+		},
+		{
+			name:     "too many occurrences",
+			schedule: hourly,
+			from:     time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+			wantErr:  ErrInvalidBackfill,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := backfillOccurrences(tt.schedule, nil, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("backfillOccurrences() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != tt.wantCount {
+				t.Fatalf("backfillOccurrences() = %d occurrences, want %d", len(got), tt.wantCount)
+			}
+			if tt.wantCount > 0 && (!got[0].Equal(tt.wantFirst) || !got[len(got)-1].Equal(tt.wantLast)) {
+				t.Errorf("backfillOccurrences() = %v ... %v, want %v ... %v", got[0], got[len(got)-1], tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/mail"
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// defaultRunTimeout bounds a run, including its retries, when neither the schedule nor the org sets a deadline
+const defaultRunTimeout = 10 * time.Minute
+
+// maxRunTimeout is the longest deadline a schedule or org may configure
+const maxRunTimeout = 24 * time.Hour
+
+// Phases of a run, named in the error text of runs that exceed their deadline
+const (
+	phaseStarting  = "startup"
+	phaseCondition = "condition evaluation"
+	phaseRender    = "rendering"
+	phasePDF       = "PDF generation"
+	phaseStorage   = "artifact storage"
+	phaseDelivery  = "email delivery"
+	phaseBackoff   = "retry backoff"
+)
+
+// errDeadlineExceeded is the cancellation cause of runs that exceed their deadline
+var errDeadlineExceeded = errors.New("run deadline exceeded")
+
+// ValidateRunTimeout checks a run deadline in seconds; 0 selects the default
+func ValidateRunTimeout(seconds int) error {
+	if seconds < 0 || time.Duration(seconds)*time.Second > maxRunTimeout {
+		return fmt.Errorf("run_timeout_seconds must be between 0 and %d", int(maxRunTimeout/time.Second))
+	}
+	return nil
+}
+
+// resolveRunTimeout returns the deadline of a schedule's runs: its own, else the org's, else the default
+func resolveRunTimeout(schedule *model.Schedule, settings *model.Settings) time.Duration {
+	if schedule.RunTimeoutSeconds > 0 {
+		return time.Duration(schedule.RunTimeoutSeconds) * time.Second
+	}
+	if settings != nil && settings.Limits.RunTimeoutSeconds > 0 {
+		return time.Duration(settings.Limits.RunTimeoutSeconds) * time.Second
+	}
+	return defaultRunTimeout
+}
+
+// phaseTracker records which phase a run is in, so a missed deadline can be attributed
+type phaseTracker struct {
+	mu    sync.Mutex
+	phase string
+}
+
+type phaseTrackerKey struct{}
+
+// withPhases returns a context that tracks the phases entered through enterPhase
+func withPhases(ctx context.Context) context.Context {
+	return context.WithValue(ctx, phaseTrackerKey{}, &phaseTracker{phase: phaseStarting})
+}
+
+// enterPhase records that the run behind ctx entered a phase; it is a no-op for untracked contexts
+func enterPhase(ctx context.Context, phase string) {
+	if tracker, ok := ctx.Value(phaseTrackerKey{}).(*phaseTracker); ok {
+		tracker.mu.Lock()
+		tracker.phase = phase
+		tracker.mu.Unlock()
+	}
+}
+
+// currentPhase returns the phase the run behind ctx is in
+func currentPhase(ctx context.Context) string {
+	tracker, ok := ctx.Value(phaseTrackerKey{}).(*phaseTracker)
+	if !ok {
+		return phaseStarting
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.phase
+}
+
+// withContext runs fn, which cannot be interrupted, and returns early with the context's cause if ctx
+// ends first. fn keeps running in the background and its result is discarded.
+func withContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, context.Cause(ctx)
+	}
+}
+
+// deadlineExceeded is returned for runs that ran out of time, naming the phase they were in
+type deadlineExceeded struct {
+	timeout time.Duration
+	phase   string
+	err     error
+}
+
+// Error implements error
+func (e *deadlineExceeded) Error() string {
+	msg := fmt.Sprintf("Run exceeded its deadline of %v during %s", e.timeout, e.phase)
+	if errors.Is(e.err, mail.ErrDeliveryUnknown) {
+		msg += "; the report may still arrive"
+	}
+	return msg
+}
+
+// Unwrap returns the error the run ended with
+func (e *deadlineExceeded) Unwrap() error {
+	return e.err
+}
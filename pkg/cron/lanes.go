@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/store"
+)
+
+// workerPool limits how many runs execute at once on this instance. Queued items are claimed by
+// priority, and when the pool has more than one slot the last free slot is kept for interactive
+// runs, so a user clicking "Run now" does not wait for a batch of scheduled reports to finish.
+type workerPool struct {
+	mu          sync.Mutex
+	size        int
+	defaultSize int // Size used when no size is configured
+	busy        int
+}
+
+// maxWorkerPoolSize is the largest worker pool size that can be configured
+const maxWorkerPoolSize = 50
+
+// ValidateConcurrency checks the concurrency limits of an org's settings
+func ValidateConcurrency(limits model.Limits) error {
+	if limits.MaxConcurrentRenders < 0 {
+		return fmt.Errorf("max_concurrent_renders must not be negative")
+	}
+	if limits.WorkerPoolSize < 0 || limits.WorkerPoolSize > maxWorkerPoolSize {
+		return fmt.Errorf("worker_pool_size must be between 0 and %d", maxWorkerPoolSize)
+	}
+	return nil
+}
+
+// newWorkerPool creates a pool with the given number of slots (at least one)
+func newWorkerPool(size int) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	return &workerPool{size: size, defaultSize: size}
+}
+
+// resize changes the number of slots; 0 restores the default size. Runs already executing keep
+// their slots, so a shrinking pool takes no new work until enough of them have finished. It reports
+// whether the size changed.
+func (p *workerPool) resize(size int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if size <= 0 {
+		size = p.defaultSize
+	}
+	changed := size != p.size
+	p.size = size
+	return changed
+}
+
+// capacity returns the number of slots
+func (p *workerPool) capacity() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// acquire takes a free slot and returns the lowest queue priority the slot may run. ok is false
+// when all slots are busy.
+func (p *workerPool) acquire() (minPriority int, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.busy >= p.size {
+		return 0, false
+	}
+	minPriority = store.PriorityBackground
+	if p.size > 1 && p.busy == p.size-1 {
+		minPriority = store.PriorityInteractive
+	}
+	p.busy++
+	return minPriority, true
+}
+
+// release frees a slot taken by acquire
+func (p *workerPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy--
+}
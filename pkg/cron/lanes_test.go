@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/store"
+)
+
+func TestWorkerPool(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want []int // Minimum priority of each slot acquired in turn
+	}{
+		{name: "single slot runs anything", size: 1, want: []int{store.PriorityBackground}},
+		{name: "last slot is kept for interactive runs", size: 3, want: []int{store.PriorityBackground, store.PriorityBackground, store.PriorityInteractive}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newWorkerPool(tt.size)
+			for i, want := range tt.want {
+				if got, ok := pool.acquire(); !ok || got != want {
+					t.Fatalf("acquire() #%d = %d, %v; want %d, true", i+1, got, ok, want)
+				}
+			}
+			if _, ok := pool.acquire(); ok {
+				t.Fatal("acquire() on a full pool succeeded")
+			}
+
+			// A released slot becomes the interactive one again
+			pool.release()
+			if got, ok := pool.acquire(); !ok || got != tt.want[len(tt.want)-1] {
+				t.Errorf("acquire() after release = %d, %v; want %d, true", got, ok, tt.want[len(tt.want)-1])
+			}
+		})
+	}
+}
+
+func TestWorkerPool_Resize(t *testing.T) {
+	pool := newWorkerPool(2)
+	pool.acquire()
+	pool.acquire()
+
+	// Shrinking below the busy slots takes no new work until enough runs finish
+	if !pool.resize(1) {
+		t.Fatal("resize(1) reported no change")
+	}
+	pool.release()
+	if _, ok := pool.acquire(); ok {
+		t.Fatal("acquire() succeeded while the shrunk pool is still full")
+	}
+	pool.release()
+	if got, ok := pool.acquire(); !ok || got != store.PriorityBackground {
+		t.Errorf("acquire() = %d, %v; want %d, true", got, ok, store.PriorityBackground)
+	}
+
+	// 0 restores the default size
+	if !pool.resize(0) || pool.capacity() != 2 {
+		t.Errorf("resize(0) capacity = %d, want 2", pool.capacity())
+	}
+}
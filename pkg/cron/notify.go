@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/mail"
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+// notifyTimeout bounds the owner lookup and email of an expiry notification
+const notifyTimeout = time.Minute
+
+// notifyExpired emails the owner of a schedule that was disabled because it has no occurrences left.
+// Failures are logged; the schedule stays disabled either way.
+func (s *Scheduler) notifyExpired(schedule *model.Schedule, reason string) {
+	ctx, cancel := context.WithTimeout(s.baseCtx, notifyTimeout)
+	defer cancel()
+
+	if err := s.sendExpiryNotice(ctx, schedule, reason); err != nil {
+		log.Printf("Failed to notify the owner of expired schedule %d: %v", schedule.ID, err)
+	}
+}
+
+// sendExpiryNotice looks up the schedule owner's email address in Grafana and sends the notice
+func (s *Scheduler) sendExpiryNotice(ctx context.Context, schedule *model.Schedule, reason string) error {
+	settings, err := s.store.GetSettings(schedule.OrgID)
+	if err != nil {
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	if settings == nil {
+		return fmt.Errorf("no settings configured for org %d", schedule.OrgID)
+	}
+
+	token, err := render.ServiceAccountToken(ctx)
+	if err != nil {
+		return err
+	}
+	email, err := lookupUserEmail(ctx, s.resolveGrafanaURL(settings), token, schedule.OrgID, schedule.OwnerUserID, settings.RendererConfig.SkipTLSVerify)
+	if err != nil {
+		return err
+	}
+
+	smtpConfig, err := resolveSMTPConfig(settings)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Scheduled report %q has ended", schedule.Name)
+	body := fmt.Sprintf(
+		"<p>The scheduled report <b>%s</b> was disabled because %s.</p>"+
+			"<p>To keep sending it, extend its end date or occurrence limit and enable it again.</p>",
+		html.EscapeString(schedule.Name), html.EscapeString(reason),
+	)
+	return mail.NewMailer(smtpConfig).SendReport(ctx, model.Recipients{To: []string{email}}, subject, body, nil, "")
+}
+
+// lookupUserEmail returns the email address of a member of the org through Grafana's API
+func lookupUserEmail(ctx context.Context, grafanaURL, token string, orgID, userID int64, skipTLSVerify bool) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(grafanaURL, "/")+"/api/org/users", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(orgID, 10))
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipTLSVerify},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to list org users: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list org users: HTTP %d", resp.StatusCode)
+	}
+
+	var users []struct {
+		UserID int64  `json:"userId"`
+		Email  string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return "", fmt.Errorf("failed to decode org users: %w", err)
+	}
+	for _, user := range users {
+		if user.UserID == userID {
+			if user.Email == "" {
+				return "", fmt.Errorf("user %d has no email address", userID)
+			}
+			return user.Email, nil
+		}
+	}
+	return "", fmt.Errorf("user %d is not a member of org %d", userID, orgID)
+}
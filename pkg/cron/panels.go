@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/pdf"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+// maxPanels caps how many panels a schedule renders individually
+const maxPanels = 50
+
+// ValidatePanels checks a schedule's panel selection and layout for the org's rendering backend.
+// Panels are laid out in a PDF, so other formats keep their selection but render the whole dashboard
+// (see renderPanels); wkhtmltopdf prints each panel on its own page and cannot lay them out in a grid.
+func ValidatePanels(schedule *model.Schedule, backend render.BackendType) error {
+	switch schedule.PanelLayout {
+	case "", pdf.LayoutPage, pdf.LayoutGrid:
+	default:
+		return fmt.Errorf("invalid panel_layout %q: must be %q or %q", schedule.PanelLayout, pdf.LayoutPage, pdf.LayoutGrid)
+	}
+	if len(schedule.PanelIDs) == 0 {
+		return nil
+	}
+	if len(schedule.PanelIDs) > maxPanels {
+		return fmt.Errorf("panel_ids must not select more than %d panels", maxPanels)
+	}
+	if schedule.PanelLayout == pdf.LayoutGrid && backend == render.BackendWkhtmltopdf {
+		return fmt.Errorf("panel_layout %q is not supported by the wkhtmltopdf backend", pdf.LayoutGrid)
+	}
+	seen := make(map[int64]bool, len(schedule.PanelIDs))
+	for _, id := range schedule.PanelIDs {
+		if id <= 0 || seen[id] {
+			return fmt.Errorf("invalid panel_ids: %d is not a positive, unique panel ID", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// renderPanels reports whether a schedule renders its selected panels instead of the whole dashboard
+func renderPanels(schedule *model.Schedule) bool {
+	return len(schedule.PanelIDs) > 0 && schedule.Format == "pdf"
+}
+
+// pdfLayout returns how the rendered images of a schedule are laid out in its PDF: the dashboard
+// screenshot fills the page, panels go one per page unless the schedule asks for a grid
+func pdfLayout(schedule *model.Schedule) string {
+	if !renderPanels(schedule) {
+		return pdf.LayoutFill
+	}
+	if schedule.PanelLayout == pdf.LayoutGrid {
+		return pdf.LayoutGrid
+	}
+	return pdf.LayoutPage
+}
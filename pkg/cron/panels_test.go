@@ -0,0 +1,33 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+func TestValidatePanels(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule model.Schedule
+		backend  render.BackendType
+		wantErr  bool
+	}{
+		{name: "whole dashboard", schedule: model.Schedule{Format: "html"}},
+		{name: "panels in a grid", schedule: model.Schedule{Format: "pdf", PanelIDs: model.IntSlice{2, 5}, PanelLayout: "grid"}},
+		{name: "unknown layout", schedule: model.Schedule{Format: "pdf", PanelIDs: model.IntSlice{2}, PanelLayout: "columns"}, wantErr: true},
+		{name: "panels kept for html", schedule: model.Schedule{Format: "html", PanelIDs: model.IntSlice{2}}},
+		{name: "grid with wkhtmltopdf", schedule: model.Schedule{Format: "pdf", PanelIDs: model.IntSlice{2}, PanelLayout: "grid"}, backend: render.BackendWkhtmltopdf, wantErr: true},
+		{name: "duplicate panel", schedule: model.Schedule{Format: "pdf", PanelIDs: model.IntSlice{2, 2}}, wantErr: true},
+		{name: "invalid panel ID", schedule: model.Schedule{Format: "pdf", PanelIDs: model.IntSlice{0}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePanels(&tt.schedule, tt.backend); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePanels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+// errRenderersClosed is returned when a run asks for a renderer after the scheduler shut down
+var errRenderersClosed = errors.New("renderers are closed")
+
+// rendererCache keeps one renderer per org, so runs reuse its browser instance. The workers share it,
+// so it is safe for concurrent use. Renderers are reference counted: one that is replaced or shut down
+// while runs still use it is closed when the last of them releases it.
+type rendererCache struct {
+	mu      sync.Mutex
+	entries map[int64]*rendererEntry
+	closed  bool
+}
+
+// rendererEntry is a renderer and the runs using it
+type rendererEntry struct {
+	backend render.Backend
+	key     string // Identifies the settings the renderer was created with
+	users   int    // Runs using the renderer
+	stale   bool   // Replaced or shut down: closed once it has no users
+}
+
+// newRendererCache creates an empty renderer cache
+func newRendererCache() *rendererCache {
+	return &rendererCache{entries: make(map[int64]*rendererEntry)}
+}
+
+// acquire returns the org's renderer for the given key, creating it when the org has none or one
+// created for another key. The caller releases the entry when its run is done with it.
+func (c *rendererCache) acquire(orgID int64, key string, create func() (render.Backend, error)) (*rendererEntry, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errRenderersClosed
+	}
+
+	entry, ok := c.entries[orgID]
+	if ok && entry.key == key {
+		entry.users++
+		c.mu.Unlock()
+		return entry, nil
+	}
+
+	backend, err := create()
+	if err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	var replaced *rendererEntry
+	if ok {
+		entry.stale = true
+		if entry.users == 0 {
+			replaced = entry
+		}
+	}
+	entry = &rendererEntry{backend: backend, key: key, users: 1}
+	c.entries[orgID] = entry
+	c.mu.Unlock()
+
+	if replaced != nil {
+		closeRenderer(orgID, replaced.backend)
+	}
+	return entry, nil
+}
+
+// release ends a run's use of a renderer, closing it when it is stale and no other run uses it
+func (c *rendererCache) release(orgID int64, entry *rendererEntry) {
+	c.mu.Lock()
+	entry.users--
+	idle := entry.stale && entry.users == 0
+	c.mu.Unlock()
+
+	if idle {
+		closeRenderer(orgID, entry.backend)
+	}
+}
+
+// closeAll closes the idle renderers and marks the ones still in use to be closed when released.
+// No renderer is handed out afterwards.
+func (c *rendererCache) closeAll() {
+	c.mu.Lock()
+	c.closed = true
+	idle := make(map[int64]render.Backend)
+	for orgID, entry := range c.entries {
+		entry.stale = true
+		if entry.users == 0 {
+			idle[orgID] = entry.backend
+		}
+	}
+	c.entries = make(map[int64]*rendererEntry)
+	c.mu.Unlock()
+
+	for orgID, backend := range idle {
+		closeRenderer(orgID, backend)
+	}
+}
+
+// rendererKey identifies the settings a renderer is created with, so a renderer is replaced as soon
+// as any of them changes
+func rendererKey(backendType render.BackendType, grafanaURL string, config model.RendererConfig) string {
+	data, _ := json.Marshal(config) // A struct of plain fields always marshals
+	return fmt.Sprintf("%s|%s|%s", backendType, grafanaURL, data)
+}
+
+// closeRenderer closes a renderer, logging failures
+func closeRenderer(orgID int64, backend render.Backend) {
+	if err := backend.Close(); err != nil {
+		log.Printf("Failed to close renderer for org %d: %v", orgID, err)
+	}
+}
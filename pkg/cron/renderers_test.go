@@ -0,0 +1,136 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+// fakeBackend is a render.Backend that only counts how often it was closed
+type fakeBackend struct {
+	mu     sync.Mutex
+	closed int
+}
+
+func (b *fakeBackend) RenderDashboard(ctx context.Context, schedule *model.Schedule) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) RenderPanels(ctx context.Context, schedule *model.Schedule) ([][]byte, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed++
+	return nil
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) closeCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func TestRendererCache(t *testing.T) {
+	cache := newRendererCache()
+	var created []*fakeBackend
+	create := func() (render.Backend, error) {
+		backend := &fakeBackend{}
+		created = append(created, backend)
+		return backend, nil
+	}
+
+	first, err := cache.acquire(1, "chromium", create)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	again, _ := cache.acquire(1, "chromium", create)
+	if again != first || len(created) != 1 {
+		t.Fatalf("acquire() with the same key created %d renderers, want 1", len(created))
+	}
+	cache.release(1, again)
+
+	// A renderer replaced while a run uses it is closed when that run releases it
+	replacement, _ := cache.acquire(1, "wkhtmltopdf", create)
+	if len(created) != 2 || created[0].closeCount() != 0 {
+		t.Fatalf("replacing a renderer in use: created %d, first closed %d times; want 2 and 0", len(created), created[0].closeCount())
+	}
+	cache.release(1, first)
+	if created[0].closeCount() != 1 {
+		t.Errorf("replaced renderer closed %d times after its last run, want 1", created[0].closeCount())
+	}
+
+	// Shutting down closes idle renderers at once and the others when their run ends
+	other, _ := cache.acquire(2, "chromium", create)
+	cache.release(2, other)
+	cache.closeAll()
+	if created[2].closeCount() != 1 || created[1].closeCount() != 0 {
+		t.Errorf("closeAll() closed the idle renderer %d times and the one in use %d times, want 1 and 0",
+			created[2].closeCount(), created[1].closeCount())
+	}
+	cache.release(1, replacement)
+	if created[1].closeCount() != 1 {
+		t.Errorf("renderer in use at shutdown closed %d times after its run, want 1", created[1].closeCount())
+	}
+	if _, err := cache.acquire(1, "chromium", create); !errors.Is(err, errRenderersClosed) {
+		t.Errorf("acquire() after closeAll() error = %v, want errRenderersClosed", err)
+	}
+}
+
+func TestRendererCache_Concurrent(t *testing.T) {
+	cache := newRendererCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(orgID int64) {
+			defer wg.Done()
+			entry, err := cache.acquire(orgID, "chromium", func() (render.Backend, error) { return &fakeBackend{}, nil })
+			if err != nil {
+				t.Errorf("acquire() error = %v", err)
+				return
+			}
+			cache.release(orgID, entry)
+		}(int64(i % 3))
+	}
+	wg.Wait()
+	if len(cache.entries) != 3 {
+		t.Errorf("cache holds %d renderers, want one per org", len(cache.entries))
+	}
+}
+
+func TestRendererKey(t *testing.T) {
+	config := model.RendererConfig{URL: "http://renderer:8081/render", TimeoutMS: 30000}
+	key := rendererKey(render.BackendImageRenderer, "http://grafana:3000", config)
+
+	changedURL := config
+	changedURL.URL = "http://other:8081/render"
+	fullPage := config
+	fullPage.FullPage = true
+
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{name: "same settings", key: rendererKey(render.BackendImageRenderer, "http://grafana:3000", config), same: true},
+		{name: "other backend", key: rendererKey(render.BackendChromium, "http://grafana:3000", config)},
+		{name: "other Grafana URL", key: rendererKey(render.BackendImageRenderer, "http://grafana:3001", config)},
+		{name: "other renderer URL", key: rendererKey(render.BackendImageRenderer, "http://grafana:3000", changedURL)},
+		{name: "full page turned on", key: rendererKey(render.BackendImageRenderer, "http://grafana:3000", fullPage)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.key == key) != tt.same {
+				t.Errorf("rendererKey() equal = %v, want %v", tt.key == key, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// Retry policy defaults, used for fields a policy leaves at zero
+const (
+	defaultMaxAttempts   = 3
+	defaultBackoffBaseMS = 1000
+	defaultBackoffMaxMS  = 30000
+
+	// maxRetryAttempts bounds MaxAttempts so a misconfigured policy cannot occupy a worker for hours
+	maxRetryAttempts = 10
+)
+
+// ValidateRetryPolicy checks the fields of a retry policy
+func ValidateRetryPolicy(policy *model.RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 0 || policy.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry_policy.max_attempts must be between 1 and %d", maxRetryAttempts)
+	}
+	if policy.BackoffBaseMS < 0 || policy.BackoffMaxMS < 0 {
+		return fmt.Errorf("retry_policy backoff must not be negative")
+	}
+	if policy.BackoffMaxMS > 0 && policy.BackoffBaseMS > policy.BackoffMaxMS {
+		return fmt.Errorf("retry_policy.backoff_base_ms must not exceed backoff_max_ms")
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return fmt.Errorf("retry_policy.jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// resolveRetryPolicy returns the retry policy of a schedule: its own policy, else the org's, with
+// defaults filled in
+func resolveRetryPolicy(schedule *model.Schedule, settings *model.Settings) model.RetryPolicy {
+	var policy model.RetryPolicy
+	if schedule.RetryPolicy != nil {
+		policy = *schedule.RetryPolicy
+	} else if settings != nil && settings.RetryPolicy != nil {
+		policy = *settings.RetryPolicy
+	}
+
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if policy.BackoffBaseMS == 0 {
+		policy.BackoffBaseMS = defaultBackoffBaseMS
+	}
+	if policy.BackoffMaxMS == 0 {
+		policy.BackoffMaxMS = defaultBackoffMaxMS
+	}
+	return policy
+}
+
+// retryDelay returns how long to wait before the given retry (1 for the first retry): the base
+// delay doubled per retry, capped at the maximum and spread by the jitter fraction
+func retryDelay(policy model.RetryPolicy, retry int, random func() float64) time.Duration {
+	delay := time.Duration(policy.BackoffBaseMS) * time.Millisecond
+	max := time.Duration(policy.BackoffMaxMS) * time.Millisecond
+	for i := 1; i < retry && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	if policy.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 + policy.Jitter*(2*random()-1)))
+	}
+	return delay
+}
+
+// isPermanent reports whether an error is marked as not retryable, such as render.PermanentError
+// and mail.PermanentError
+func isPermanent(err error) bool {
+	var permanent interface{ Permanent() bool }
+	return errors.As(err, &permanent) && permanent.Permanent()
+}
@@ -0,0 +1,81 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/mail"
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := model.RetryPolicy{MaxAttempts: 5, BackoffBaseMS: 500, BackoffMaxMS: 3000}
+
+	tests := []struct {
+		name   string
+		jitter float64
+		random float64
+		retry  int
+		want   time.Duration
+	}{
+		{name: "first retry uses base", retry: 1, want: 500 * time.Millisecond},
+		{name: "doubles per retry", retry: 3, want: 2 * time.Second},
+		{name: "capped at max", retry: 5, want: 3 * time.Second},
+		{name: "jitter low end", jitter: 0.2, random: 0, retry: 2, want: 800 * time.Millisecond},
+		{name: "jitter high end", jitter: 0.2, random: 1, retry: 2, want: 1200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+			p.Jitter = tt.jitter
+			got := retryDelay(p, tt.retry, func() float64 { return tt.random })
+			if got != tt.want {
+				t.Errorf("retryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRetryPolicy(t *testing.T) {
+	org := &model.Settings{RetryPolicy: &model.RetryPolicy{MaxAttempts: 5, Jitter: 0.1}}
+
+	got := resolveRetryPolicy(&model.Schedule{}, org)
+	want := model.RetryPolicy{MaxAttempts: 5, BackoffBaseMS: defaultBackoffBaseMS, BackoffMaxMS: defaultBackoffMaxMS, Jitter: 0.1}
+	if got != want {
+		t.Errorf("org policy: got %+v, want %+v", got, want)
+	}
+
+	got = resolveRetryPolicy(&model.Schedule{RetryPolicy: &model.RetryPolicy{MaxAttempts: 1}}, org)
+	if got.MaxAttempts != 1 || got.Jitter != 0 {
+		t.Errorf("schedule policy: got %+v, want 1 attempt without jitter", got)
+	}
+
+	got = resolveRetryPolicy(&model.Schedule{}, nil)
+	if got.MaxAttempts != defaultMaxAttempts {
+		t.Errorf("default policy: got %+v, want %d attempts", got, defaultMaxAttempts)
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "plain error", err: errors.New("connection refused"), want: false},
+		{name: "render permanent", err: &render.PermanentError{Err: render.ErrDashboardNotFound}, want: true},
+		{name: "wrapped mail permanent", err: fmt.Errorf("failed to send email: %w", &mail.PermanentError{Err: mail.ErrInvalidRecipient}), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPermanent(tt.err); got != tt.want {
+				t.Errorf("isPermanent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,15 +3,19 @@ package cron
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"github.com/yourusername/sheduled-reports-app/pkg/condition"
 	"github.com/yourusername/sheduled-reports-app/pkg/mail"
 	"github.com/yourusername/sheduled-reports-app/pkg/model"
 	"github.com/yourusername/sheduled-reports-app/pkg/pdf"
@@ -19,15 +23,79 @@ import (
 	"github.com/yourusername/sheduled-reports-app/pkg/store"
 )
 
+// maxQueueAttempts is how many times a queued run is claimed before it is given up on.
+// A claim only repeats when the plugin died while the run was in progress.
+const maxQueueAttempts = 3
+
+// dispatchInterval is how often the queue is polled in addition to explicit wake-ups
+const dispatchInterval = 15 * time.Second
+
+// queueLease is how long a claimed run stays owned by this instance without a heartbeat.
+// Runs whose lease expires are recovered by another instance (or by this one after a restart).
+const queueLease = 2 * time.Minute
+
+// leaseRenewInterval is how often the lease of an executing run is renewed
+const leaseRenewInterval = queueLease / 4
+
+// manualClaimWindow is how far ahead a manual run takes over the schedule's pending occurrence.
+// A manual run shortly before (or after) a due time then replaces the scheduled run instead of
+// racing it and sending the report twice.
+const manualClaimWindow = time.Minute
+
+// shutdownGracePeriod is how long Stop waits for in-flight runs before interrupting them
+const shutdownGracePeriod = 30 * time.Second
+
+// interruptTimeout is how long Stop waits for interrupted runs to record their state
+const interruptTimeout = 10 * time.Second
+
+// deliveryPruneInterval is how often delivery records older than the run history retention are deleted
+const deliveryPruneInterval = time.Hour
+
+// defaultRetentionDays is the run history retention when the main org has not configured one
+const defaultRetentionDays = 30
+
+// mainOrgID is Grafana's main org; its settings hold the instance-wide limits such as the worker pool size
+const mainOrgID = 1
+
+var (
+	// errRunCancelled is the cancellation cause of runs cancelled through CancelRun
+	errRunCancelled = errors.New("run cancelled")
+	// errShutdown is the cancellation cause of runs interrupted by a scheduler shutdown
+	errShutdown = errors.New("scheduler shutting down")
+)
+
+// skipRun is returned when a run deliberately ends without sending a report; the run is recorded
+// with the given status and reason instead of failing
+type skipRun struct {
+	status string
+	reason string
+}
+
+// Error implements error
+func (e *skipRun) Error() string {
+	return e.reason
+}
+
 // Scheduler handles report scheduling
 type Scheduler struct {
 	store         *store.Store
 	cron          *cron.Cron
+	nodeID        string // Identifies this plugin instance when claiming queued runs
 	grafanaURL    string
 	artifactsPath string
-	workerPool    chan struct{}
+	workers       *workerPool
+	timers        *timers         // Next run times of enabled schedules
+	planMu        sync.Mutex      // Serializes queueing due schedules
+	planDone      chan struct{}   // Closed when the plan loop has exited
+	wake          chan struct{}   // Signals the dispatcher that queued work may be available
+	stop          chan struct{}   // Closed when the scheduler stops accepting work
+	dispatchDone  chan struct{}   // Closed when the dispatcher has exited
+	inflight      sync.WaitGroup  // Runs executing on this instance
 	baseCtx       context.Context // Context with Grafana config for background jobs
-	renderers     map[int64]render.Backend // Per-org renderer instances for browser reuse
+	renderers     *rendererCache  // Per-org renderer instances for browser reuse
+
+	runningMu sync.Mutex
+	running   map[int64]context.CancelCauseFunc // Cancels the runs executing on this instance, by run ID
 }
 
 // NewScheduler creates a new scheduler instance
@@ -35,14 +103,34 @@ func NewScheduler(st *store.Store, grafanaURL, artifactsPath string, maxConcurre
 	return &Scheduler{
 		store:         st,
 		cron:          cron.New(cron.WithSeconds()),
+		nodeID:        defaultNodeID(),
 		grafanaURL:    grafanaURL,
 		artifactsPath: artifactsPath,
-		workerPool:    make(chan struct{}, maxConcurrent),
+		workers:       newWorkerPool(maxConcurrent),
+		timers:        newTimers(),
+		planDone:      make(chan struct{}),
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		dispatchDone:  make(chan struct{}),
 		baseCtx:       context.Background(), // Will be updated when plugin starts
-		renderers:     make(map[int64]render.Backend),
+		renderers:     newRendererCache(),
+		running:       make(map[int64]context.CancelCauseFunc),
 	}
 }
 
+// defaultNodeID returns a stable identifier for this plugin instance. GF_PLUGIN_NODE_ID takes
+// precedence; otherwise the hostname is used, which stays stable across restarts of the same
+// container so runs it left behind can be recovered immediately instead of after the lease expires.
+func defaultNodeID() string {
+	if nodeID := os.Getenv("GF_PLUGIN_NODE_ID"); nodeID != "" {
+		return nodeID
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return fmt.Sprintf("node-%d", os.Getpid())
+}
+
 // SetContext sets the base context for the scheduler (should be called on plugin initialization)
 func (s *Scheduler) SetContext(ctx context.Context) {
 	s.baseCtx = ctx
@@ -50,89 +138,490 @@ func (s *Scheduler) SetContext(ctx context.Context) {
 
 // Start starts the scheduler
 func (s *Scheduler) Start() error {
-	// Add a job that runs every minute to check for due schedules
-	_, err := s.cron.AddFunc("0 * * * * *", s.checkDueSchedules)
+	// Recover runs orphaned by a previous process of this instance before any new work is claimed
+	if err := s.recoverQueue(s.nodeID); err != nil {
+		return fmt.Errorf("failed to recover run queue: %w", err)
+	}
+
+	if err := s.loadInstanceSettings(); err != nil {
+		return err
+	}
+
+	// Schedules are queued when their timer fires; the timers are rebuilt from the database
+	// periodically in case they missed a change
+	_, err := s.cron.AddFunc("@every "+reconcileInterval.String(), s.reconcile)
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
+	if _, err := s.cron.AddFunc("@every "+deliveryPruneInterval.String(), s.pruneDeliveries); err != nil {
+		return fmt.Errorf("failed to add cron job: %w", err)
+	}
 
+	s.reconcile()
 	s.cron.Start()
-	log.Println("Scheduler started")
+	go s.planLoop()
+	go s.dispatchLoop()
+	s.signalDispatcher()
+	log.Printf("Scheduler started (node %s)", s.nodeID)
 
 	return nil
 }
 
-// Stop stops the scheduler and cleans up browser instances
+// ApplySettings applies the instance-wide limits of saved settings at runtime. Only the main org's
+// settings size the worker pool; other orgs' limits are enforced per run when it is claimed.
+func (s *Scheduler) ApplySettings(settings *model.Settings) {
+	if settings.OrgID != mainOrgID {
+		return
+	}
+	if s.workers.resize(settings.Limits.WorkerPoolSize) {
+		log.Printf("Worker pool resized to %d", s.workers.capacity())
+		s.signalDispatcher()
+	}
+}
+
+// loadInstanceSettings applies the main org's saved settings, which may have been changed through
+// another instance
+func (s *Scheduler) loadInstanceSettings() error {
+	settings, err := s.store.GetSettings(mainOrgID)
+	if err != nil {
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	if settings != nil {
+		s.ApplySettings(settings)
+	}
+	return nil
+}
+
+// Stop shuts the scheduler down gracefully, see Shutdown
 func (s *Scheduler) Stop() {
-	s.cron.Stop()
+	s.Shutdown(shutdownGracePeriod)
+}
+
+// Shutdown stops the scheduler. It stops planning and claiming runs, waits up to grace for the runs
+// in flight to finish, then interrupts the remaining ones: they are recorded as "interrupted" and
+// put back in the queue, so they resume on the next start or on another instance. Browser instances
+// are closed last.
+func (s *Scheduler) Shutdown(grace time.Duration) {
+	graceCtx, cancelGrace := context.WithTimeout(context.Background(), grace)
+	defer cancelGrace()
+
+	cronDone := s.cron.Stop()
+	close(s.stop)
+	select {
+	case <-cronDone.Done():
+	case <-graceCtx.Done():
+	}
+	select {
+	case <-s.planDone:
+	case <-graceCtx.Done():
+	}
+	select {
+	case <-s.dispatchDone:
+	case <-graceCtx.Done():
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(drained)
+	}()
 
-	// Close all browser instances
-	for orgID, renderer := range s.renderers {
-		if err := renderer.Close(); err != nil {
-			log.Printf("Failed to close renderer for org %d: %v", orgID, err)
+	select {
+	case <-drained:
+		log.Println("All in-flight runs finished")
+	case <-graceCtx.Done():
+		s.runningMu.Lock()
+		log.Printf("Interrupting %d run(s) still in flight after %v", len(s.running), grace)
+		for _, cancel := range s.running {
+			cancel(errShutdown)
+		}
+		s.runningMu.Unlock()
+
+		select {
+		case <-drained:
+		case <-time.After(interruptTimeout):
+			log.Println("WARNING: some runs did not stop in time; they are recovered on the next start")
 		}
 	}
 
+	// Close all browser instances; those of runs that did not stop are closed when they end
+	s.renderers.closeAll()
+
 	log.Println("Scheduler stopped and browsers closed")
 }
 
-// checkDueSchedules checks for schedules that are due and executes them
+// checkDueSchedules queues a run for every schedule that is due and sets the timers of the queued
+// schedules to their next run
 func (s *Scheduler) checkDueSchedules() {
+	s.planMu.Lock()
+	defer s.planMu.Unlock()
+
 	schedules, err := s.store.GetDueSchedules()
 	if err != nil {
 		log.Printf("Failed to get due schedules: %v", err)
 		return
 	}
 
+	orgSettings := make(map[int64]*model.Settings)
 	for _, schedule := range schedules {
-		// Update next run time immediately to prevent duplicate execution
-		nextRun := s.calculateNextRun(schedule)
-		schedule.NextRunAt = &nextRun
-		if err := s.store.UpdateSchedule(schedule); err != nil {
-			log.Printf("Failed to update schedule %d next run time: %v", schedule.ID, err)
+		settings, ok := orgSettings[schedule.OrgID]
+		if !ok {
+			settings, _ = s.store.GetSettings(schedule.OrgID)
+			orgSettings[schedule.OrgID] = settings
+		}
+
+		now := time.Now()
+		plannedAt := now.Truncate(time.Second)
+		if schedule.NextRunAt != nil {
+			plannedAt = *schedule.NextRunAt
+		}
+
+		// Occurrences missed while the plugin was down are handled by the schedule's misfire policy
+		due, err := dueOccurrences(schedule, s.scheduleCalendar(schedule), schedule.MisfirePolicy, plannedAt, now)
+		if err != nil {
+			log.Printf("Failed to list due occurrences for schedule %d: %v", schedule.ID, err)
+			due = []time.Time{plannedAt}
+		}
+		selected := limitOccurrences(schedule, selectOccurrences(schedule.MisfirePolicy, due, now))
+		if len(due) > 1 || len(selected) < len(due) || now.Sub(plannedAt) > misfireThreshold {
+			log.Printf("Schedule %d missed occurrences since %s; misfire policy %q queues %d",
+				schedule.ID, plannedAt.Format(time.RFC3339), schedule.MisfirePolicy, len(selected))
+		}
+
+		offset := staggerOffset(schedule.ID, resolveSpread(schedule, settings))
+		runs := make([]*model.Run, 0, len(selected))
+		for _, occurrence := range selected {
+			scheduledFor := occurrence
+			run := &model.Run{
+				ScheduleID:    schedule.ID,
+				OrgID:         schedule.OrgID,
+				OccurrenceKey: occurrenceKey(schedule.ID, occurrence),
+				ScheduledFor:  &scheduledFor,
+			}
+			if notBefore := occurrence.Add(offset); notBefore.After(now) {
+				s.staggerRun(schedule, run, notBefore)
+			}
+			runs = append(runs, run)
+		}
+
+		// Advancing the next run time and queueing the runs happen in one transaction
+		// to prevent duplicate execution and lost occurrences
+		nextRun := s.nextRun(schedule, now, len(runs))
+		items, err := s.store.EnqueueScheduledRuns(schedule, nextRun, runs)
+		if err != nil {
+			log.Printf("Failed to queue runs for schedule %d: %v", schedule.ID, err)
+			s.timers.set(schedule.ID, now.Add(planRetryDelay))
+			continue
+		}
+		if len(items) < len(runs) {
+			log.Printf("Schedule %d: %d of %d occurrence(s) were already queued", schedule.ID, len(runs)-len(items), len(runs))
+		}
+		if items == nil {
+			// Another instance queued the occurrences and advanced the schedule; this one keeps a timer
+			// for the next run time in case that instance goes away
+			s.retrackSchedule(schedule)
 			continue
 		}
+		s.TrackSchedule(schedule)
+		for _, item := range items {
+			if item.NotBefore != nil {
+				time.AfterFunc(time.Until(*item.NotBefore), s.signalDispatcher)
+			}
+		}
+		if nextRun == nil && !schedule.Enabled {
+			// This instance disabled the schedule, so it alone notifies the owner
+			reason := expiryReason(schedule)
+			log.Printf("Schedule %d expired and was disabled: %s", schedule.ID, reason)
+			go s.notifyExpired(schedule, reason)
+		}
+	}
 
-		// Execute in worker pool
-		go s.executeSchedule(schedule)
+	if len(schedules) > 0 {
+		s.signalDispatcher()
 	}
 }
 
-// ExecuteSchedule executes a schedule immediately (for manual runs)
-func (s *Scheduler) ExecuteSchedule(schedule *model.Schedule) {
-	go s.executeSchedule(schedule)
+// staggerRun delays a scheduled run to its start within the spread window. The report still covers
+// the time range of its fire time, as if it had not been delayed.
+func (s *Scheduler) staggerRun(schedule *model.Schedule, run *model.Run, notBefore time.Time) {
+	run.NotBefore = &notBefore
+	if err := pinTimeRange(schedule, run); err != nil {
+		log.Printf("Failed to resolve time range of staggered schedule %d, rendering it at start time: %v", schedule.ID, err)
+	}
 }
 
-// executeSchedule executes a single schedule
-func (s *Scheduler) executeSchedule(schedule *model.Schedule) {
-	// Acquire worker slot
-	s.workerPool <- struct{}{}
-	defer func() { <-s.workerPool }()
-
-	// Create run record
+// ExecuteSchedule queues a schedule for immediate execution (for manual runs). When the schedule
+// is due within manualClaimWindow, the manual run fulfils that occurrence; store.ErrDuplicateOccurrence
+// is returned if the occurrence was already queued.
+func (s *Scheduler) ExecuteSchedule(schedule *model.Schedule) (*model.Run, error) {
 	run := &model.Run{
 		ScheduleID: schedule.ID,
 		OrgID:      schedule.OrgID,
-		StartedAt:  time.Now(),
-		Status:     "running",
+	}
+	if schedule.Enabled && schedule.NextRunAt != nil && schedule.NextRunAt.Before(time.Now().Add(manualClaimWindow)) {
+		run.OccurrenceKey = occurrenceKey(schedule.ID, *schedule.NextRunAt)
+		run.ScheduledFor = schedule.NextRunAt
 	}
 
-	if err := s.store.CreateRun(run); err != nil {
-		log.Printf("Failed to create run record: %v", err)
+	if _, err := s.store.EnqueueRun(run, "manual"); err != nil {
+		if errors.Is(err, store.ErrDuplicateOccurrence) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to queue run: %w", err)
+	}
+
+	s.signalDispatcher()
+	return run, nil
+}
+
+// occurrenceKey identifies a scheduled occurrence by schedule ID and planned fire time
+func occurrenceKey(scheduleID int64, plannedAt time.Time) string {
+	return fmt.Sprintf("%d@%s", scheduleID, plannedAt.UTC().Format(time.RFC3339))
+}
+
+// deliveryKey identifies the report delivery of a run: its occurrence when it has one, otherwise the run itself
+func deliveryKey(run *model.Run) string {
+	if run.OccurrenceKey != "" {
+		return run.OccurrenceKey
+	}
+	return fmt.Sprintf("run:%d", run.ID)
+}
+
+// deliveredSkip returns why a run is skipped when an earlier attempt of it already got to sending its
+// report, or nil. Reports are sent at most once, so one whose send was interrupted is not sent again;
+// the run records that its delivery state is unknown instead of passing as delivered.
+func (s *Scheduler) deliveredSkip(schedule *model.Schedule, run *model.Run) *skipRun {
+	settings, _ := s.store.GetSettings(schedule.OrgID)
+	state, err := s.store.DeliveryState(deliveryKey(run), resolveRunTimeout(schedule, settings))
+	if err != nil {
+		log.Printf("Failed to check the delivery of run %d (%s): %v", run.ID, deliveryKey(run), err)
+		return nil
+	}
+
+	switch state {
+	case store.DeliverySent:
+		return &skipRun{status: "skipped", reason: "Already delivered by an earlier attempt"}
+	case store.DeliverySending:
+		return &skipRun{status: "skipped", reason: "Being delivered by another attempt"}
+	case store.DeliveryUnknown:
+		return &skipRun{status: "skipped", reason: "Delivery state unknown: an earlier attempt was interrupted while sending the report"}
+	}
+	return nil
+}
+
+// signalDispatcher wakes the dispatcher without blocking
+func (s *Scheduler) signalDispatcher() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLoop claims queued runs whenever it is woken up or the poll interval elapses
+func (s *Scheduler) dispatchLoop() {
+	defer close(s.dispatchDone)
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+			// Pick up runs from instances whose lease expired
+			if err := s.recoverQueue(""); err != nil {
+				log.Printf("Failed to recover run queue: %v", err)
+			}
+			if err := s.loadInstanceSettings(); err != nil {
+				log.Printf("Failed to apply instance settings: %v", err)
+			}
+		}
+		s.dispatchQueued()
+	}
+}
+
+// dispatchQueued claims queued runs while worker slots are free
+func (s *Scheduler) dispatchQueued() {
+	for {
+		// Stop claiming work once the scheduler is shutting down
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		minPriority, ok := s.workers.acquire()
+		if !ok {
+			return
+		}
+		item, err := s.store.ClaimNextQueueItem(s.nodeID, queueLease, minPriority)
+		if err != nil || item == nil {
+			s.workers.release()
+			if err != nil {
+				log.Printf("Failed to claim queued run: %v", err)
+			}
+			return
+		}
+
+		s.inflight.Add(1)
+		go func() {
+			defer func() {
+				s.workers.release()
+				s.signalDispatcher()
+				s.inflight.Done()
+			}()
+			s.executeQueueItem(item)
+		}()
+	}
+}
+
+// recoverQueue re-queues or fails runs orphaned by dead instances (see store.RecoverQueue)
+func (s *Scheduler) recoverQueue(staleNodeID string) error {
+	requeued, failed, err := s.store.RecoverQueue(staleNodeID, maxQueueAttempts)
+	if err != nil {
+		return err
+	}
+	if requeued > 0 || failed > 0 {
+		log.Printf("Recovered run queue: %d run(s) re-queued, %d run(s) failed", requeued, failed)
+		s.signalDispatcher()
+	}
+	return nil
+}
+
+// pruneDeliveries deletes delivery records older than the main org's run history retention
+func (s *Scheduler) pruneDeliveries() {
+	days := defaultRetentionDays
+	if settings, err := s.store.GetSettings(mainOrgID); err != nil {
+		log.Printf("Failed to get settings: %v", err)
+		return
+	} else if settings != nil && settings.Limits.RetentionDays > 0 {
+		days = settings.Limits.RetentionDays
+	}
+
+	pruned, err := s.store.PruneDeliveries(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Printf("Failed to prune delivery records: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("Pruned %d delivery record(s) older than %d days", pruned, days)
+	}
+}
+
+// renewLease keeps the lease on a claimed queue item alive until done is closed. It calls cancel
+// when cancellation of the run was requested through another instance.
+func (s *Scheduler) renewLease(item *model.QueueItem, done <-chan struct{}, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			held, err := s.store.RenewQueueLease(item.ID, s.nodeID, queueLease)
+			if err != nil {
+				log.Printf("Failed to renew lease on queue item %d: %v", item.ID, err)
+			} else if !held {
+				log.Printf("WARNING: lease on queue item %d (run %d) was lost to another instance", item.ID, item.RunID)
+				return
+			}
+			if requested, err := s.store.QueueCancelRequested(item.ID); err != nil {
+				log.Printf("Failed to check cancellation of queue item %d: %v", item.ID, err)
+			} else if requested {
+				log.Printf("Cancelling run %d as requested", item.RunID)
+				cancel(errRunCancelled)
+			}
+		}
+	}
+}
+
+// executeQueueItem executes the run behind a claimed queue item
+func (s *Scheduler) executeQueueItem(item *model.QueueItem) {
+	ctx, cancel := context.WithCancelCause(s.baseCtx)
+	defer cancel(nil)
+	s.trackRun(item.RunID, cancel)
+	defer s.untrackRun(item.RunID)
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.renewLease(item, done, cancel)
+
+	run, err := s.store.GetRun(item.OrgID, item.RunID)
+	if err != nil {
+		log.Printf("Failed to load run %d for queue item %d: %v", item.RunID, item.ID, err)
+		if err := s.store.FinishQueueItem(item.ID, s.nodeID, "failed"); err != nil {
+			log.Printf("Failed to update queue item %d: %v", item.ID, err)
+		}
 		return
 	}
 
-	// Execute with retries
-	err := s.executeWithRetry(schedule, run, 3)
+	run.StartedAt = time.Now()
+	run.Status = "running"
+	run.ErrorText = ""
+	if err := s.store.UpdateRun(run); err != nil {
+		log.Printf("Failed to update run record: %v", err)
+	}
+
+	schedule, err := s.store.GetSchedule(item.OrgID, item.ScheduleID)
+	duplicate := false
+	if err != nil {
+		err = fmt.Errorf("failed to load schedule %d: %w", item.ScheduleID, err)
+	} else if skip := s.deliveredSkip(schedule, run); skip != nil {
+		// A previous attempt (e.g. before a restart or on another instance) got to sending the report
+		err, duplicate = skip, true
+	} else {
+		// Execute with retries, bounded by the run's deadline
+		s.resolveRunTimeRange(schedule, run)
+		settings, _ := s.store.GetSettings(schedule.OrgID)
+		timeout := resolveRunTimeout(schedule, settings)
+		deadline := time.Now().Add(timeout)
+		if item.NotBefore != nil && item.Attempts == 1 && run.ScheduledFor != nil {
+			// Staggering does not postpone delivery: the run delivers by the time it would have without it
+			if deliverBy := run.ScheduledFor.Add(timeout); deliverBy.Before(deadline) {
+				deadline = deliverBy
+			}
+		}
+		runCtx, cancelRun := context.WithDeadlineCause(withPhases(ctx), deadline, errDeadlineExceeded)
+		err = s.executeWithRetry(runCtx, scheduleForRun(schedule, run), run, resolveRetryPolicy(schedule, settings))
+		if err != nil && errors.Is(context.Cause(runCtx), errDeadlineExceeded) {
+			err = &deadlineExceeded{timeout: timeout, phase: currentPhase(runCtx), err: err}
+		}
+		cancelRun()
+	}
 
 	// Update run record
 	now := time.Now()
 	run.FinishedAt = &now
 
-	if err != nil {
+	queueStatus := "done"
+	var skip *skipRun
+	var deadline *deadlineExceeded
+	if errors.As(err, &skip) {
+		run.Status = skip.status
+		run.ErrorText = skip.reason
+		log.Printf("Schedule %d run %d %s: %s", item.ScheduleID, run.ID, skip.status, skip.reason)
+	} else if errors.As(err, &deadline) {
+		run.Status = "failed"
+		run.ErrorText = deadline.Error()
+		queueStatus = "failed"
+		log.Printf("Schedule %d run %d %s: %v", item.ScheduleID, run.ID, deadline.Error(), deadline.err)
+	} else if err != nil && errors.Is(context.Cause(ctx), errShutdown) {
+		run.Status = "interrupted"
+		run.ErrorText = "Interrupted by shutdown; resumes when the scheduler restarts"
+		queueStatus = "queued"
+		log.Printf("Schedule %d run %d interrupted by shutdown", item.ScheduleID, run.ID)
+	} else if err != nil && ctx.Err() != nil {
+		run.Status = "cancelled"
+		run.ErrorText = "Cancelled"
+		queueStatus = "cancelled"
+		log.Printf("Schedule %d run %d cancelled", item.ScheduleID, run.ID)
+	} else if err != nil {
 		run.Status = "failed"
 		run.ErrorText = err.Error()
-		log.Printf("Schedule %d execution failed: %v", schedule.ID, err)
+		queueStatus = "failed"
+		log.Printf("Schedule %d execution failed: %v", item.ScheduleID, err)
 	} else {
 		run.Status = "completed"
 	}
@@ -140,43 +629,153 @@ func (s *Scheduler) executeSchedule(schedule *model.Schedule) {
 	if err := s.store.UpdateRun(run); err != nil {
 		log.Printf("Failed to update run record: %v", err)
 	}
+	if queueStatus == "queued" {
+		if err := s.store.RequeueQueueItem(item.ID, s.nodeID); err != nil {
+			log.Printf("Failed to re-queue queue item %d: %v", item.ID, err)
+		}
+	} else if err := s.store.FinishQueueItem(item.ID, s.nodeID, queueStatus); err != nil {
+		log.Printf("Failed to update queue item %d: %v", item.ID, err)
+	}
 
-	// Update schedule last run time
-	schedule.LastRunAt = &run.StartedAt
-	if err := s.store.UpdateSchedule(schedule); err != nil {
-		log.Printf("Failed to update schedule last run time: %v", err)
+	// Update schedule last run time; backfills regenerate past reports and leave it alone, and so do
+	// runs whose report an earlier attempt took care of
+	if schedule != nil && run.BackfillID == nil && !duplicate {
+		if err := s.store.UpdateScheduleLastRun(schedule.ID, run.StartedAt); err != nil {
+			log.Printf("Failed to update schedule last run time: %v", err)
+		}
+	}
+
+	if run.BackfillID != nil && queueStatus != "queued" {
+		s.finishBackfill(run.OrgID, *run.BackfillID)
 	}
 }
 
-// executeWithRetry executes a schedule with retry logic
-func (s *Scheduler) executeWithRetry(schedule *model.Schedule, run *model.Run, maxRetries int) error {
-	var lastErr error
+// trackRun registers the cancel function of a run executing on this instance
+func (s *Scheduler) trackRun(runID int64, cancel context.CancelCauseFunc) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	s.running[runID] = cancel
+}
+
+// untrackRun removes a finished run from the running runs
+func (s *Scheduler) untrackRun(runID int64) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	delete(s.running, runID)
+}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt*attempt) * time.Second
-			log.Printf("Retrying schedule %d (attempt %d/%d) after %v", schedule.ID, attempt+1, maxRetries, backoff)
-			time.Sleep(backoff)
+// CancelRun cancels a queued or running run and reports whether it is already cancelled. A queued
+// run is cancelled right away. A run executing on this instance stops promptly; one executing on
+// another instance stops when that instance next renews its lease (leaseRenewInterval).
+// store.ErrRunNotActive is returned for runs that already finished.
+func (s *Scheduler) CancelRun(orgID, runID int64) (bool, error) {
+	cancelled, err := s.store.CancelRun(orgID, runID)
+	if err != nil {
+		return false, err
+	}
+	if cancelled {
+		// The run never started, so no worker finishes its backfill
+		if run, err := s.store.GetRun(orgID, runID); err == nil && run.BackfillID != nil {
+			go s.finishBackfill(orgID, *run.BackfillID)
 		}
+		return true, nil
+	}
+
+	s.runningMu.Lock()
+	cancel, ok := s.running[runID]
+	s.runningMu.Unlock()
+	if ok {
+		cancel(errRunCancelled)
+	}
+	return false, nil
+}
 
-		err := s.executeScheduleOnce(schedule, run)
+// resolveRunTimeRange pins a late run to the time window of the occurrence it fulfils, so a report
+// caught up after downtime covers the period it would have covered had it run on time
+func (s *Scheduler) resolveRunTimeRange(schedule *model.Schedule, run *model.Run) {
+	if run.RangeFrom != "" || run.ScheduledFor == nil || time.Since(*run.ScheduledFor) <= misfireThreshold {
+		return
+	}
+
+	loc, err := scheduleLocation(schedule)
+	if err == nil {
+		var from, to time.Time
+		from, to, err = resolveTimeRange(schedule.RangeFrom, schedule.RangeTo, *run.ScheduledFor, loc)
 		if err == nil {
-			return nil
+			run.RangeFrom = strconv.FormatInt(from.UnixMilli(), 10)
+			run.RangeTo = strconv.FormatInt(to.UnixMilli(), 10)
+			log.Printf("Run %d is late for %s; rendering its original time range %s to %s",
+				run.ID, run.ScheduledFor.Format(time.RFC3339), from.Format(time.RFC3339), to.Format(time.RFC3339))
+			return
+		}
+	}
+	log.Printf("Failed to resolve original time range of run %d, using %s to %s: %v", run.ID, schedule.RangeFrom, schedule.RangeTo, err)
+}
+
+// scheduleForRun returns the schedule as it should be rendered for a run, applying the run's time range
+// and variable overrides
+func scheduleForRun(schedule *model.Schedule, run *model.Run) *model.Schedule {
+	if (run.RangeFrom == "" || run.RangeTo == "") && len(run.Variables) == 0 {
+		return schedule
+	}
+	override := *schedule
+	if run.RangeFrom != "" && run.RangeTo != "" {
+		override.RangeFrom = run.RangeFrom
+		override.RangeTo = run.RangeTo
+	}
+	if len(run.Variables) > 0 {
+		override.Variables = make(model.JSONMap, len(schedule.Variables)+len(run.Variables))
+		for name, value := range schedule.Variables {
+			override.Variables[name] = value
+		}
+		for name, value := range run.Variables {
+			override.Variables[name] = value
+		}
+	}
+	return &override
+}
+
+// executeWithRetry executes a schedule, retrying failures according to the retry policy. Permanent
+// errors and skips end the run without further attempts.
+func (s *Scheduler) executeWithRetry(ctx context.Context, schedule *model.Schedule, run *model.Run, policy model.RetryPolicy) error {
+	var lastErr error
+
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			backoff := retryDelay(policy, attempt-1, rand.Float64)
+			log.Printf("Retrying schedule %d (attempt %d/%d) after %v", schedule.ID, attempt, policy.MaxAttempts, backoff)
+			enterPhase(ctx, phaseBackoff)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := s.executeScheduleOnce(ctx, schedule, run)
+		var skip *skipRun
+		if err == nil || errors.As(err, &skip) {
+			return err
+		}
+		if ctx.Err() != nil {
+			// Cancelled, interrupted or out of time; the caller records why
+			return err
+		}
+		if isPermanent(err) {
+			log.Printf("Schedule %d execution attempt %d failed permanently: %v", schedule.ID, attempt, err)
+			return err
 		}
 
 		lastErr = err
-		log.Printf("Schedule %d execution attempt %d failed: %v", schedule.ID, attempt+1, err)
+		log.Printf("Schedule %d execution attempt %d failed: %v", schedule.ID, attempt, err)
 	}
 
-	return fmt.Errorf("all %d attempts failed: %w", maxRetries, lastErr)
+	return fmt.Errorf("all %d attempts failed: %w", policy.MaxAttempts, lastErr)
 }
 
-// executeScheduleOnce executes a schedule once
-func (s *Scheduler) executeScheduleOnce(schedule *model.Schedule, run *model.Run) error {
-	// Use the base context which has Grafana config
-	ctx := s.baseCtx
-
+// executeScheduleOnce executes a schedule once. ctx derives from the base context, which has the
+// Grafana config, and is cancelled when the run is or when it exceeds its deadline.
+func (s *Scheduler) executeScheduleOnce(ctx context.Context, schedule *model.Schedule, run *model.Run) error {
 	// Get settings
 	settings, err := s.store.GetSettings(schedule.OrgID)
 	if err != nil {
@@ -192,38 +791,85 @@ func (s *Scheduler) executeScheduleOnce(schedule *model.Schedule, run *model.Run
 		backendType = render.BackendType(settings.RendererConfig.Backend)
 	}
 
-	// Use configured Grafana URL from settings, fall back to scheduler default
-	grafanaURL := s.grafanaURL
-	if settings.RendererConfig.GrafanaURL != "" {
-		grafanaURL = settings.RendererConfig.GrafanaURL
-		log.Printf("DEBUG: Using configured Grafana URL from settings: %s", grafanaURL)
-	} else {
-		log.Printf("DEBUG: Using default Grafana URL: %s", grafanaURL)
-	}
+	grafanaURL := s.resolveGrafanaURL(settings)
 
 	log.Printf("DEBUG: Rendering with grafanaURL=%s, backend=%s (using managed service account)", grafanaURL, backendType)
 
-	// Get or create renderer for this org (reuse renderer instance)
-	// Note: We need to recreate if backend OR grafanaURL changes
-	renderer, exists := s.renderers[schedule.OrgID]
-	if !exists || renderer.Name() != string(backendType) {
-		// Create new renderer if doesn't exist or backend changed
-		var err error
-		renderer, err = render.NewBackend(backendType, grafanaURL, settings.RendererConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create renderer backend: %w", err)
+	// Exception reports are only sent when their condition holds over the report's time range.
+	// Backfills regenerate past reports on request, so they skip the condition and change checks.
+	if schedule.Condition != nil && run.BackfillID == nil {
+		enterPhase(ctx, phaseCondition)
+		if err := s.checkCondition(ctx, grafanaURL, settings, schedule, run); err != nil {
+			return err
 		}
-		s.renderers[schedule.OrgID] = renderer
-		log.Printf("Created new %s renderer for org %d with URL %s", backendType, schedule.OrgID, grafanaURL)
 	}
 
-	// Render dashboard (token will be retrieved from context inside renderer)
-	renderedData, err := renderer.RenderDashboard(ctx, schedule)
+	enterPhase(ctx, phaseRender)
+
+	// Get or create renderer for this org (reuse renderer instance); it is recreated when the
+	// backend, the Grafana URL or any renderer setting changes
+	entry, err := s.renderers.acquire(schedule.OrgID, rendererKey(backendType, grafanaURL, settings.RendererConfig), func() (render.Backend, error) {
+		renderer, err := render.NewBackend(backendType, grafanaURL, settings.RendererConfig)
+		if err == nil {
+			log.Printf("Created new %s renderer for org %d with URL %s", backendType, schedule.OrgID, grafanaURL)
+		}
+		return renderer, err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to render dashboard: %w", err)
+		return fmt.Errorf("failed to create renderer backend: %w", err)
+	}
+	defer s.renderers.release(schedule.OrgID, entry)
+	renderer := entry.backend
+
+	// Render the whole dashboard, or each selected panel on its own (token will be retrieved from
+	// context inside renderer). The time spent waiting for panels to load is recorded on the run,
+	// also when rendering fails.
+	renderCtx, renderStats := render.WithStats(ctx)
+	var images [][]byte
+	if renderPanels(schedule) {
+		images, err = renderer.RenderPanels(renderCtx, schedule)
+		run.RenderWaitMS = renderStats.Wait().Milliseconds()
+		if err != nil {
+			return fmt.Errorf("failed to render panels: %w", err)
+		}
+		run.RenderedPages = len(schedule.PanelIDs)
+	} else {
+		renderedData, err := renderer.RenderDashboard(renderCtx, schedule)
+		run.RenderWaitMS = renderStats.Wait().Milliseconds()
+		if err != nil {
+			return fmt.Errorf("failed to render dashboard: %w", err)
+		}
+		images = [][]byte{renderedData}
+		run.RenderedPages = 1
 	}
 
-	run.RenderedPages = 1
+	// Fingerprint what was rendered (not the PDF, whose footer carries the generation time); the
+	// image hash of panels concatenates the hashes of each panel
+	checksum := sha256.New()
+	for _, image := range images {
+		checksum.Write(image)
+	}
+	run.Checksum = fmt.Sprintf("%x", checksum.Sum(nil))
+	run.ImageHash = ""
+	if backendType != render.BackendWkhtmltopdf {
+		var hashes strings.Builder
+		for _, image := range images {
+			hash, err := render.ImageHash(image)
+			if err != nil {
+				log.Printf("Failed to hash screenshot of schedule %d: %v", schedule.ID, err)
+				hashes.Reset()
+				break
+			}
+			hashes.WriteString(hash)
+		}
+		run.ImageHash = hashes.String()
+	}
+
+	if schedule.SkipUnchanged != SkipUnchangedOff && run.BackfillID == nil {
+		if err := s.checkUnchanged(schedule, run); err != nil {
+			return err
+		}
+	}
 
 	// Generate PDF or HTML
 	var reportData []byte
@@ -233,49 +879,117 @@ func (s *Scheduler) executeScheduleOnce(schedule *model.Schedule, run *model.Run
 		// Check if backend already returned PDF (wkhtmltopdf) or PNG (chromium)
 		if backendType == render.BackendWkhtmltopdf {
 			// wkhtmltopdf returns PDF directly
-			reportData = renderedData
+			reportData = images[0]
 			log.Printf("DEBUG: Using PDF directly from wkhtmltopdf backend (%d bytes)", len(reportData))
 		} else {
 			// chromium returns PNG, need to convert to PDF
+			enterPhase(ctx, phasePDF)
 			pdfGen := pdf.NewGenerator()
-			reportData, err = pdfGen.Generate([][]byte{renderedData}, pdf.Options{
+			opts := pdf.Options{
 				Title:       schedule.Name,
 				Orientation: "landscape",
 				PageSize:    "A4",
 				Header:      schedule.Name,
 				Footer:      fmt.Sprintf("Generated at %s", time.Now().Format(time.RFC1123)),
+				Layout:      pdfLayout(schedule),
+			}
+
+			// A capture taller than a page is split into pages between the dashboard's rows and panels
+			pages := images
+			if !renderPanels(schedule) {
+				pages, err = withContext(ctx, func() ([][]byte, error) {
+					return pdf.SplitPages(images[0], renderStats.Breaks(), opts)
+				})
+				if err != nil {
+					return fmt.Errorf("failed to split capture into pages: %w", err)
+				}
+				if len(pages) > 1 {
+					opts.Layout = pdf.LayoutSlices
+				}
+				run.RenderedPages = len(pages)
+			}
+
+			reportData, err = withContext(ctx, func() ([]byte, error) {
+				return pdfGen.Generate(pages, opts)
 			})
 			if err != nil {
 				return fmt.Errorf("failed to generate PDF from PNG: %w", err)
 			}
 			log.Printf("DEBUG: Converted PNG to PDF (%d bytes)", len(reportData))
 		}
-		filename = fmt.Sprintf("%s-%s.pdf", schedule.Name, time.Now().Format("2006-01-02-150405"))
+		filename = fmt.Sprintf("%s-%s.pdf", schedule.Name, artifactStamp(run))
 	} else {
-		// For HTML format, use the rendered data directly
-		reportData = renderedData
-		filename = fmt.Sprintf("%s-%s.png", schedule.Name, time.Now().Format("2006-01-02-150405"))
+		// For HTML format, use the rendered data directly (panels require the pdf format)
+		reportData = images[0]
+		filename = fmt.Sprintf("%s-%s.png", schedule.Name, artifactStamp(run))
 	}
 
 	run.Bytes = int64(len(reportData))
 
-	// Calculate checksum
-	checksum := fmt.Sprintf("%x", sha256.Sum256(reportData))
-	run.Checksum = checksum
-
 	// Save artifact
+	enterPhase(ctx, phaseStorage)
 	artifactPath := filepath.Join(s.artifactsPath, fmt.Sprintf("org_%d", schedule.OrgID), filename)
 	if err := os.MkdirAll(filepath.Dir(artifactPath), 0755); err != nil {
 		return fmt.Errorf("failed to create artifacts directory: %w", err)
 	}
 
-	if err := os.WriteFile(artifactPath, reportData, 0644); err != nil {
+	if _, err := withContext(ctx, func() (struct{}, error) {
+		return struct{}{}, os.WriteFile(artifactPath, reportData, 0644)
+	}); err != nil {
 		return fmt.Errorf("failed to save artifact: %w", err)
 	}
 
 	run.ArtifactPath = artifactPath
 
+	if run.BackfillID != nil {
+		// Backfilled reports are emailed together once the whole backfill has finished
+		return nil
+	}
+
 	// Send email
+	enterPhase(ctx, phaseDelivery)
+	smtpConfig, err := resolveSMTPConfig(settings)
+	if err != nil {
+		return err
+	}
+
+	mailer := mail.NewMailer(smtpConfig)
+
+	// Interpolate template variables
+	vars := map[string]string{
+		"schedule.name":    schedule.Name,
+		"dashboard.title":  schedule.DashboardTitle,
+		"timerange":        describeTimeRange(schedule),
+		"run.started_at":   run.StartedAt.Format(time.RFC1123),
+	}
+
+	subject := mail.InterpolateTemplate(schedule.EmailSubject, vars)
+	body := mail.InterpolateTemplate(schedule.EmailBody, vars)
+
+	err = mailer.SendReportOnce(ctx, s.store, deliveryKey(run), schedule.Recipients, subject, body, reportData, filename)
+	if errors.Is(err, mail.ErrAlreadyDelivered) {
+		log.Printf("Run %d (%s) was already delivered, not sending it again", run.ID, deliveryKey(run))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// artifactStamp returns the timestamp in the artifact filename of a run: the occurrence a backfilled
+// run regenerates, otherwise the time the report was generated
+func artifactStamp(run *model.Run) string {
+	if run.BackfillID != nil && run.ScheduledFor != nil {
+		return run.ScheduledFor.Format("2006-01-02-150405")
+	}
+	return time.Now().Format("2006-01-02-150405")
+}
+
+// resolveSMTPConfig returns the SMTP server reports are sent through: Grafana's own (from its
+// environment) when the org uses Grafana SMTP and it is configured, otherwise the plugin's
+func resolveSMTPConfig(settings *model.Settings) (model.SMTPConfig, error) {
 	var smtpConfig model.SMTPConfig
 	if settings.UseGrafanaSMTP {
 		// Load Grafana SMTP config from environment variables
@@ -289,7 +1003,7 @@ func (s *Scheduler) executeScheduleOnce(schedule *model.Schedule, run *model.Run
 			if settings.SMTPConfig != nil {
 				smtpConfig = *settings.SMTPConfig
 			} else {
-				return fmt.Errorf("no SMTP configuration available")
+				return model.SMTPConfig{}, fmt.Errorf("no SMTP configuration available")
 			}
 		} else {
 			// Parse host:port
@@ -312,58 +1026,130 @@ func (s *Scheduler) executeScheduleOnce(schedule *model.Schedule, run *model.Run
 		}
 	} else {
 		if settings.SMTPConfig == nil {
-			return fmt.Errorf("SMTP configuration not set")
+			return model.SMTPConfig{}, fmt.Errorf("SMTP configuration not set")
 		}
 		smtpConfig = *settings.SMTPConfig
 	}
+	return smtpConfig, nil
+}
 
-	mailer := mail.NewMailer(smtpConfig)
+// resolveGrafanaURL returns the Grafana URL configured in the org's settings, falling back to the scheduler's default
+func (s *Scheduler) resolveGrafanaURL(settings *model.Settings) string {
+	if settings.RendererConfig.GrafanaURL != "" {
+		log.Printf("DEBUG: Using configured Grafana URL from settings: %s", settings.RendererConfig.GrafanaURL)
+		return settings.RendererConfig.GrafanaURL
+	}
+	log.Printf("DEBUG: Using default Grafana URL: %s", s.grafanaURL)
+	return s.grafanaURL
+}
 
-	// Interpolate template variables
-	vars := map[string]string{
-		"schedule.name":    schedule.Name,
-		"dashboard.title":  schedule.DashboardTitle,
-		"timerange":        fmt.Sprintf("%s to %s", schedule.RangeFrom, schedule.RangeTo),
-		"run.started_at":   run.StartedAt.Format(time.RFC1123),
+// checkCondition evaluates the schedule's condition and records its value on the run. It returns a
+// skipRun error when the condition is not met.
+func (s *Scheduler) checkCondition(ctx context.Context, grafanaURL string, settings *model.Settings, schedule *model.Schedule, run *model.Run) error {
+	token, err := render.ServiceAccountToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate condition: %w", err)
 	}
 
-	subject := mail.InterpolateTemplate(schedule.EmailSubject, vars)
-	body := mail.InterpolateTemplate(schedule.EmailBody, vars)
+	evaluator := condition.NewEvaluator(grafanaURL, settings.RendererConfig.SkipTLSVerify)
+	result, err := evaluator.Evaluate(ctx, token, schedule.OrgID, schedule.Condition, schedule.RangeFrom, schedule.RangeTo)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate condition: %w", err)
+	}
 
-	if err := mailer.SendReport(schedule.Recipients, subject, body, reportData, filename); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+	run.ConditionValue = nil
+	if !result.NoData {
+		value := result.Value
+		run.ConditionValue = &value
 	}
 
+	description := result.Describe(schedule.Condition)
+	if !result.Met {
+		return &skipRun{status: "skipped", reason: description}
+	}
+	log.Printf("Schedule %d %s", schedule.ID, description)
 	return nil
 }
 
-// CalculateNextRun calculates the next run time for a schedule (exported for use in handlers)
-func (s *Scheduler) CalculateNextRun(schedule *model.Schedule) time.Time {
-	return s.calculateNextRun(schedule)
+// checkUnchanged compares a rendered run with the schedule's last completed run and returns a
+// skipRun error when the report has not changed since it was last sent
+func (s *Scheduler) checkUnchanged(schedule *model.Schedule, run *model.Run) error {
+	previous, err := s.store.GetLastCompletedRun(schedule.ID, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load previous run: %w", err)
+	}
+	if previous == nil {
+		return nil
+	}
+
+	if unchanged, reason := unchangedSince(schedule.SkipUnchanged, previous, run); unchanged {
+		return &skipRun{status: "skipped_unchanged", reason: reason}
+	}
+	return nil
 }
 
-// calculateNextRun calculates the next run time for a schedule
-func (s *Scheduler) calculateNextRun(schedule *model.Schedule) time.Time {
-	now := time.Now()
+// CalculateNextRun calculates the next run time for a schedule (exported for use in handlers). It
+// returns nil when the schedule has no occurrences left in its validity window.
+func (s *Scheduler) CalculateNextRun(schedule *model.Schedule) *time.Time {
+	return s.nextRun(schedule, time.Now(), 0)
+}
 
-	switch schedule.IntervalType {
-	case "daily":
-		return now.Add(24 * time.Hour)
-	case "weekly":
-		return now.Add(7 * 24 * time.Hour)
-	case "monthly":
-		return now.AddDate(0, 1, 0)
-	case "cron":
-		if schedule.CronExpr != "" {
-			parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-			sched, err := parser.Parse(schedule.CronExpr)
-			if err != nil {
-				log.Printf("Failed to parse cron expression %s: %v", schedule.CronExpr, err)
-				return now.Add(1 * time.Hour)
-			}
-			return sched.Next(now)
-		}
+// UpcomingRuns returns up to count upcoming fire times of a schedule as the scheduler will compute
+// them (timezone, holiday calendar, validity window and occurrence limit), each with the absolute
+// time range its report will cover
+func (s *Scheduler) UpcomingRuns(schedule *model.Schedule, count int) ([]model.UpcomingRun, error) {
+	var offset time.Duration
+	if schedule.ID != 0 {
+		// The stagger offset derives from the schedule ID, which unsaved schedules do not have yet
+		settings, _ := s.store.GetSettings(schedule.OrgID)
+		offset = staggerOffset(schedule.ID, resolveSpread(schedule, settings))
+	}
+	return upcomingRuns(schedule, s.scheduleCalendar(schedule), time.Now(), count, offset)
+}
+
+// nextRun calculates the next run time of a schedule after the given instant, with queued more
+// occurrences counting towards its limit. It returns nil when the schedule has no occurrences left.
+func (s *Scheduler) nextRun(schedule *model.Schedule, after time.Time, queued int) *time.Time {
+	next, ok, err := nextWindowRun(schedule, s.scheduleCalendar(schedule), after, queued)
+	if err != nil {
+		log.Printf("Failed to calculate next run for schedule %d: %v", schedule.ID, err)
+		next, ok = after.Add(1*time.Hour), true
+	}
+	if !ok {
+		return nil
+	}
+	return &next
+}
+
+// scheduleCalendar loads the holiday calendar referenced by a schedule (nil when it has none)
+func (s *Scheduler) scheduleCalendar(schedule *model.Schedule) *model.Calendar {
+	if schedule.CalendarID == nil {
+		return nil
+	}
+	cal, err := s.store.GetCalendar(schedule.OrgID, *schedule.CalendarID)
+	if err != nil {
+		log.Printf("Failed to load calendar %d for schedule %d, ignoring it: %v", *schedule.CalendarID, schedule.ID, err)
+		return nil
 	}
+	return cal
+}
 
-	return now.Add(1 * time.Hour)
+// Reschedule recalculates and stores the next run time of schedules, e.g. after their calendar changed
+func (s *Scheduler) Reschedule(schedules []*model.Schedule) error {
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+		// Schedules without occurrences left keep their next run time and expire when it is due
+		nextRun := s.CalculateNextRun(schedule)
+		if nextRun == nil {
+			continue
+		}
+		if err := s.store.UpdateScheduleNextRun(schedule.ID, *nextRun); err != nil {
+			return err
+		}
+		schedule.NextRunAt = nextRun
+		s.TrackSchedule(schedule)
+	}
+	return nil
 }
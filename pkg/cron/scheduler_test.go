@@ -0,0 +1,156 @@
+package cron
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/store"
+)
+
+func TestShutdown_InterruptsInFlightRuns(t *testing.T) {
+	// Grafana hangs on the dashboard lookup until the run is interrupted
+	release := make(chan struct{})
+	grafana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer grafana.Close()
+	defer close(release)
+
+	st, scheduler, schedule := newTestScheduler(t, grafana.URL, &model.Schedule{})
+	run, err := scheduler.ExecuteSchedule(schedule)
+	if err != nil {
+		t.Fatalf("ExecuteSchedule() error = %v", err)
+	}
+
+	waitForRun(t, st, run.ID, "running")
+
+	start := time.Now()
+	scheduler.Shutdown(200 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Shutdown() took %v, want it bounded by the grace period", elapsed)
+	}
+
+	interrupted, err := st.GetRun(1, run.ID)
+	if err != nil || interrupted.Status != "interrupted" {
+		t.Fatalf("run after shutdown = %+v, %v; want interrupted", interrupted, err)
+	}
+
+	// The interrupted run is back in the queue for the next instance to resume
+	item, err := st.ClaimNextQueueItem("node-b", time.Minute, store.PriorityBackground)
+	if err != nil || item == nil || item.RunID != run.ID {
+		t.Errorf("ClaimNextQueueItem() = %+v, %v; want run %d re-queued", item, err, run.ID)
+	}
+}
+
+func TestExecute_DeadlineExceeded(t *testing.T) {
+	// Grafana hangs on the dashboard lookup past the run's deadline
+	grafana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer grafana.Close()
+
+	st, scheduler, schedule := newTestScheduler(t, grafana.URL, &model.Schedule{RunTimeoutSeconds: 1})
+	defer scheduler.Shutdown(time.Second)
+
+	run, err := scheduler.ExecuteSchedule(schedule)
+	if err != nil {
+		t.Fatalf("ExecuteSchedule() error = %v", err)
+	}
+	failed := waitForRun(t, st, run.ID, "failed")
+
+	want := "Run exceeded its deadline of 1s during rendering"
+	if failed.ErrorText != want {
+		t.Errorf("ErrorText = %q, want %q", failed.ErrorText, want)
+	}
+}
+
+func TestExecute_AlreadyDelivered(t *testing.T) {
+	st, scheduler, schedule := newTestScheduler(t, "http://grafana.invalid", &model.Schedule{RunTimeoutSeconds: 1})
+	defer scheduler.Shutdown(time.Second)
+
+	// One occurrence was sent before a restart; another one's send was interrupted by a crash
+	for _, key := range []string{"1@sent", "1@crashed"} {
+		if ok, err := st.BeginDelivery(key); err != nil || !ok {
+			t.Fatalf("BeginDelivery(%s) = %v, %v", key, ok, err)
+		}
+	}
+	if err := st.CompleteDelivery("1@sent"); err != nil {
+		t.Fatalf("CompleteDelivery() error = %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond) // Past the run timeout the reservation is stale
+
+	tests := []struct {
+		key    string
+		reason string
+	}{
+		{key: "1@sent", reason: "Already delivered by an earlier attempt"},
+		{key: "1@crashed", reason: "Delivery state unknown: an earlier attempt was interrupted while sending the report"},
+	}
+	for _, tt := range tests {
+		run := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: tt.key}
+		if _, err := st.EnqueueRun(run, "schedule"); err != nil {
+			t.Fatalf("EnqueueRun() error = %v", err)
+		}
+		scheduler.signalDispatcher()
+
+		skipped := waitForRun(t, st, run.ID, "skipped")
+		if skipped.ErrorText != tt.reason {
+			t.Errorf("run %s ErrorText = %q, want %q", tt.key, skipped.ErrorText, tt.reason)
+		}
+	}
+
+	if stored, err := st.GetSchedule(schedule.OrgID, schedule.ID); err != nil || stored.LastRunAt != nil {
+		t.Errorf("schedule last run = %v, %v; want none recorded for skipped duplicates", stored.LastRunAt, err)
+	}
+}
+
+// newTestScheduler starts a scheduler for org 1 against the given Grafana, with a store in a temp
+// directory and a daily schedule created from the fields set on schedule
+func newTestScheduler(t *testing.T, grafanaURL string, schedule *model.Schedule) (*store.Store, *Scheduler, *model.Schedule) {
+	t.Helper()
+	t.Setenv("GF_PLUGIN_SA_TOKEN", "token")
+
+	st, err := store.NewStore(filepath.Join(t.TempDir(), "reporting.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	if err := st.UpsertSettings(&model.Settings{OrgID: 1, RendererConfig: model.RendererConfig{GrafanaURL: grafanaURL}}); err != nil {
+		t.Fatalf("UpsertSettings() error = %v", err)
+	}
+	schedule.OrgID, schedule.Name, schedule.DashboardUID = 1, "weekly", "abc"
+	schedule.RangeFrom, schedule.RangeTo = "now-7d", "now"
+	schedule.IntervalType, schedule.TimeOfDay, schedule.Timezone, schedule.Format = "daily", "08:00", "UTC", "pdf"
+	schedule.Recipients = model.Recipients{To: []string{"ops@example.com"}}
+	if err := st.CreateSchedule(schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+
+	scheduler := NewScheduler(st, grafanaURL, t.TempDir(), 1)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	return st, scheduler, schedule
+}
+
+// waitForRun polls a run until it reaches the given status
+func waitForRun(t *testing.T, st *store.Store, runID int64, status string) *model.Run {
+	t.Helper()
+	for i := 0; ; i++ {
+		if current, _ := st.GetRun(1, runID); current != nil && current.Status == status {
+			return current
+		}
+		if i == 250 {
+			t.Fatalf("run %d did not reach status %q", runID, status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
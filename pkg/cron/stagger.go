@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// maxSpreadMinutes is the widest spread window a schedule or org may configure
+const maxSpreadMinutes = 60
+
+// ValidateSpread checks a spread window in minutes; 0 disables staggering (or, on a schedule, uses the org's window)
+func ValidateSpread(minutes int) error {
+	if minutes < 0 || minutes > maxSpreadMinutes {
+		return fmt.Errorf("spread_minutes must be between 0 and %d", maxSpreadMinutes)
+	}
+	return nil
+}
+
+// resolveSpread returns the spread window of a schedule: its own, else the org's. The window is
+// capped at half the run deadline, so a staggered run keeps at least half its time budget.
+func resolveSpread(schedule *model.Schedule, settings *model.Settings) time.Duration {
+	minutes := schedule.SpreadMinutes
+	if minutes == 0 && settings != nil {
+		minutes = settings.Limits.SpreadMinutes
+	}
+	spread := time.Duration(minutes) * time.Minute
+	if limit := resolveRunTimeout(schedule, settings) / 2; spread > limit {
+		spread = limit
+	}
+	return spread
+}
+
+// staggerOffset returns how long after its fire time a schedule starts, in whole seconds below the
+// spread window. The offset is derived from the schedule ID, so a schedule always starts at the same
+// point of the window and schedules sharing a fire time are spread across it.
+func staggerOffset(scheduleID int64, spread time.Duration) time.Duration {
+	seconds := int64(spread / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(scheduleID, 10)))
+	return time.Duration(h.Sum64()%uint64(seconds)) * time.Second
+}
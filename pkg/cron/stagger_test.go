@@ -0,0 +1,55 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestStaggerOffset(t *testing.T) {
+	spread := 15 * time.Minute
+
+	seen := make(map[time.Duration]bool)
+	for id := int64(1); id <= 20; id++ {
+		offset := staggerOffset(id, spread)
+		if offset < 0 || offset >= spread || offset%time.Second != 0 {
+			t.Fatalf("staggerOffset(%d) = %v, want whole seconds in [0, %v)", id, offset, spread)
+		}
+		if again := staggerOffset(id, spread); again != offset {
+			t.Errorf("staggerOffset(%d) = %v, then %v; want deterministic", id, offset, again)
+		}
+		seen[offset] = true
+	}
+	if len(seen) < 15 {
+		t.Errorf("20 schedules got %d distinct offsets, want them spread across the window", len(seen))
+	}
+
+	if offset := staggerOffset(1, 0); offset != 0 {
+		t.Errorf("staggerOffset() without spread = %v, want 0", offset)
+	}
+}
+
+func TestResolveSpread(t *testing.T) {
+	org := &model.Settings{Limits: model.Limits{SpreadMinutes: 10, RunTimeoutSeconds: 3600}}
+
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		settings *model.Settings
+		want     time.Duration
+	}{
+		{name: "off", schedule: &model.Schedule{}, want: 0},
+		{name: "org default", schedule: &model.Schedule{}, settings: org, want: 10 * time.Minute},
+		{name: "schedule override", schedule: &model.Schedule{SpreadMinutes: 30}, settings: org, want: 30 * time.Minute},
+		{name: "capped at half the deadline", schedule: &model.Schedule{SpreadMinutes: 30, RunTimeoutSeconds: 1200}, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSpread(tt.schedule, tt.settings); got != tt.want {
+				t.Errorf("resolveSpread() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// resolveTimeRange resolves a Grafana time range ("now-7d" to "now", "now-1M/M" to "now-1M/M", absolute
+// dates, epoch milliseconds) as it would have been evaluated at the given instant in the given timezone
+func resolveTimeRange(from, to string, at time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := resolveTimeExpr(from, at, loc, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range_from %q: %w", from, err)
+	}
+	end, err := resolveTimeExpr(to, at, loc, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range_to %q: %w", to, err)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("range_to %q is before range_from %q", to, from)
+	}
+	return start, end, nil
+}
+
+// resolveTimeExpr resolves a single time expression. Rounding ("/d") snaps to the start of the unit,
+// or to its end when roundUp is set, matching how Grafana treats the "to" side of a range.
+func resolveTimeExpr(expr string, at time.Time, loc *time.Location, roundUp bool) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return time.Time{}, fmt.Errorf("empty time expression")
+	}
+
+	if !strings.HasPrefix(expr, "now") {
+		return parseAbsoluteTime(expr, loc)
+	}
+
+	t := at.In(loc)
+	rest := expr[len("now"):]
+	for rest != "" {
+		op := rest[0]
+		rest = rest[1:]
+
+		switch op {
+		case '+', '-':
+			digits := 0
+			for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+				digits++
+			}
+			amount := 1
+			if digits > 0 {
+				amount, _ = strconv.Atoi(rest[:digits])
+			}
+			if digits >= len(rest) {
+				return time.Time{}, fmt.Errorf("missing unit in %q", expr)
+			}
+			if op == '-' {
+				amount = -amount
+			}
+			shifted, err := addTimeUnit(t, amount, rest[digits])
+			if err != nil {
+				return time.Time{}, err
+			}
+			t = shifted
+			rest = rest[digits+1:]
+
+		case '/':
+			if rest == "" {
+				return time.Time{}, fmt.Errorf("missing rounding unit in %q", expr)
+			}
+			rounded, err := roundTimeUnit(t, rest[0], roundUp)
+			if err != nil {
+				return time.Time{}, err
+			}
+			t = rounded
+			rest = rest[1:]
+
+		default:
+			return time.Time{}, fmt.Errorf("unexpected %q in %q", op, expr)
+		}
+	}
+
+	return t, nil
+}
+
+// addTimeUnit adds amount units to t using calendar arithmetic for days and longer
+func addTimeUnit(t time.Time, amount int, unit byte) (time.Time, error) {
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(amount) * time.Second), nil
+	case 'm':
+		return t.Add(time.Duration(amount) * time.Minute), nil
+	case 'h':
+		return t.Add(time.Duration(amount) * time.Hour), nil
+	case 'd':
+		return t.AddDate(0, 0, amount), nil
+	case 'w':
+		return t.AddDate(0, 0, 7*amount), nil
+	case 'M':
+		return t.AddDate(0, amount, 0), nil
+	case 'y':
+		return t.AddDate(amount, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown time unit %q", unit)
+}
+
+// roundTimeUnit snaps t to the start of its unit, or to the last millisecond of it when roundUp is set.
+// Weeks start on Monday.
+func roundTimeUnit(t time.Time, unit byte, roundUp bool) (time.Time, error) {
+	loc := t.Location()
+	var start time.Time
+
+	switch unit {
+	case 's':
+		start = t.Truncate(time.Second)
+	case 'm':
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	case 'h':
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case 'd':
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	case 'w':
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		start = time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	case 'M':
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	case 'y':
+		start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Time{}, fmt.Errorf("unknown time unit %q", unit)
+	}
+
+	if !roundUp {
+		return start, nil
+	}
+
+	end, err := addTimeUnit(start, 1, unit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return end.Add(-time.Millisecond), nil
+}
+
+// parseAbsoluteTime parses epoch milliseconds, RFC 3339 timestamps and dates in the given timezone
+func parseAbsoluteTime(value string, loc *time.Location) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).In(loc), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+// pinTimeRange sets a run's time range to the absolute range of the occurrence it fulfils (epoch ms),
+// so the report covers that period whenever it is rendered
+func pinTimeRange(schedule *model.Schedule, run *model.Run) error {
+	loc, err := scheduleLocation(schedule)
+	if err != nil {
+		return err
+	}
+	from, to, err := resolveTimeRange(schedule.RangeFrom, schedule.RangeTo, *run.ScheduledFor, loc)
+	if err != nil {
+		return err
+	}
+	run.RangeFrom = strconv.FormatInt(from.UnixMilli(), 10)
+	run.RangeTo = strconv.FormatInt(to.UnixMilli(), 10)
+	return nil
+}
+
+// describeTimeRange renders the time range of a schedule for email templates
+func describeTimeRange(schedule *model.Schedule) string {
+	loc, err := scheduleLocation(schedule)
+	if err != nil {
+		loc = time.UTC
+	}
+	return fmt.Sprintf("%s to %s", formatRangeBound(schedule.RangeFrom, loc), formatRangeBound(schedule.RangeTo, loc))
+}
+
+// formatRangeBound renders a range bound for humans, turning epoch milliseconds into a local timestamp
+func formatRangeBound(value string, loc *time.Location) string {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).In(loc).Format("2006-01-02 15:04 MST")
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestResolveTimeRange(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	at := time.Date(2025, 3, 12, 8, 0, 0, 0, berlin) // Wednesday
+
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{
+			name:     "relative days",
+			from:     "now-7d",
+			to:       "now",
+			wantFrom: time.Date(2025, 3, 5, 8, 0, 0, 0, berlin),
+			wantTo:   at,
+		},
+		{
+			name:     "yesterday",
+			from:     "now-1d/d",
+			to:       "now-1d/d",
+			wantFrom: time.Date(2025, 3, 11, 0, 0, 0, 0, berlin),
+			wantTo:   time.Date(2025, 3, 11, 23, 59, 59, 999e6, berlin),
+		},
+		{
+			name:     "previous week starts on Monday",
+			from:     "now-1w/w",
+			to:       "now-1w/w",
+			wantFrom: time.Date(2025, 3, 3, 0, 0, 0, 0, berlin),
+			wantTo:   time.Date(2025, 3, 9, 23, 59, 59, 999e6, berlin),
+		},
+		{
+			name:     "previous month",
+			from:     "now-1M/M",
+			to:       "now-1M/M",
+			wantFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, berlin),
+			wantTo:   time.Date(2025, 2, 28, 23, 59, 59, 999e6, berlin),
+		},
+		{
+			name:     "absolute bounds",
+			from:     "1735689600000",
+			to:       "2025-01-02",
+			wantFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 1, 2, 0, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := resolveTimeRange(tt.from, tt.to, at, berlin)
+			if err != nil {
+				t.Fatalf("resolveTimeRange() error = %v", err)
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("resolveTimeRange() = %v to %v, want %v to %v", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+
+	if _, _, err := resolveTimeRange("now-7x", "now", at, berlin); err == nil {
+		t.Errorf("resolveTimeRange() with unknown unit: expected error")
+	}
+}
+
+func TestSelectOccurrences(t *testing.T) {
+	schedule := &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", Timezone: "UTC"}
+	plannedAt := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 1, 4, 8, 2, 0, 0, time.UTC)
+
+	due, err := dueOccurrences(schedule, nil, MisfireRunAll, plannedAt, now)
+	if err != nil {
+		t.Fatalf("dueOccurrences() error = %v", err)
+	}
+	if len(due) != 4 {
+		t.Fatalf("dueOccurrences() = %d occurrences, want 4", len(due))
+	}
+
+	tests := []struct {
+		policy string
+		want   []time.Time
+	}{
+		{policy: "", want: due[3:]},
+		{policy: MisfireRunLatest, want: due[3:]},
+		{policy: MisfireRunAll, want: due},
+		{policy: MisfireSkip, want: due[3:]},
+	}
+
+	for _, tt := range tests {
+		got := selectOccurrences(tt.policy, due, now)
+		if len(got) != len(tt.want) || !got[0].Equal(tt.want[0]) {
+			t.Errorf("selectOccurrences(%q) = %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+
+	// After downtime with nothing on time, skip runs nothing
+	if got := selectOccurrences(MisfireSkip, due, now.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("selectOccurrences(skip) an hour late = %v, want none", got)
+	}
+}
+
+func TestDueOccurrences_LongOutage(t *testing.T) {
+	// A schedule firing every second, down for a week
+	schedule := &model.Schedule{IntervalType: "cron", CronExpr: "* * * * * *", Timezone: "UTC"}
+	now := time.Date(2025, 1, 8, 8, 0, 0, 0, time.UTC)
+	plannedAt := now.AddDate(0, 0, -7)
+
+	tests := []struct {
+		policy string
+		want   int
+	}{
+		{policy: MisfireRunLatest, want: 1},
+		{policy: MisfireRunAll, want: maxCatchUpRuns},
+		{policy: MisfireSkip, want: int(misfireThreshold/time.Second) + 1}, // Both ends of the window count
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			due, err := dueOccurrences(schedule, nil, tt.policy, plannedAt, now)
+			if err != nil {
+				t.Fatalf("dueOccurrences() error = %v", err)
+			}
+			if len(due) != tt.want || !due[len(due)-1].Equal(now) {
+				t.Errorf("dueOccurrences() = %d occurrences ending %v, want %d ending %v", len(due), due[len(due)-1], tt.want, now)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package cron
+
+import (
+	"container/heap"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// reconcileInterval is how often the in-memory timers are rebuilt from the database, picking up
+// schedules changed through another instance. The plan loop also wakes up at this interval when no
+// timer is due earlier.
+const reconcileInterval = 5 * time.Minute
+
+// planRetryDelay is how soon a schedule is planned again after queueing its runs failed, e.g. on a
+// busy database, instead of waiting for the next reconcile
+const planRetryDelay = 5 * time.Second
+
+// timer is the next run time of one schedule
+type timer struct {
+	scheduleID int64
+	at         time.Time
+	index      int // Position in the heap
+}
+
+// timerHeap orders timers by run time; it implements heap.Interface
+type timerHeap []*timer
+
+func (h timerHeap) Len() int           { return len(h) }
+func (h timerHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *timerHeap) Push(x any) {
+	t := x.(*timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return t
+}
+
+// timers keeps the next run time of every enabled schedule in memory, so the scheduler can sleep
+// until the earliest one is due instead of polling the database
+type timers struct {
+	mu      sync.Mutex
+	heap    timerHeap
+	byID    map[int64]*timer
+	changed chan struct{} // Signals the plan loop that the earliest timer may have changed
+}
+
+// newTimers creates an empty timer set
+func newTimers() *timers {
+	return &timers{
+		byID:    make(map[int64]*timer),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// set schedules the timer of a schedule, replacing its previous one
+func (t *timers) set(scheduleID int64, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if existing, ok := t.byID[scheduleID]; ok {
+		existing.at = at
+		heap.Fix(&t.heap, existing.index)
+	} else {
+		entry := &timer{scheduleID: scheduleID, at: at}
+		heap.Push(&t.heap, entry)
+		t.byID[scheduleID] = entry
+	}
+	t.signal()
+}
+
+// remove drops the timer of a schedule, if it has one
+func (t *timers) remove(scheduleID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if existing, ok := t.byID[scheduleID]; ok {
+		heap.Remove(&t.heap, existing.index)
+		delete(t.byID, scheduleID)
+		t.signal()
+	}
+}
+
+// reset replaces all timers with the given run times by schedule ID
+func (t *timers) reset(times map[int64]time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.heap = make(timerHeap, 0, len(times))
+	t.byID = make(map[int64]*timer, len(times))
+	for scheduleID, at := range times {
+		entry := &timer{scheduleID: scheduleID, at: at, index: len(t.heap)}
+		t.heap = append(t.heap, entry)
+		t.byID[scheduleID] = entry
+	}
+	heap.Init(&t.heap)
+	t.signal()
+}
+
+// next returns the earliest run time; ok is false when no schedule has a timer
+func (t *timers) next() (at time.Time, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.heap) == 0 {
+		return time.Time{}, false
+	}
+	return t.heap[0].at, true
+}
+
+// popDue removes and returns the schedules whose run time is not after now. Their new run times are
+// set again once their occurrences have been queued.
+func (t *timers) popDue(now time.Time) []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var due []int64
+	for len(t.heap) > 0 && !t.heap[0].at.After(now) {
+		entry := heap.Pop(&t.heap).(*timer)
+		delete(t.byID, entry.scheduleID)
+		due = append(due, entry.scheduleID)
+	}
+	return due
+}
+
+// signal wakes the plan loop without blocking; the caller holds mu
+func (t *timers) signal() {
+	select {
+	case t.changed <- struct{}{}:
+	default:
+	}
+}
+
+// TrackSchedule updates the timer of a schedule after it was created, updated or rescheduled.
+// Disabled schedules and schedules without a next run time are dropped.
+func (s *Scheduler) TrackSchedule(schedule *model.Schedule) {
+	if !schedule.Enabled || schedule.NextRunAt == nil {
+		s.timers.remove(schedule.ID)
+		return
+	}
+	s.timers.set(schedule.ID, *schedule.NextRunAt)
+}
+
+// retrackSchedule restores the timer of a due schedule whose occurrences another instance queued
+// first: the timer was popped when it fired, and the schedule's new next run time is only known
+// from the database. If it cannot be read (or was deleted meanwhile), planning is retried shortly.
+func (s *Scheduler) retrackSchedule(schedule *model.Schedule) {
+	current, err := s.store.GetSchedule(schedule.OrgID, schedule.ID)
+	if err != nil {
+		log.Printf("Failed to reload schedule %d: %v", schedule.ID, err)
+		s.timers.set(schedule.ID, time.Now().Add(planRetryDelay))
+		return
+	}
+	s.TrackSchedule(current)
+}
+
+// UntrackSchedule drops the timer of a deleted schedule
+func (s *Scheduler) UntrackSchedule(scheduleID int64) {
+	s.timers.remove(scheduleID)
+}
+
+// reconcile rebuilds the timers from the database and queues whatever is due. It is the fallback
+// for schedules changed through another instance and for timers lost to errors. Backfills whose
+// last run ended without a worker finishing them are completed as well.
+func (s *Scheduler) reconcile() {
+	times, err := s.store.ListNextRunTimes()
+	if err != nil {
+		log.Printf("Failed to load schedule run times: %v", err)
+	} else {
+		s.timers.reset(times)
+	}
+	s.checkDueSchedules()
+	s.finishBackfills()
+}
+
+// planLoop sleeps until the earliest schedule timer is due and queues the due schedules
+func (s *Scheduler) planLoop() {
+	defer close(s.planDone)
+	wait := time.NewTimer(reconcileInterval)
+	defer wait.Stop()
+
+	for {
+		delay := reconcileInterval
+		if at, ok := s.timers.next(); ok {
+			delay = min(time.Until(at), reconcileInterval)
+		}
+		if !wait.Stop() {
+			select {
+			case <-wait.C:
+			default:
+			}
+		}
+		wait.Reset(delay)
+
+		select {
+		case <-s.stop:
+			return
+		case <-s.timers.changed:
+			continue
+		case <-wait.C:
+		}
+
+		if due := s.timers.popDue(time.Now()); len(due) > 0 {
+			s.checkDueSchedules()
+		}
+	}
+}
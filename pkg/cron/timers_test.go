@@ -0,0 +1,84 @@
+package cron
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/store"
+)
+
+func TestTimers(t *testing.T) {
+	base := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+
+	timers := newTimers()
+	timers.reset(map[int64]time.Time{1: at(30), 2: at(10), 3: at(20)})
+	timers.set(4, at(5))
+	timers.set(2, at(40)) // Rescheduled
+	timers.remove(3)      // Deleted
+
+	if next, ok := timers.next(); !ok || !next.Equal(at(5)) {
+		t.Fatalf("next() = %v, %v; want %v", next, ok, at(5))
+	}
+	if due := timers.popDue(at(30)); !slices.Equal(due, []int64{4, 1}) {
+		t.Errorf("popDue() = %v, want [4 1] in run time order", due)
+	}
+	if due := timers.popDue(at(30)); len(due) != 0 {
+		t.Errorf("popDue() again = %v, want nothing", due)
+	}
+	if next, ok := timers.next(); !ok || !next.Equal(at(40)) {
+		t.Errorf("next() = %v, %v; want %v", next, ok, at(40))
+	}
+
+	timers.remove(2)
+	if next, ok := timers.next(); ok {
+		t.Errorf("next() = %v on empty timers", next)
+	}
+}
+
+func TestRetrackSchedule_LostRace(t *testing.T) {
+	st, err := store.NewStore(filepath.Join(t.TempDir(), "reporting.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer st.Close()
+
+	due := time.Now().Add(-time.Second).Truncate(time.Second)
+	schedule := &model.Schedule{
+		OrgID: 1, Name: "race", DashboardUID: "abc", RangeFrom: "now-7d", RangeTo: "now",
+		IntervalType: "daily", TimeOfDay: "08:00", Timezone: "UTC", Format: "pdf",
+		Recipients: model.Recipients{To: []string{"ops@example.com"}}, Enabled: true, NextRunAt: &due,
+	}
+	if err := st.CreateSchedule(schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	stale := *schedule
+
+	// Another instance queues the occurrence first and advances the schedule
+	next := due.Add(24 * time.Hour)
+	winner := *schedule
+	if _, err := st.EnqueueScheduledRuns(&winner, &next, []*model.Run{{ScheduleID: schedule.ID, OrgID: schedule.OrgID}}); err != nil {
+		t.Fatalf("EnqueueScheduledRuns() error = %v", err)
+	}
+	items, err := st.EnqueueScheduledRuns(&stale, &next, []*model.Run{{ScheduleID: schedule.ID, OrgID: schedule.OrgID}})
+	if err != nil || items != nil {
+		t.Fatalf("EnqueueScheduledRuns() after losing the race = %v, %v; want no items", items, err)
+	}
+
+	// The losing instance keeps a timer for the next run time
+	scheduler := NewScheduler(st, "http://grafana", t.TempDir(), 1)
+	scheduler.retrackSchedule(&stale)
+	if at, ok := scheduler.timers.next(); !ok || !at.Equal(next) {
+		t.Errorf("timer after losing the race = %v, %v; want %v", at, ok, next)
+	}
+
+	// Without a readable schedule, planning is retried shortly
+	st.Close()
+	scheduler.retrackSchedule(&stale)
+	if at, ok := scheduler.timers.next(); !ok || time.Until(at) > planRetryDelay {
+		t.Errorf("timer after a failed reload = %v, %v; want within %v", at, ok, planRetryDelay)
+	}
+}
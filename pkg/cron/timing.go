@@ -0,0 +1,306 @@
+package cron
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/yourusername/sheduled-reports-app/pkg/calendar"
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// Misfire policies decide what happens to occurrences missed while the plugin was down
+const (
+	MisfireRunLatest = "run_latest" // Run the most recent missed occurrence once (default)
+	MisfireSkip      = "skip"       // Drop missed occurrences and wait for the next one
+	MisfireRunAll    = "run_all"    // Run every missed occurrence with its original time window
+)
+
+// misfireThreshold is how late an occurrence may start before it counts as missed. Late runs render
+// the time window of their planned fire time instead of the window at the time they execute.
+const misfireThreshold = 5 * time.Minute
+
+// maxCalendarSearch bounds how many occurrences are inspected for one outside a calendar's blocked days
+const maxCalendarSearch = 10000
+
+// maxCatchUpRuns caps how many missed occurrences of one schedule are queued at once
+const maxCatchUpRuns = 50
+
+// cronParser parses the cron expressions used by schedules: standard 5-field expressions, or 6 fields
+// with a leading seconds field for sub-minute precision
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateTiming checks that the timing fields of a schedule can be evaluated
+func ValidateTiming(schedule *model.Schedule) error {
+	if _, err := scheduleLocation(schedule); err != nil {
+		return err
+	}
+
+	switch schedule.MisfirePolicy {
+	case "", MisfireRunLatest, MisfireSkip, MisfireRunAll:
+	default:
+		return fmt.Errorf("unknown misfire_policy %q", schedule.MisfirePolicy)
+	}
+	if err := calendar.ValidatePolicy(schedule.CalendarPolicy); err != nil {
+		return err
+	}
+
+	switch schedule.IntervalType {
+	case "daily", "weekly", "monthly":
+		if _, _, err := parseTimeOfDay(schedule.TimeOfDay); err != nil {
+			return err
+		}
+		if schedule.IntervalType == "weekly" && (schedule.DayOfWeek < 0 || schedule.DayOfWeek > 6) {
+			return fmt.Errorf("invalid day_of_week %d: must be between 0 (Sunday) and 6 (Saturday)", schedule.DayOfWeek)
+		}
+		if schedule.IntervalType == "monthly" && (schedule.DayOfMonth < 1 || schedule.DayOfMonth > 31) {
+			return fmt.Errorf("invalid day_of_month %d: must be between 1 and 31", schedule.DayOfMonth)
+		}
+	case "cron":
+		if _, err := cronParser.Parse(schedule.CronExpr); err != nil {
+			return fmt.Errorf("invalid cron expression %q: %w", schedule.CronExpr, err)
+		}
+	default:
+		return fmt.Errorf("unknown interval type %q", schedule.IntervalType)
+	}
+
+	return nil
+}
+
+// nextOccurrence returns the first fire time of the schedule strictly after the given instant.
+// Presets and cron expressions are evaluated as wall-clock times in the schedule's timezone,
+// so a report at 08:00 stays at 08:00 local time across DST changes.
+func nextOccurrence(schedule *model.Schedule, after time.Time) (time.Time, error) {
+	loc, err := scheduleLocation(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	local := after.In(loc)
+
+	switch schedule.IntervalType {
+	case "daily":
+		hour, minute, err := parseTimeOfDay(schedule.TimeOfDay)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for offset := 0; ; offset++ {
+			candidate := time.Date(local.Year(), local.Month(), local.Day()+offset, hour, minute, 0, 0, loc)
+			if candidate.After(after) {
+				return candidate, nil
+			}
+		}
+
+	case "weekly":
+		hour, minute, err := parseTimeOfDay(schedule.TimeOfDay)
+		if err != nil {
+			return time.Time{}, err
+		}
+		daysAhead := (schedule.DayOfWeek - int(local.Weekday()) + 7) % 7
+		for offset := daysAhead; ; offset += 7 {
+			candidate := time.Date(local.Year(), local.Month(), local.Day()+offset, hour, minute, 0, 0, loc)
+			if candidate.After(after) {
+				return candidate, nil
+			}
+		}
+
+	case "monthly":
+		hour, minute, err := parseTimeOfDay(schedule.TimeOfDay)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for offset := 0; ; offset++ {
+			year, month := local.Year(), local.Month()+time.Month(offset)
+			day := monthlyDay(schedule, year, month, loc)
+			candidate := time.Date(year, month, day, hour, minute, 0, 0, loc)
+			if candidate.After(after) {
+				return candidate, nil
+			}
+		}
+
+	case "cron":
+		sched, err := cronParser.Parse(schedule.CronExpr)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid cron expression %q: %w", schedule.CronExpr, err)
+		}
+		// The parsed schedule evaluates in the location of the time it is given
+		next := sched.Next(local)
+		if next.IsZero() {
+			return time.Time{}, fmt.Errorf("cron expression %q never fires", schedule.CronExpr)
+		}
+		return next, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unknown interval type %q", schedule.IntervalType)
+}
+
+// nextRunTime returns the first fire time of the schedule strictly after the given instant, taking the
+// schedule's holiday calendar (nil when it has none) and calendar policy into account
+func nextRunTime(schedule *model.Schedule, cal *model.Calendar, after time.Time) (time.Time, error) {
+	// A postponed occurrence can land after later regular ones, so keep the earliest candidate
+	// until the regular occurrences pass it
+	var best time.Time
+	search := after
+	for i := 0; i < maxCalendarSearch; i++ {
+		occurrence, err := nextOccurrence(schedule, search)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !best.IsZero() && occurrence.After(best) {
+			return best, nil
+		}
+
+		adjusted, ok := calendar.Adjust(cal, schedule.CalendarPolicy, occurrence)
+		if ok && adjusted.After(after) && (best.IsZero() || adjusted.Before(best)) {
+			best = adjusted
+		}
+
+		search = occurrence
+		if !ok {
+			// Blocked days are whole days: continue with the first occurrence after this one
+			search = time.Date(occurrence.Year(), occurrence.Month(), occurrence.Day()+1, 0, 0, 0, 0, occurrence.Location()).
+				Add(-time.Nanosecond)
+		}
+	}
+	if !best.IsZero() {
+		return best, nil
+	}
+	return time.Time{}, fmt.Errorf("no occurrence outside the blocked days of calendar %q", cal.Name)
+}
+
+// dueOccurrences lists the occurrences of a schedule from the planned fire time up to now, oldest
+// first, as far as its misfire policy can select them: the last maxCatchUpRuns for run_all, the ones
+// within misfireThreshold for skip and the latest one otherwise. After a long outage, walking every
+// missed occurrence (e.g. of a schedule firing every second) would take long, so the search starts
+// shortly before now and only reaches further back while it finds fewer occurrences than needed.
+func dueOccurrences(schedule *model.Schedule, cal *model.Calendar, policy string, plannedAt, now time.Time) ([]time.Time, error) {
+	keep := 1
+	switch policy {
+	case MisfireRunAll:
+		keep = maxCatchUpRuns
+	case MisfireSkip:
+		keep = math.MaxInt // All of them lie in the first window searched
+	}
+
+	for lookback := misfireThreshold; ; lookback *= 4 {
+		start := now.Add(-lookback)
+		reachesPlanned := !start.After(plannedAt)
+
+		var due []time.Time
+		after := start.Add(-time.Nanosecond)
+		if reachesPlanned {
+			due = append(due, plannedAt)
+			after = plannedAt
+		}
+		for {
+			next, err := nextRunTime(schedule, cal, after)
+			if err != nil {
+				return nil, err
+			}
+			if next.After(now) {
+				break
+			}
+			if len(due) == keep {
+				due = append(due[:0], due[1:]...) // Only the last ones are kept
+			}
+			due = append(due, next)
+			after = next
+		}
+
+		if reachesPlanned || policy == MisfireSkip || len(due) >= keep {
+			return due, nil
+		}
+	}
+}
+
+// selectOccurrences applies a schedule's misfire policy to its due occurrences (oldest first) and
+// returns the ones that should run
+func selectOccurrences(policy string, due []time.Time, now time.Time) []time.Time {
+	switch policy {
+	case MisfireSkip:
+		selected := make([]time.Time, 0, 1)
+		for _, occurrence := range due {
+			if now.Sub(occurrence) <= misfireThreshold {
+				selected = append(selected, occurrence)
+			}
+		}
+		return selected
+	case MisfireRunAll:
+		if len(due) > maxCatchUpRuns {
+			return due[len(due)-maxCatchUpRuns:]
+		}
+		return due
+	default:
+		return due[len(due)-1:]
+	}
+}
+
+// monthlyDay resolves the calendar day a monthly preset fires on in the given month
+func monthlyDay(schedule *model.Schedule, year int, month time.Month, loc *time.Location) int {
+	target := schedule.DayOfMonth
+	if target < 1 {
+		target = 1
+	}
+
+	// time.Date normalizes the month, so day 0 of the following month is the last day of this one
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+
+	if !schedule.BusinessDay {
+		if target > lastDay {
+			return lastDay
+		}
+		return target
+	}
+
+	// Count Monday-Friday days until the requested business day is reached
+	count, lastBusinessDay := 0, 1
+	for day := 1; day <= lastDay; day++ {
+		weekday := time.Date(year, month, day, 12, 0, 0, 0, loc).Weekday()
+		if weekday == time.Saturday || weekday == time.Sunday {
+			continue
+		}
+		count++
+		lastBusinessDay = day
+		if count == target {
+			return day
+		}
+	}
+	return lastBusinessDay
+}
+
+// scheduleLocation resolves the IANA timezone of a schedule (UTC when unset)
+func scheduleLocation(schedule *model.Schedule) (*time.Location, error) {
+	if schedule.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+	return loc, nil
+}
+
+// parseTimeOfDay parses an "HH:MM" string (midnight when empty)
+func parseTimeOfDay(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time_of_day %q: expected HH:MM", value)
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("invalid time_of_day %q: hour must be between 00 and 23", value)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time_of_day %q: minute must be between 00 and 59", value)
+	}
+
+	return hour, minute, nil
+}
@@ -0,0 +1,204 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestNextOccurrence(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	newYork := mustLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		after    time.Time
+		want     time.Time
+	}{
+		{
+			name:     "daily later today",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", Timezone: "Europe/Berlin"},
+			after:    time.Date(2025, 3, 10, 6, 0, 0, 0, berlin),
+			want:     time.Date(2025, 3, 10, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "daily already passed today",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", Timezone: "Europe/Berlin"},
+			after:    time.Date(2025, 3, 10, 8, 0, 0, 0, berlin),
+			want:     time.Date(2025, 3, 11, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "daily across spring forward keeps local time",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", Timezone: "Europe/Berlin"},
+			after:    time.Date(2025, 3, 29, 9, 0, 0, 0, berlin),
+			want:     time.Date(2025, 3, 30, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "daily inside skipped DST hour",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "02:30", Timezone: "Europe/Berlin"},
+			after:    time.Date(2025, 3, 29, 12, 0, 0, 0, berlin),
+			want:     time.Date(2025, 3, 30, 3, 30, 0, 0, berlin),
+		},
+		{
+			name:     "weekly next Monday",
+			schedule: &model.Schedule{IntervalType: "weekly", TimeOfDay: "07:30", DayOfWeek: 1, Timezone: "America/New_York"},
+			after:    time.Date(2025, 11, 5, 12, 0, 0, 0, newYork), // Wednesday
+			want:     time.Date(2025, 11, 10, 7, 30, 0, 0, newYork),
+		},
+		{
+			name:     "weekly same day before time",
+			schedule: &model.Schedule{IntervalType: "weekly", TimeOfDay: "07:30", DayOfWeek: 1, Timezone: "America/New_York"},
+			after:    time.Date(2025, 11, 10, 7, 0, 0, 0, newYork),
+			want:     time.Date(2025, 11, 10, 7, 30, 0, 0, newYork),
+		},
+		{
+			name:     "monthly clamps to last day",
+			schedule: &model.Schedule{IntervalType: "monthly", TimeOfDay: "09:00", DayOfMonth: 31, Timezone: "UTC"},
+			after:    time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly first business day skips weekend",
+			schedule: &model.Schedule{IntervalType: "monthly", TimeOfDay: "09:00", DayOfMonth: 1, BusinessDay: true, Timezone: "Europe/Berlin"},
+			after:    time.Date(2025, 5, 15, 0, 0, 0, 0, berlin),
+			want:     time.Date(2025, 6, 2, 9, 0, 0, 0, berlin), // June 1st 2025 is a Sunday
+		},
+		{
+			name:     "cron evaluated in schedule timezone",
+			schedule: &model.Schedule{IntervalType: "cron", CronExpr: "0 8 * * 1-5", Timezone: "America/New_York"},
+			after:    time.Date(2025, 11, 7, 14, 0, 0, 0, time.UTC), // Friday 09:00 in New York
+			want:     time.Date(2025, 11, 10, 8, 0, 0, 0, newYork),
+		},
+		{
+			name:     "cron with seconds field",
+			schedule: &model.Schedule{IntervalType: "cron", CronExpr: "30 0 8 * * *", Timezone: "Europe/Berlin"},
+			after:    time.Date(2025, 3, 10, 8, 0, 0, 0, berlin),
+			want:     time.Date(2025, 3, 10, 8, 0, 30, 0, berlin),
+		},
+		{
+			name:     "empty timezone defaults to UTC",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "23:15"},
+			after:    time.Date(2025, 1, 1, 23, 30, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 2, 23, 15, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextOccurrence(tt.schedule, tt.after)
+			if err != nil {
+				t.Fatalf("nextOccurrence() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("nextOccurrence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextRunTime_Calendar(t *testing.T) {
+	cal := &model.Calendar{
+		Name:     "holidays",
+		Holidays: model.CalendarDays{{Date: "2025-12-25"}, {Date: "2025-12-26"}},
+	}
+	after := time.Date(2025, 12, 24, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		policy string
+		want   time.Time
+	}{
+		{policy: "skip", want: time.Date(2025, 12, 27, 8, 0, 0, 0, time.UTC)},
+		{policy: "postpone", want: time.Date(2025, 12, 27, 8, 0, 0, 0, time.UTC)},
+		{policy: "pull_forward", want: time.Date(2025, 12, 27, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			daily := &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", CalendarPolicy: tt.policy}
+			got, err := nextRunTime(daily, cal, after)
+			if err != nil {
+				t.Fatalf("nextRunTime() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("nextRunTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// A weekly report due on a holiday moves to the next business day
+	weekly := &model.Schedule{IntervalType: "weekly", TimeOfDay: "08:00", DayOfWeek: 4, CalendarPolicy: "postpone"}
+	got, err := nextRunTime(weekly, cal, after)
+	if err != nil {
+		t.Fatalf("nextRunTime() error = %v", err)
+	}
+	if want := time.Date(2025, 12, 29, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("nextRunTime() weekly postponed = %v, want %v", got, want)
+	}
+}
+
+func TestValidateTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		wantErr  bool
+	}{
+		{
+			name:     "valid daily",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", Timezone: "UTC"},
+		},
+		{
+			name:     "invalid timezone",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "08:00", Timezone: "Mars/Olympus"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid time of day",
+			schedule: &model.Schedule{IntervalType: "daily", TimeOfDay: "25:00", Timezone: "UTC"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid weekday",
+			schedule: &model.Schedule{IntervalType: "weekly", DayOfWeek: 7, Timezone: "UTC"},
+			wantErr:  true,
+		},
+		{
+			name:     "day of month zero",
+			schedule: &model.Schedule{IntervalType: "monthly", TimeOfDay: "08:00", DayOfMonth: 0, Timezone: "UTC"},
+			wantErr:  true,
+		},
+		{
+			name:     "cron with seconds field",
+			schedule: &model.Schedule{IntervalType: "cron", CronExpr: "15 */5 * * * *", Timezone: "UTC"},
+		},
+		{
+			name:     "cron with too many fields",
+			schedule: &model.Schedule{IntervalType: "cron", CronExpr: "0 0 8 * * * 2025", Timezone: "UTC"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid cron expression",
+			schedule: &model.Schedule{IntervalType: "cron", CronExpr: "not a cron", Timezone: "UTC"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTiming(tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTiming() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// maxTriggerSourceLength caps the caller-provided source recorded on triggered runs
+const maxTriggerSourceLength = 200
+
+// ErrInvalidTrigger is returned for trigger calls whose overrides cannot be applied
+var ErrInvalidTrigger = errors.New("invalid trigger")
+
+// ErrScheduleDisabled is returned when a paused schedule is triggered
+var ErrScheduleDisabled = errors.New("schedule is paused")
+
+// TriggerSchedule queues a run of a schedule for an external trigger, e.g. an ETL pipeline that
+// finished loading. The request may override the time range and dashboard variables of this run;
+// a relative range is resolved when the trigger fires, so the report covers the same period however
+// long the run waits in the queue.
+func (s *Scheduler) TriggerSchedule(schedule *model.Schedule, token *model.TriggerToken, req model.TriggerRequest) (*model.Run, error) {
+	if !schedule.Enabled {
+		return nil, ErrScheduleDisabled
+	}
+
+	run := &model.Run{
+		ScheduleID:    schedule.ID,
+		OrgID:         schedule.OrgID,
+		TriggerID:     &token.ID,
+		TriggerSource: strings.TrimSpace(req.Source),
+		Variables:     req.Variables,
+	}
+	if run.TriggerSource == "" {
+		run.TriggerSource = token.Name
+	}
+	if len(run.TriggerSource) > maxTriggerSourceLength {
+		return nil, fmt.Errorf("%w: source is longer than %d characters", ErrInvalidTrigger, maxTriggerSourceLength)
+	}
+
+	if req.RangeFrom != "" || req.RangeTo != "" {
+		if req.RangeFrom == "" || req.RangeTo == "" {
+			return nil, fmt.Errorf("%w: range_from and range_to must be set together", ErrInvalidTrigger)
+		}
+		loc, err := scheduleLocation(schedule)
+		if err != nil {
+			return nil, err
+		}
+		from, to, err := resolveTimeRange(req.RangeFrom, req.RangeTo, time.Now(), loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTrigger, err)
+		}
+		if !from.Before(to) {
+			return nil, fmt.Errorf("%w: range_from must be before range_to", ErrInvalidTrigger)
+		}
+		run.RangeFrom = strconv.FormatInt(from.UnixMilli(), 10)
+		run.RangeTo = strconv.FormatInt(to.UnixMilli(), 10)
+	}
+
+	if _, err := s.store.EnqueueRun(run, "trigger"); err != nil {
+		return nil, fmt.Errorf("failed to queue run: %w", err)
+	}
+
+	s.signalDispatcher()
+	return run, nil
+}
@@ -0,0 +1,90 @@
+package cron
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/store"
+)
+
+func TestTriggerSchedule(t *testing.T) {
+	st, err := store.NewStore(filepath.Join(t.TempDir(), "reporting.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer st.Close()
+	scheduler := NewScheduler(st, "http://grafana", t.TempDir(), 1)
+
+	schedule := &model.Schedule{
+		OrgID: 1, Name: "daily sales", DashboardUID: "abc", RangeFrom: "now-7d", RangeTo: "now",
+		IntervalType: "daily", TimeOfDay: "08:00", Timezone: "UTC", Format: "pdf",
+		Recipients: model.Recipients{To: []string{"ops@example.com"}}, Enabled: true,
+	}
+	if err := st.CreateSchedule(schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	paused := *schedule
+	paused.Enabled = false
+	token := &model.TriggerToken{ID: 7, ScheduleID: schedule.ID, OrgID: 1, Name: "etl"}
+
+	tests := []struct {
+		name       string
+		schedule   *model.Schedule
+		req        model.TriggerRequest
+		wantSource string
+		wantRange  bool
+		wantErr    error
+	}{
+		{name: "no overrides", schedule: schedule, wantSource: "etl"},
+		{
+			name:       "range and variables",
+			schedule:   schedule,
+			req:        model.TriggerRequest{RangeFrom: "now-1d/d", RangeTo: "now-1d/d", Variables: map[string]string{"region": "eu"}, Source: "load #42"},
+			wantSource: "load #42",
+			wantRange:  true,
+		},
+		{name: "range without end", schedule: schedule, req: model.TriggerRequest{RangeFrom: "now-1d"}, wantErr: ErrInvalidTrigger},
+		{name: "unparsable range", schedule: schedule, req: model.TriggerRequest{RangeFrom: "yesterday", RangeTo: "now"}, wantErr: ErrInvalidTrigger},
+		{name: "empty range", schedule: schedule, req: model.TriggerRequest{RangeFrom: "now", RangeTo: "now-1h"}, wantErr: ErrInvalidTrigger},
+		{name: "paused schedule", schedule: &paused, wantErr: ErrScheduleDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := scheduler.TriggerSchedule(tt.schedule, token, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TriggerSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			stored, err := st.GetRun(1, run.ID)
+			if err != nil {
+				t.Fatalf("GetRun() error = %v", err)
+			}
+			if stored.Status != "queued" || stored.TriggerID == nil || *stored.TriggerID != token.ID || stored.TriggerSource != tt.wantSource {
+				t.Errorf("run = status %q, trigger %v, source %q; want queued, %d, %q",
+					stored.Status, stored.TriggerID, stored.TriggerSource, token.ID, tt.wantSource)
+			}
+			if (stored.RangeFrom != "") != tt.wantRange || len(stored.Variables) != len(tt.req.Variables) {
+				t.Errorf("run range = %q to %q, variables = %v; want overrides %+v", stored.RangeFrom, stored.RangeTo, stored.Variables, tt.req)
+			}
+		})
+	}
+}
+
+func TestScheduleForRun_Variables(t *testing.T) {
+	schedule := &model.Schedule{RangeFrom: "now-7d", RangeTo: "now", Variables: model.JSONMap{"region": "us", "env": "prod"}}
+	run := &model.Run{Variables: model.JSONMap{"region": "eu"}}
+
+	got := scheduleForRun(schedule, run)
+	if got.Variables["region"] != "eu" || got.Variables["env"] != "prod" || got.RangeFrom != "now-7d" {
+		t.Errorf("scheduleForRun() = range %s, variables %v; want the schedule's range with region overridden", got.RangeFrom, got.Variables)
+	}
+	if schedule.Variables["region"] != "us" {
+		t.Errorf("scheduleForRun() modified the schedule's variables: %v", schedule.Variables)
+	}
+}
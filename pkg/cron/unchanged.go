@@ -0,0 +1,49 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+	"github.com/yourusername/sheduled-reports-app/pkg/render"
+)
+
+// Modes for skipping reports that did not change since the last one sent
+const (
+	SkipUnchangedOff       = ""
+	SkipUnchangedIdentical = "identical" // Skip when the rendered dashboard is byte-for-byte identical
+	SkipUnchangedSimilar   = "similar"   // Skip when the screenshot looks the same (tolerates timestamps and similar small changes)
+)
+
+// similarImageDistance is the number of differing perceptual hash bits (out of 256) up to which two
+// screenshots are considered the same. Reports of several panels allow as many per panel.
+const similarImageDistance = 8
+
+// ValidateSkipUnchanged checks a schedule's skip_unchanged mode
+func ValidateSkipUnchanged(mode string) error {
+	switch mode {
+	case SkipUnchangedOff, SkipUnchangedIdentical, SkipUnchangedSimilar:
+		return nil
+	}
+	return fmt.Errorf("invalid skip_unchanged %q: must be %q or %q", mode, SkipUnchangedIdentical, SkipUnchangedSimilar)
+}
+
+// unchangedSince reports whether a run rendered the same report as a previous run and describes why.
+// The similar mode compares perceptual image hashes and falls back to checksums when either run has
+// no image hash (e.g. reports rendered by wkhtmltopdf).
+func unchangedSince(mode string, previous, run *model.Run) (bool, string) {
+	if mode == SkipUnchangedSimilar && previous.ImageHash != "" && run.ImageHash != "" {
+		distance, err := render.ImageHashDistance(previous.ImageHash, run.ImageHash)
+		if err == nil {
+			bits := len(run.ImageHash) * 4 // Hex digits
+			if distance <= similarImageDistance*bits/256 {
+				return true, fmt.Sprintf("report looks the same as run %d (%d of %d image hash bits differ)", previous.ID, distance, bits)
+			}
+			return false, ""
+		}
+	}
+
+	if previous.Checksum != "" && previous.Checksum == run.Checksum {
+		return true, fmt.Sprintf("report is identical to run %d", previous.ID)
+	}
+	return false, ""
+}
@@ -0,0 +1,108 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// ValidateWindow checks a schedule's validity window and occurrence limit
+func ValidateWindow(schedule *model.Schedule) error {
+	if schedule.MaxOccurrences < 0 {
+		return fmt.Errorf("max_occurrences must not be negative")
+	}
+	if schedule.StartDate != nil && schedule.EndDate != nil && !schedule.EndDate.After(*schedule.StartDate) {
+		return fmt.Errorf("end_date must be after start_date")
+	}
+	return nil
+}
+
+// limitOccurrences drops the occurrences (oldest first) that lie outside the schedule's validity
+// window or exceed its occurrence limit
+func limitOccurrences(schedule *model.Schedule, occurrences []time.Time) []time.Time {
+	limited := make([]time.Time, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if schedule.MaxOccurrences > 0 && schedule.OccurrenceCount+len(limited) >= schedule.MaxOccurrences {
+			break
+		}
+		if schedule.StartDate != nil && occurrence.Before(*schedule.StartDate) {
+			continue
+		}
+		if schedule.EndDate != nil && occurrence.After(*schedule.EndDate) {
+			continue
+		}
+		limited = append(limited, occurrence)
+	}
+	return limited
+}
+
+// nextWindowRun returns the first fire time of the schedule after the given instant that lies in its
+// validity window, given that queued more occurrences are about to count towards its limit. ok is
+// false when the schedule has no occurrences left.
+func nextWindowRun(schedule *model.Schedule, cal *model.Calendar, after time.Time, queued int) (next time.Time, ok bool, err error) {
+	if schedule.MaxOccurrences > 0 && schedule.OccurrenceCount+queued >= schedule.MaxOccurrences {
+		return time.Time{}, false, nil
+	}
+	if schedule.StartDate != nil && after.Before(*schedule.StartDate) {
+		// An occurrence exactly at the start date is the first one
+		after = schedule.StartDate.Add(-time.Nanosecond)
+	}
+
+	next, err = nextRunTime(schedule, cal, after)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if schedule.EndDate != nil && next.After(*schedule.EndDate) {
+		return time.Time{}, false, nil
+	}
+	return next, true, nil
+}
+
+// upcomingRuns lists up to count fire times of a schedule after the given instant, following its
+// validity window and occurrence limit, with the time range each report will cover and, for staggered
+// schedules, the start offset by the given stagger
+func upcomingRuns(schedule *model.Schedule, cal *model.Calendar, after time.Time, count int, offset time.Duration) ([]model.UpcomingRun, error) {
+	loc, err := scheduleLocation(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := make([]model.UpcomingRun, 0, count)
+	for len(upcoming) < count {
+		next, ok, err := nextWindowRun(schedule, cal, after, len(upcoming))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		from, to, err := resolveTimeRange(schedule.RangeFrom, schedule.RangeTo, next, loc)
+		if err != nil {
+			return nil, err
+		}
+		run := model.UpcomingRun{At: next, RangeFrom: from, RangeTo: to}
+		if offset > 0 {
+			start := next.Add(offset)
+			run.StartAt = &start
+		}
+		upcoming = append(upcoming, run)
+		after = next
+	}
+	return upcoming, nil
+}
+
+// expiryReason describes why a schedule without occurrences left ended
+func expiryReason(schedule *model.Schedule) string {
+	if schedule.MaxOccurrences > 0 && schedule.OccurrenceCount >= schedule.MaxOccurrences {
+		return fmt.Sprintf("it reached its limit of %d occurrence(s)", schedule.MaxOccurrences)
+	}
+	if schedule.EndDate != nil {
+		end := *schedule.EndDate
+		if loc, err := scheduleLocation(schedule); err == nil {
+			end = end.In(loc)
+		}
+		return fmt.Sprintf("it has no occurrences left before its end date %s", end.Format(time.RFC1123))
+	}
+	return "it has no occurrences left"
+}
@@ -0,0 +1,137 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestNextWindowRun(t *testing.T) {
+	start := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC) // Monday
+	end := time.Date(2025, 6, 30, 23, 59, 0, 0, time.UTC)
+	weekly := func(s model.Schedule) *model.Schedule {
+		s.IntervalType, s.TimeOfDay, s.DayOfWeek, s.Timezone = "weekly", "08:00", 1, "UTC"
+		return &s
+	}
+
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		after    time.Time
+		queued   int
+		want     time.Time
+		wantOK   bool
+	}{
+		{
+			name:     "no window",
+			schedule: weekly(model.Schedule{}),
+			after:    time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 5, 5, 8, 0, 0, 0, time.UTC),
+			wantOK:   true,
+		},
+		{
+			name:     "before start date waits for an occurrence at the start",
+			schedule: weekly(model.Schedule{StartDate: &start}),
+			after:    time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			want:     start,
+			wantOK:   true,
+		},
+		{
+			name:     "last occurrence before end date",
+			schedule: weekly(model.Schedule{EndDate: &end}),
+			after:    time.Date(2025, 6, 23, 8, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 6, 30, 8, 0, 0, 0, time.UTC),
+			wantOK:   true,
+		},
+		{
+			name:     "no occurrence before end date",
+			schedule: weekly(model.Schedule{EndDate: &end}),
+			after:    time.Date(2025, 6, 30, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "occurrences left",
+			schedule: weekly(model.Schedule{MaxOccurrences: 5, OccurrenceCount: 3}),
+			after:    time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC),
+			queued:   1,
+			want:     time.Date(2025, 6, 9, 8, 0, 0, 0, time.UTC),
+			wantOK:   true,
+		},
+		{
+			name:     "limit reached by the queued occurrences",
+			schedule: weekly(model.Schedule{MaxOccurrences: 5, OccurrenceCount: 3}),
+			after:    time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC),
+			queued:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := nextWindowRun(tt.schedule, nil, tt.after, tt.queued)
+			if err != nil {
+				t.Fatalf("nextWindowRun() error = %v", err)
+			}
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("nextWindowRun() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLimitOccurrences(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 8, 0, 0, 0, time.UTC) }
+	start, end := day(2), day(4)
+	due := []time.Time{day(1), day(2), day(3), day(4), day(5)}
+
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		want     []time.Time
+	}{
+		{name: "no window", schedule: &model.Schedule{}, want: due},
+		{name: "validity window", schedule: &model.Schedule{StartDate: &start, EndDate: &end}, want: due[1:4]},
+		{name: "remaining occurrences, oldest first", schedule: &model.Schedule{MaxOccurrences: 3, OccurrenceCount: 1}, want: due[:2]},
+		{name: "limit reached", schedule: &model.Schedule{MaxOccurrences: 3, OccurrenceCount: 3}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limitOccurrences(tt.schedule, due)
+			if len(got) != len(tt.want) {
+				t.Fatalf("limitOccurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("limitOccurrences()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUpcomingRuns(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	schedule := &model.Schedule{
+		IntervalType: "weekly", TimeOfDay: "08:00", DayOfWeek: 1, Timezone: "Europe/Berlin",
+		RangeFrom: "now-1w/w", RangeTo: "now-1w/w", MaxOccurrences: 4, OccurrenceCount: 1,
+	}
+
+	// Three occurrences are left; each covers the previous calendar week
+	got, err := upcomingRuns(schedule, nil, time.Date(2025, 3, 20, 12, 0, 0, 0, berlin), 5, 0)
+	if err != nil {
+		t.Fatalf("upcomingRuns() error = %v", err)
+	}
+	want := []model.UpcomingRun{
+		{At: time.Date(2025, 3, 24, 8, 0, 0, 0, berlin), RangeFrom: time.Date(2025, 3, 17, 0, 0, 0, 0, berlin), RangeTo: time.Date(2025, 3, 23, 23, 59, 59, 999000000, berlin)},
+		{At: time.Date(2025, 3, 31, 8, 0, 0, 0, berlin), RangeFrom: time.Date(2025, 3, 24, 0, 0, 0, 0, berlin), RangeTo: time.Date(2025, 3, 30, 23, 59, 59, 999000000, berlin)},
+		{At: time.Date(2025, 4, 7, 8, 0, 0, 0, berlin), RangeFrom: time.Date(2025, 3, 31, 0, 0, 0, 0, berlin), RangeTo: time.Date(2025, 4, 6, 23, 59, 59, 999000000, berlin)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("upcomingRuns() = %d runs, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].At.Equal(want[i].At) || !got[i].RangeFrom.Equal(want[i].RangeFrom) || !got[i].RangeTo.Equal(want[i].RangeTo) {
+			t.Errorf("upcomingRuns()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"regexp"
+	"strconv"
+)
+
+// ErrInvalidRecipient is returned when a recipient address cannot be parsed
+var ErrInvalidRecipient = errors.New("invalid recipient address")
+
+// PermanentError wraps a delivery failure that retrying will not fix, such as a recipient rejected
+// by the SMTP server or failed authentication
+type PermanentError struct {
+	Err error
+}
+
+// Error implements error
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks the error as not retryable
+func (e *PermanentError) Permanent() bool {
+	return true
+}
+
+// sendErrorCode finds the SMTP reply code in errors gomail flattens into a string
+// (e.g. "gomail: could not send email 1: 550 5.1.1 User unknown")
+var sendErrorCode = regexp.MustCompile(`could not send email \d+: (\d{3}) `)
+
+// classifySMTPError marks SMTP errors with a 5xx reply code as permanent. 4xx replies and network
+// errors are transient and left as they are.
+func classifySMTPError(err error) error {
+	code := 0
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		code = protoErr.Code
+	} else if match := sendErrorCode.FindStringSubmatch(err.Error()); match != nil {
+		code, _ = strconv.Atoi(match[1])
+	}
+
+	if code >= 500 && code < 600 {
+		return &PermanentError{Err: fmt.Errorf("SMTP server rejected the message: %w", err)}
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestClassifySMTPError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantPermanent bool
+	}{
+		{name: "rejected recipient", err: errors.New("gomail: could not send email 1: 550 5.1.1 User unknown"), wantPermanent: true},
+		{name: "mailbox busy", err: errors.New("gomail: could not send email 1: 450 4.2.1 Try again later"), wantPermanent: false},
+		{name: "authentication failed", err: fmt.Errorf("dial: %w", &textproto.Error{Code: 535, Msg: "Authentication failed"}), wantPermanent: true},
+		{name: "timeout", err: errors.New("dial tcp 10.0.0.1:587: i/o timeout"), wantPermanent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var permanent *PermanentError
+			if got := errors.As(classifySMTPError(tt.err), &permanent); got != tt.wantPermanent {
+				t.Errorf("classifySMTPError() permanent = %v, want %v", got, tt.wantPermanent)
+			}
+		})
+	}
+}
+
+func TestSendReport_InvalidRecipient(t *testing.T) {
+	mailer := NewMailer(model.SMTPConfig{Host: "127.0.0.1", Port: 1})
+	err := mailer.SendReport(context.Background(), model.Recipients{To: []string{"ops@example.com"}, CC: []string{"not an address"}}, "subject", "body", nil, "")
+
+	var permanent *PermanentError
+	if !errors.As(err, &permanent) || !errors.Is(err, ErrInvalidRecipient) {
+		t.Errorf("SendReport() error = %v, want permanent ErrInvalidRecipient", err)
+	}
+}
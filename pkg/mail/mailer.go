@@ -1,9 +1,13 @@
 package mail
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	netmail "net/mail"
 	"strings"
 
 	"github.com/yourusername/sheduled-reports-app/pkg/model"
@@ -22,8 +26,37 @@ func NewMailer(config model.SMTPConfig) *Mailer {
 	}
 }
 
-// SendReport sends a report via email
-func (m *Mailer) SendReport(recipients model.Recipients, subject, body string, attachment []byte, filename string) error {
+// ErrDeliveryUnknown is returned when the context ends while the SMTP server is still receiving the
+// message. The send goes on in the background, so recipients may still get the report.
+var ErrDeliveryUnknown = errors.New("delivery outcome unknown")
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename string
+	Data     []byte
+}
+
+// single returns the attachments of an email carrying at most one report
+func single(data []byte, filename string) []Attachment {
+	if len(data) == 0 {
+		return nil
+	}
+	return []Attachment{{Filename: filename, Data: data}}
+}
+
+// SendReport sends a report via email. It returns when the context ends, with ErrDeliveryUnknown if
+// the SMTP server was already receiving the message.
+func (m *Mailer) SendReport(ctx context.Context, recipients model.Recipients, subject, body string, attachment []byte, filename string) error {
+	result, err := m.startSend(ctx, recipients, subject, body, single(attachment, filename))
+	if err != nil {
+		return err
+	}
+	return awaitSend(ctx, result)
+}
+
+// startSend validates and builds the message and hands it to the SMTP server in the background.
+// The outcome of the send is delivered on the returned channel.
+func (m *Mailer) startSend(ctx context.Context, recipients model.Recipients, subject, body string, attachments []Attachment) (<-chan error, error) {
 	msg := gomail.NewMessage()
 
 	// Set sender
@@ -31,7 +64,14 @@ func (m *Mailer) SendReport(recipients model.Recipients, subject, body string, a
 
 	// Set recipients
 	if len(recipients.To) == 0 {
-		return fmt.Errorf("no recipients specified")
+		return nil, &PermanentError{Err: fmt.Errorf("no recipients specified")}
+	}
+	for _, list := range [][]string{recipients.To, recipients.CC, recipients.BCC} {
+		for _, address := range list {
+			if _, err := netmail.ParseAddress(address); err != nil {
+				return nil, &PermanentError{Err: fmt.Errorf("%w %q: %v", ErrInvalidRecipient, address, err)}
+			}
+		}
 	}
 	msg.SetHeader("To", recipients.To...)
 
@@ -47,14 +87,18 @@ func (m *Mailer) SendReport(recipients model.Recipients, subject, body string, a
 	msg.SetHeader("Subject", subject)
 	msg.SetBody("text/html", body)
 
-	// Attach report
-	if len(attachment) > 0 {
-		msg.Attach(filename, gomail.SetCopyFunc(func(w io.Writer) error {
-			_, err := w.Write(attachment)
+	// Attach reports
+	for _, attachment := range attachments {
+		msg.Attach(attachment.Filename, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(attachment.Data)
 			return err
 		}))
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Create dialer
 	dialer := gomail.NewDialer(m.config.Host, m.config.Port, m.config.Username, m.config.Password)
 
@@ -66,11 +110,83 @@ func (m *Mailer) SendReport(recipients model.Recipients, subject, body string, a
 	}
 
 	// Send email
-	if err := dialer.DialAndSend(msg); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+	result := make(chan error, 1)
+	go func() {
+		if err := dialer.DialAndSend(msg); err != nil {
+			result <- classifySMTPError(fmt.Errorf("failed to send email: %w", err))
+			return
+		}
+		result <- nil
+	}()
+	return result, nil
+}
+
+// awaitSend waits for the outcome of a send started by startSend
+func awaitSend(ctx context.Context, result <-chan error) error {
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrDeliveryUnknown, context.Cause(ctx))
 	}
+}
 
-	return nil
+// ErrAlreadyDelivered is returned when a report for the same delivery key was already sent
+var ErrAlreadyDelivered = errors.New("report was already delivered")
+
+// DeliveryGuard records deliveries so the same report is never emailed twice
+type DeliveryGuard interface {
+	// BeginDelivery reserves a delivery key, returning false if it was already reserved
+	BeginDelivery(key string) (bool, error)
+	// CompleteDelivery marks a reserved delivery as sent
+	CompleteDelivery(key string) error
+	// AbortDelivery releases a reservation after a failed send
+	AbortDelivery(key string) error
+}
+
+// SendReportOnce sends a report unless a report with the same delivery key was already sent.
+// It returns ErrAlreadyDelivered instead of sending a duplicate. When the context ends mid-send,
+// the reservation is settled once the SMTP server answers.
+func (m *Mailer) SendReportOnce(ctx context.Context, guard DeliveryGuard, key string, recipients model.Recipients, subject, body string, attachment []byte, filename string) error {
+	return m.SendBundleOnce(ctx, guard, key, recipients, subject, body, single(attachment, filename))
+}
+
+// SendBundleOnce sends several reports in one email, with the same guarantees as SendReportOnce
+func (m *Mailer) SendBundleOnce(ctx context.Context, guard DeliveryGuard, key string, recipients model.Recipients, subject, body string, attachments []Attachment) error {
+	reserved, err := guard.BeginDelivery(key)
+	if err != nil {
+		return fmt.Errorf("failed to reserve delivery: %w", err)
+	}
+	if !reserved {
+		return ErrAlreadyDelivered
+	}
+
+	result, err := m.startSend(ctx, recipients, subject, body, attachments)
+	if err != nil {
+		return settleDelivery(guard, key, err)
+	}
+
+	err = awaitSend(ctx, result)
+	if errors.Is(err, ErrDeliveryUnknown) {
+		go func() {
+			if err := settleDelivery(guard, key, <-result); err != nil {
+				log.Printf("Delivery %s finished after its run ended: %v", key, err)
+			}
+		}()
+		return err
+	}
+	return settleDelivery(guard, key, err)
+}
+
+// settleDelivery completes a reserved delivery after a successful send and releases it after a failed one
+func settleDelivery(guard DeliveryGuard, key string, sendErr error) error {
+	if sendErr == nil {
+		return guard.CompleteDelivery(key)
+	}
+	if abortErr := guard.AbortDelivery(key); abortErr != nil {
+		return fmt.Errorf("%w (and failed to release delivery: %v)", sendErr, abortErr)
+	}
+	return sendErr
 }
 
 // InterpolateTemplate replaces placeholders in the template
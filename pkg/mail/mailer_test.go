@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// fakeGuard records the calls made to a DeliveryGuard
+type fakeGuard struct {
+	mu      sync.Mutex
+	calls   []string
+	settled chan struct{}
+}
+
+func (g *fakeGuard) record(call string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls = append(g.calls, call)
+}
+
+func (g *fakeGuard) BeginDelivery(key string) (bool, error) {
+	g.record("begin")
+	return true, nil
+}
+
+func (g *fakeGuard) CompleteDelivery(key string) error {
+	g.record("complete")
+	close(g.settled)
+	return nil
+}
+
+func (g *fakeGuard) AbortDelivery(key string) error {
+	g.record("abort")
+	close(g.settled)
+	return nil
+}
+
+func TestSendReportOnce_ContextEndsMidSend(t *testing.T) {
+	// The SMTP server accepts the connection but never greets until released
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	release := make(chan struct{})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		<-release
+		conn.Close()
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewMailer(model.SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "reports@example.com"})
+	guard := &fakeGuard{settled: make(chan struct{})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = mailer.SendReportOnce(ctx, guard, "run:1", model.Recipients{To: []string{"ops@example.com"}}, "subject", "body", nil, "")
+	if !errors.Is(err, ErrDeliveryUnknown) {
+		t.Fatalf("SendReportOnce() error = %v, want ErrDeliveryUnknown", err)
+	}
+
+	// The reservation is kept while the server may still accept the message...
+	guard.mu.Lock()
+	calls := append([]string(nil), guard.calls...)
+	guard.mu.Unlock()
+	if len(calls) != 1 {
+		t.Fatalf("guard calls before the send finished = %v, want only begin", calls)
+	}
+
+	// ...and released once the send fails
+	close(release)
+	select {
+	case <-guard.settled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery was not settled after the send finished")
+	}
+	if got := guard.calls[len(guard.calls)-1]; got != "abort" {
+		t.Errorf("delivery settled with %q, want abort", got)
+	}
+}
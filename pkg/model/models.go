@@ -8,29 +8,56 @@ import (
 
 // Schedule represents a scheduled report
 type Schedule struct {
-	ID             int64             `json:"id"`
-	OrgID          int64             `json:"org_id"`
-	Name           string            `json:"name"`
-	DashboardUID   string            `json:"dashboard_uid"`
-	DashboardTitle string            `json:"dashboard_title,omitempty"`
-	PanelIDs       IntSlice          `json:"panel_ids,omitempty"`
-	RangeFrom      string            `json:"range_from"`
-	RangeTo        string            `json:"range_to"`
-	IntervalType   string            `json:"interval_type"`
-	CronExpr       string            `json:"cron_expr,omitempty"`
-	Timezone       string            `json:"timezone"`
-	Format         string            `json:"format"`
-	Variables      JSONMap           `json:"variables,omitempty"`
-	Recipients     Recipients        `json:"recipients"`
-	EmailSubject   string            `json:"email_subject"`
-	EmailBody      string            `json:"email_body"`
-	TemplateID     *int64            `json:"template_id,omitempty"`
-	Enabled        bool              `json:"enabled"`
-	LastRunAt      *time.Time        `json:"last_run_at,omitempty"`
-	NextRunAt      *time.Time        `json:"next_run_at,omitempty"`
-	OwnerUserID    int64             `json:"owner_user_id"`
-	CreatedAt      time.Time         `json:"created_at"`
-	UpdatedAt      time.Time         `json:"updated_at"`
+	ID                int64        `json:"id"`
+	OrgID             int64        `json:"org_id"`
+	Name              string       `json:"name"`
+	DashboardUID      string       `json:"dashboard_uid"`
+	DashboardTitle    string       `json:"dashboard_title,omitempty"`
+	PanelIDs          IntSlice     `json:"panel_ids,omitempty"`    // Render only these panels, each on its own, instead of the whole dashboard
+	PanelLayout       string       `json:"panel_layout,omitempty"` // PDF layout of the panels: "page" (default, one per page) or "grid"
+	RangeFrom         string       `json:"range_from"`
+	RangeTo           string       `json:"range_to"`
+	IntervalType      string       `json:"interval_type"`
+	CronExpr          string       `json:"cron_expr,omitempty"`
+	TimeOfDay         string       `json:"time_of_day,omitempty"` // "HH:MM" in Timezone for daily/weekly/monthly presets
+	DayOfWeek         int          `json:"day_of_week"`           // Weekly presets: 0 = Sunday ... 6 = Saturday
+	DayOfMonth        int          `json:"day_of_month"`          // Monthly presets: 1-31, clamped to the length of the month
+	BusinessDay       bool         `json:"business_day"`          // Monthly presets: DayOfMonth counts business days (Mon-Fri)
+	Timezone          string       `json:"timezone"`
+	MisfirePolicy     string       `json:"misfire_policy,omitempty"`      // Missed occurrences after downtime: "run_latest" (default), "skip" or "run_all"
+	CalendarID        *int64       `json:"calendar_id,omitempty"`         // Holiday calendar whose blocked days the schedule avoids
+	CalendarPolicy    string       `json:"calendar_policy,omitempty"`     // Blocked occurrences: "skip" (default), "postpone" or "pull_forward"
+	Condition         *Condition   `json:"condition,omitempty"`           // Only send the report when this query condition is met
+	SkipUnchanged     string       `json:"skip_unchanged,omitempty"`      // Skip reports unchanged since the last sent one: "" (off), "identical" or "similar"
+	RetryPolicy       *RetryPolicy `json:"retry_policy,omitempty"`        // Overrides the org's retry policy for this schedule
+	RunTimeoutSeconds int          `json:"run_timeout_seconds,omitempty"` // Deadline of a run including retries; 0 uses the org's default
+	StartDate         *time.Time   `json:"start_date,omitempty"`          // No occurrences before this instant
+	EndDate           *time.Time   `json:"end_date,omitempty"`            // No occurrences after this instant; the schedule is disabled once it passes
+	MaxOccurrences    int          `json:"max_occurrences,omitempty"`     // Disable the schedule after this many scheduled occurrences (0 = unlimited)
+	OccurrenceCount   int          `json:"occurrence_count"`              // Scheduled occurrences queued so far (maintained by the scheduler)
+	SpreadMinutes     int          `json:"spread_minutes,omitempty"`      // Staggers the start within this many minutes after the fire time; 0 uses the org's window
+	Format            string       `json:"format"`
+	Variables         JSONMap      `json:"variables,omitempty"`
+	Recipients        Recipients   `json:"recipients"`
+	EmailSubject      string       `json:"email_subject"`
+	EmailBody         string       `json:"email_body"`
+	TemplateID        *int64       `json:"template_id,omitempty"`
+	Enabled           bool         `json:"enabled"`
+	LastRunAt         *time.Time   `json:"last_run_at,omitempty"`
+	NextRunAt         *time.Time   `json:"next_run_at,omitempty"`
+	OwnerUserID       int64        `json:"owner_user_id"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// Condition gates a schedule on a datasource query evaluated over the report's time range
+// (e.g. only send when the error rate is above 2%)
+type Condition struct {
+	DatasourceUID string                 `json:"datasource_uid"`
+	Query         map[string]interface{} `json:"query"`     // Query model as sent to Grafana's /api/ds/query (expr, rawSql, ...)
+	Reducer       string                 `json:"reducer"`   // Reduces the returned series: "last" (default), "mean", "min", "max", "sum" or "count"
+	Operator      string                 `json:"operator"`  // ">", ">=", "<", "<=", "==" or "!="
+	Threshold     float64                `json:"threshold"` // The report is sent when <value> <operator> <threshold> holds
 }
 
 // Recipients holds email recipient information
@@ -42,20 +69,136 @@ type Recipients struct {
 
 // Run represents a report execution
 type Run struct {
-	ID            int64      `json:"id"`
-	ScheduleID    int64      `json:"schedule_id"`
-	OrgID         int64      `json:"org_id"`
-	StartedAt     time.Time  `json:"started_at"`
-	FinishedAt    *time.Time `json:"finished_at,omitempty"`
-	Status        string     `json:"status"`
-	ErrorText     string     `json:"error_text,omitempty"`
-	ArtifactPath  string     `json:"artifact_path,omitempty"`
-	RenderedPages int        `json:"rendered_pages"`
-	Bytes         int64      `json:"bytes"`
-	Checksum      string     `json:"checksum,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID             int64      `json:"id"`
+	ScheduleID     int64      `json:"schedule_id"`
+	OrgID          int64      `json:"org_id"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	Status         string     `json:"status"`
+	ErrorText      string     `json:"error_text,omitempty"`
+	ArtifactPath   string     `json:"artifact_path,omitempty"`
+	RenderedPages  int        `json:"rendered_pages"`
+	Bytes          int64      `json:"bytes"`
+	Checksum       string     `json:"checksum,omitempty"`        // sha256 of the rendered dashboard (before PDF conversion)
+	ImageHash      string     `json:"image_hash,omitempty"`      // Perceptual hash of the rendered screenshot (PNG backends only)
+	OccurrenceKey  string     `json:"occurrence_key,omitempty"`  // Schedule ID + planned fire time; unique across runs
+	ScheduledFor   *time.Time `json:"scheduled_for,omitempty"`   // Planned fire time of the occurrence this run fulfils
+	RangeFrom      string     `json:"range_from,omitempty"`      // Time range rendered when it differs from the schedule's,
+	RangeTo        string     `json:"range_to,omitempty"`        // e.g. the original window of a late occurrence (epoch ms)
+	ConditionValue *float64   `json:"condition_value,omitempty"` // Value the schedule's condition evaluated to
+	NotBefore      *time.Time `json:"not_before,omitempty"`      // Staggered runs start no earlier than this
+	QueuePosition  int        `json:"queue_position,omitempty"`  // 1-based position of a queued run in the queue (not stored)
+	BackfillID     *int64     `json:"backfill_id,omitempty"`     // Backfill that generated this run for a past occurrence
+	TriggerID      *int64     `json:"trigger_id,omitempty"`      // Trigger token that queued this run
+	TriggerSource  string     `json:"trigger_source,omitempty"`  // Caller of the trigger, e.g. the pipeline that fired it
+	Variables      JSONMap    `json:"variables,omitempty"`       // Dashboard variables overriding the schedule's
+	RenderWaitMS   int64      `json:"render_wait_ms,omitempty"`  // Time spent waiting for the dashboard to finish loading (Chromium)
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Backfill generates the reports of a schedule's past occurrences in a date range, one run per
+// occurrence. Its reports are not emailed one by one; they can be sent as a single bundle.
+type Backfill struct {
+	ID         int64      `json:"id"`
+	ScheduleID int64      `json:"schedule_id"`
+	OrgID      int64      `json:"org_id"`
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+	SendEmail  bool       `json:"send_email"`
+	Status     string     `json:"status"` // "running", "delivering", "completed" or "failed"
+	RunCount   int        `json:"run_count"`
+	ErrorText  string     `json:"error_text,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// TriggerToken lets an external system, e.g. an ETL pipeline, run a schedule on demand. Only a hash of
+// the token is stored; the token itself is returned once, when it is created.
+type TriggerToken struct {
+	ID         int64      `json:"id"`
+	ScheduleID int64      `json:"schedule_id"`
+	OrgID      int64      `json:"org_id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"` // Only set in the response creating the token
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// TriggerRequest is the optional payload of a trigger call overriding the schedule for one run
+type TriggerRequest struct {
+	RangeFrom string            `json:"range_from,omitempty"` // Grafana time expressions, e.g. "now-1d/d", or epoch ms
+	RangeTo   string            `json:"range_to,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Source    string            `json:"source,omitempty"` // Recorded on the run; defaults to the token name
+}
+
+// UpcomingRun is a future fire time of a schedule with the absolute time range its report will cover
+type UpcomingRun struct {
+	At        time.Time  `json:"at"`
+	StartAt   *time.Time `json:"start_at,omitempty"` // Staggered start, when the schedule is spread (saved schedules only)
+	RangeFrom time.Time  `json:"range_from"`
+	RangeTo   time.Time  `json:"range_to"`
+}
+
+// QueueItem is a persisted unit of scheduler work. Every queued run has exactly one item,
+// which is claimed by a worker and survives plugin restarts.
+type QueueItem struct {
+	ID         int64      `json:"id"`
+	RunID      int64      `json:"run_id"`
+	ScheduleID int64      `json:"schedule_id"`
+	OrgID      int64      `json:"org_id"`
+	Source     string     `json:"source"` // "schedule", "manual", "backfill" or "trigger"
+	Status     string     `json:"status"` // "queued", "claimed", "done", "failed" or "cancelled"
+	Attempts   int        `json:"attempts"`
+	EnqueuedAt time.Time  `json:"enqueued_at"`
+	ClaimedAt  *time.Time `json:"claimed_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Lease held by the plugin instance executing the item; an expired lease means the instance died
+	ClaimedBy      string     `json:"claimed_by,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+
+	// Set when the run was cancelled while another instance executes it; that instance stops it
+	// on its next lease renewal
+	CancelRequested bool `json:"cancel_requested"`
+
+	// Staggered items are not claimed before this time
+	NotBefore *time.Time `json:"not_before,omitempty"`
+
+	// Items with a higher priority are claimed first (see store.PriorityInteractive)
+	Priority int `json:"priority"`
+}
+
+// Calendar is an org-level set of holidays and blackout windows that schedules can reference
+type Calendar struct {
+	ID        int64           `json:"id"`
+	OrgID     int64           `json:"org_id"`
+	Name      string          `json:"name"`
+	Holidays  CalendarDays    `json:"holidays"`
+	Blackouts BlackoutWindows `json:"blackouts"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// CalendarDay is a single blocked date
+type CalendarDay struct {
+	Date string `json:"date"` // "YYYY-MM-DD", interpreted in the schedule's timezone
+	Name string `json:"name,omitempty"`
+}
+
+// BlackoutWindow is a range of blocked dates (inclusive), e.g. a quarter-close freeze
+type BlackoutWindow struct {
+	Start string `json:"start"` // "YYYY-MM-DD"
+	End   string `json:"end"`   // "YYYY-MM-DD"
+	Name  string `json:"name,omitempty"`
 }
 
+// CalendarDays is a custom type for storing calendar days in SQLite
+type CalendarDays []CalendarDay
+
+// BlackoutWindows is a custom type for storing blackout windows in SQLite
+type BlackoutWindows []BlackoutWindow
+
 // Template represents a report template
 type Template struct {
 	ID        int64          `json:"id"`
@@ -94,10 +237,20 @@ type Settings struct {
 	SMTPConfig     *SMTPConfig    `json:"smtp_config,omitempty"`
 	RendererConfig RendererConfig `json:"renderer_config"`
 	Limits         Limits         `json:"limits"`
+	RetryPolicy    *RetryPolicy   `json:"retry_policy,omitempty"` // Default retry policy of the org's schedules
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 }
 
+// RetryPolicy controls how often and how quickly failed runs are retried. Permanent failures
+// (e.g. a deleted dashboard or a rejected recipient) are never retried.
+type RetryPolicy struct {
+	MaxAttempts   int     `json:"max_attempts"`    // Total attempts including the first one (default 3)
+	BackoffBaseMS int     `json:"backoff_base_ms"` // Delay before the first retry, doubled for every further retry (default 1000)
+	BackoffMaxMS  int     `json:"backoff_max_ms"`  // Upper bound of the delay (default 30000)
+	Jitter        float64 `json:"jitter"`          // Randomizes each delay by up to this fraction, e.g. 0.2 for ±20%
+}
+
 // SMTPConfig holds SMTP configuration
 type SMTPConfig struct {
 	Host     string `json:"host"`
@@ -110,15 +263,21 @@ type SMTPConfig struct {
 
 // RendererConfig holds renderer configuration
 type RendererConfig struct {
-	Backend           string  `json:"backend"`             // Rendering backend: "chromium" or "wkhtmltopdf" (default: "chromium")
-	GrafanaURL        string  `json:"grafana_url"`         // Grafana base URL (e.g., https://127.0.0.1:3000/dna)
-	URL               string  `json:"url"`                 // DEPRECATED: renderer service URL (kept for backward compatibility)
+	Backend           string  `json:"backend"`        // Rendering backend: "chromium", "wkhtmltopdf" or "image-renderer" (default: "chromium")
+	GrafanaURL        string  `json:"grafana_url"`    // Grafana base URL (e.g., https://127.0.0.1:3000/dna)
+	URL               string  `json:"url"`            // Render endpoint of the image-renderer backend (e.g., http://renderer:8081/render)
+	RendererToken     string  `json:"renderer_token"` // Auth token of the image renderer service (Grafana's rendering.renderer_token)
 	TimeoutMS         int     `json:"timeout_ms"`
-	DelayMS           int     `json:"delay_ms"`
+	DelayMS           int     `json:"delay_ms"`        // JavaScript delay of wkhtmltopdf; Chromium waits until the dashboard is ready instead
+	NetworkIdleMS     int     `json:"network_idle_ms"` // Chromium: no request may be pending for this long before capturing (default: 500)
 	ViewportWidth     int     `json:"viewport_width"`
 	ViewportHeight    int     `json:"viewport_height"`
 	DeviceScaleFactor float64 `json:"device_scale_factor"` // Higher values (2-4) increase image quality
 	SkipTLSVerify     bool    `json:"skip_tls_verify"`     // Skip TLS certificate verification
+	PanelWidth        int     `json:"panel_width"`         // Size of individually rendered panels in pixels (default: 1000x500)
+	PanelHeight       int     `json:"panel_height"`
+	FullPage          bool    `json:"full_page"`   // Capture the whole dashboard height instead of the viewport (Chromium, image-renderer)
+	ExpandRows        bool    `json:"expand_rows"` // Full-page captures expand collapsed rows (Chromium)
 
 	// Chromium-specific configuration
 	ChromiumPath      string  `json:"chromium_path"`       // Path to Chrome/Chromium binary (optional, auto-detect if empty)
@@ -134,8 +293,11 @@ type RendererConfig struct {
 type Limits struct {
 	MaxRecipients        int `json:"max_recipients"`
 	MaxAttachmentSizeMB  int `json:"max_attachment_size_mb"`
-	MaxConcurrentRenders int `json:"max_concurrent_renders"`
+	MaxConcurrentRenders int `json:"max_concurrent_renders"` // Runs of the org executing at once across all instances (0 = no limit)
 	RetentionDays        int `json:"retention_days"`
+	RunTimeoutSeconds    int `json:"run_timeout_seconds"` // Default deadline of a run including retries (0 = 10 minutes)
+	SpreadMinutes        int `json:"spread_minutes"`      // Default window schedules are staggered in after their fire time (0 = off)
+	WorkerPoolSize       int `json:"worker_pool_size"`    // Runs each instance executes at once; main org only (0 = default)
 }
 
 // JSONMap is a custom type for storing JSON key-value pairs in SQLite
@@ -273,3 +435,85 @@ func (l *Limits) Scan(value interface{}) error {
 func (l Limits) Value() (driver.Value, error) {
 	return json.Marshal(l)
 }
+
+// Scan implements sql.Scanner for CalendarDays
+func (c *CalendarDays) Scan(value interface{}) error {
+	if value == nil {
+		*c = CalendarDays{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements driver.Valuer for CalendarDays
+func (c CalendarDays) Value() (driver.Value, error) {
+	if c == nil {
+		c = CalendarDays{}
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner for BlackoutWindows
+func (b *BlackoutWindows) Scan(value interface{}) error {
+	if value == nil {
+		*b = BlackoutWindows{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, b)
+}
+
+// Value implements driver.Valuer for BlackoutWindows
+func (b BlackoutWindows) Value() (driver.Value, error) {
+	if b == nil {
+		b = BlackoutWindows{}
+	}
+	return json.Marshal(b)
+}
+
+// Scan implements sql.Scanner for Condition
+func (c *Condition) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements driver.Valuer for Condition
+func (c *Condition) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner for RetryPolicy
+func (p *RetryPolicy) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// Value implements driver.Valuer for RetryPolicy
+func (p *RetryPolicy) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
@@ -8,6 +8,17 @@ import (
 	"github.com/jung-kurt/gofpdf"
 )
 
+// Layouts of the images in the PDF
+const (
+	LayoutFill = ""     // One image per page, stretched to the page margins (whole-dashboard screenshots)
+	LayoutPage = "page" // One image per page, scaled to fit without distortion
+	LayoutGrid = "grid" // GridColumns×GridRows images per page, each scaled to fit its cell
+
+	// LayoutSlices places one image per page like LayoutPage, but at the top of the page, so the
+	// slices of a capture cut by SplitPages read on from page to page
+	LayoutSlices = "slices"
+)
+
 // Options holds PDF generation options
 type Options struct {
 	Title       string
@@ -15,8 +26,17 @@ type Options struct {
 	PageSize    string // "A4", "Letter"
 	Header      string
 	Footer      string
+	Layout      string // LayoutFill (default), LayoutPage, LayoutGrid or LayoutSlices
+	GridColumns int    // Columns of the grid layout (default: 2)
+	GridRows    int    // Rows of the grid layout (default: 2)
 }
 
+// gridGap is the space between grid cells in mm
+const gridGap = 5.0
+
+// pageMargin is the margin around the images on each page in mm
+const pageMargin = 10.0
+
 // Generator handles PDF generation
 type Generator struct{}
 
@@ -31,16 +51,7 @@ func (g *Generator) Generate(images [][]byte, opts Options) ([]byte, error) {
 		return nil, fmt.Errorf("no images provided")
 	}
 
-	orientation := "L"
-	if opts.Orientation == "portrait" {
-		orientation = "P"
-	}
-
-	pageSize := opts.PageSize
-	if pageSize == "" {
-		pageSize = "A4"
-	}
-
+	orientation, pageSize := pageFormat(opts)
 	pdf := gofpdf.New(orientation, "mm", pageSize, "")
 
 	// Set document metadata
@@ -65,42 +76,52 @@ func (g *Generator) Generate(images [][]byte, opts Options) ([]byte, error) {
 		})
 	}
 
-	// Add each image as a page
-	for i, imgData := range images {
-		pdf.AddPage()
+	// Calculate image dimensions to fit page
+	areaWidth, areaHeight := pageArea(orientation, pageSize)
 
-		// Create a temporary image reader
-		reader := bytes.NewReader(imgData)
-		imgOpts := gofpdf.ImageOptions{
-			ImageType: "PNG",
-			ReadDpi:   true,
+	columns, rows := 1, 1
+	if opts.Layout == LayoutGrid {
+		columns, rows = opts.GridColumns, opts.GridRows
+		if columns <= 0 {
+			columns = 2
 		}
-
-		// Calculate image dimensions to fit page
-		var pageWidth, pageHeight float64
-		if orientation == "L" {
-			pageWidth, pageHeight = 297, 210 // A4 landscape
-		} else {
-			pageWidth, pageHeight = 210, 297 // A4 portrait
+		if rows <= 0 {
+			rows = 2
 		}
+	}
+	cellWidth := (areaWidth - float64(columns-1)*gridGap) / float64(columns)
+	cellHeight := (areaHeight - float64(rows-1)*gridGap) / float64(rows)
 
-		if pageSize == "Letter" {
-			if orientation == "L" {
-				pageWidth, pageHeight = 279.4, 215.9
-			} else {
-				pageWidth, pageHeight = 215.9, 279.4
-			}
-		}
+	imgOpts := gofpdf.ImageOptions{
+		ImageType: "PNG",
+		ReadDpi:   true,
+	}
 
-		// Add some margins
-		margin := 10.0
-		imgWidth := pageWidth - (2 * margin)
-		imgHeight := pageHeight - (2 * margin)
+	// Add the images, one per page or one per grid cell
+	for i, imgData := range images {
+		cell := i % (columns * rows)
+		if cell == 0 {
+			pdf.AddPage()
+		}
+		x := pageMargin + float64(cell%columns)*(cellWidth+gridGap)
+		y := pageMargin + float64(cell/columns)*(cellHeight+gridGap)
 
 		// Register and insert the image
 		imgName := fmt.Sprintf("image_%d", i)
-		pdf.RegisterImageOptionsReader(imgName, imgOpts, reader)
-		pdf.ImageOptions(imgName, margin, margin, imgWidth, imgHeight, false, imgOpts, 0, "")
+		info := pdf.RegisterImageOptionsReader(imgName, imgOpts, bytes.NewReader(imgData))
+		if info == nil {
+			return nil, fmt.Errorf("failed to read image %d: %w", i+1, pdf.Error())
+		}
+
+		width, height := cellWidth, cellHeight
+		if opts.Layout != LayoutFill {
+			width, height = fitImage(info.Width(), info.Height(), cellWidth, cellHeight)
+			x += (cellWidth - width) / 2
+			if opts.Layout != LayoutSlices {
+				y += (cellHeight - height) / 2
+			}
+		}
+		pdf.ImageOptions(imgName, x, y, width, height, false, imgOpts, 0, "")
 	}
 
 	// Output PDF to buffer
@@ -112,3 +133,47 @@ func (g *Generator) Generate(images [][]byte, opts Options) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// pageFormat returns the gofpdf orientation ("L" or "P") and page size of the options
+func pageFormat(opts Options) (string, string) {
+	orientation := "L"
+	if opts.Orientation == "portrait" {
+		orientation = "P"
+	}
+
+	pageSize := opts.PageSize
+	if pageSize == "" {
+		pageSize = "A4"
+	}
+	return orientation, pageSize
+}
+
+// pageArea returns the size in mm of the area the images are placed in for an orientation ("L" or "P")
+// and page size
+func pageArea(orientation, pageSize string) (float64, float64) {
+	var pageWidth, pageHeight float64
+	if orientation == "L" {
+		pageWidth, pageHeight = 297, 210 // A4 landscape
+	} else {
+		pageWidth, pageHeight = 210, 297 // A4 portrait
+	}
+
+	if pageSize == "Letter" {
+		if orientation == "L" {
+			pageWidth, pageHeight = 279.4, 215.9
+		} else {
+			pageWidth, pageHeight = 215.9, 279.4
+		}
+	}
+
+	return pageWidth - 2*pageMargin, pageHeight - 2*pageMargin
+}
+
+// fitImage scales an image to the largest size that fits a box while keeping its aspect ratio
+func fitImage(width, height, boxWidth, boxHeight float64) (float64, float64) {
+	if width <= 0 || height <= 0 {
+		return boxWidth, boxHeight
+	}
+	scale := min(boxWidth/width, boxHeight/height)
+	return width * scale, height * scale
+}
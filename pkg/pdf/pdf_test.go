@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPNG encodes a solid image of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerate_Layouts(t *testing.T) {
+	panel := testPNG(t, 100, 50)
+	images := [][]byte{panel, panel, panel, panel, panel}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantPages int
+	}{
+		{name: "fill", opts: Options{Layout: LayoutFill}, wantPages: 5},
+		{name: "one per page", opts: Options{Layout: LayoutPage}, wantPages: 5},
+		{name: "slices", opts: Options{Layout: LayoutSlices}, wantPages: 5},
+		{name: "2x2 grid", opts: Options{Layout: LayoutGrid}, wantPages: 2},
+		{name: "3x2 grid", opts: Options{Layout: LayoutGrid, GridColumns: 3}, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewGenerator().Generate(images, tt.opts)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if pages := bytes.Count(data, []byte("/Type /Page\n")); pages != tt.wantPages {
+				t.Errorf("Generate() produced %d pages, want %d", pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         float64
+		wantWidth, wantHeight float64
+	}{
+		{name: "wide image fills the width", width: 200, height: 50, wantWidth: 100, wantHeight: 25},
+		{name: "tall image fills the height", width: 50, height: 200, wantWidth: 12.5, wantHeight: 50},
+		{name: "unknown size fills the box", wantWidth: 100, wantHeight: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := fitImage(tt.width, tt.height, 100, 50)
+			if width != tt.wantWidth || height != tt.wantHeight {
+				t.Errorf("fitImage() = %vx%v, want %vx%v", width, height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
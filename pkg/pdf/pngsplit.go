@@ -0,0 +1,180 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// pngSignature starts every PNG file
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// PNG color types splitPNG handles
+const (
+	pngColorRGB  = 2
+	pngColorRGBA = 6
+)
+
+// errUnsupportedPNG is returned by splitPNG for PNGs it cannot split row by row
+var errUnsupportedPNG = errors.New("unsupported PNG encoding")
+
+// splitPNG cuts a PNG into horizontal strips ending at the given rows (the last one being the image
+// height). It works row by row on the compressed data, holding two rows of pixels at a time instead
+// of the whole image, which for a full-page capture can take hundreds of megabytes. Only the 8-bit,
+// non-interlaced RGB and RGBA images that browsers capture are supported; others return
+// errUnsupportedPNG.
+func splitPNG(data []byte, cuts []int) ([][]byte, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, fmt.Errorf("not a PNG image")
+	}
+
+	var header []byte
+	var idat []io.Reader
+	for rest := data[len(pngSignature):]; len(rest) >= 12; {
+		length := binary.BigEndian.Uint32(rest[:4])
+		if uint64(length)+12 > uint64(len(rest)) {
+			return nil, fmt.Errorf("truncated PNG chunk")
+		}
+		kind, payload := string(rest[4:8]), rest[8:8+length]
+		switch kind {
+		case "IHDR":
+			header = payload
+		case "IDAT":
+			idat = append(idat, bytes.NewReader(payload))
+		}
+		rest = rest[12+length:]
+	}
+	if len(header) != 13 || len(idat) == 0 {
+		return nil, fmt.Errorf("PNG image without header or data")
+	}
+
+	width := int(binary.BigEndian.Uint32(header[0:4]))
+	bitDepth, colorType, interlace := header[8], header[9], header[12]
+	if bitDepth != 8 || (colorType != pngColorRGB && colorType != pngColorRGBA) || interlace != 0 {
+		return nil, errUnsupportedPNG
+	}
+	bpp := 3
+	if colorType == pngColorRGBA {
+		bpp = 4
+	}
+	stride := width * bpp
+
+	pixels, err := zlib.NewReader(io.MultiReader(idat...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PNG data: %w", err)
+	}
+	defer pixels.Close()
+
+	prev := make([]byte, stride)
+	row := make([]byte, 1+stride) // Filter type followed by the filtered pixels
+	pages := make([][]byte, 0, len(cuts))
+	y := 0
+	for _, cut := range cuts {
+		var page bytes.Buffer
+		page.WriteString(pngSignature)
+		stripHeader := bytes.Clone(header)
+		binary.BigEndian.PutUint32(stripHeader[4:8], uint32(cut-y))
+		writePNGChunk(&page, "IHDR", stripHeader)
+
+		var compressed bytes.Buffer
+		out := zlib.NewWriter(&compressed)
+		for ; y < cut; y++ {
+			if _, err := io.ReadFull(pixels, row); err != nil {
+				return nil, fmt.Errorf("failed to read PNG row %d: %w", y, err)
+			}
+			if err := unfilterPNGRow(row[0], row[1:], prev, bpp); err != nil {
+				return nil, fmt.Errorf("PNG row %d: %w", y, err)
+			}
+			copy(prev, row[1:])
+
+			// Rows are written unfiltered; deflate still compresses dashboards' flat areas well
+			row[0] = 0
+			if _, err := out.Write(row); err != nil {
+				return nil, err
+			}
+		}
+		if err := out.Close(); err != nil {
+			return nil, err
+		}
+		writePNGChunk(&page, "IDAT", compressed.Bytes())
+		writePNGChunk(&page, "IEND", nil)
+		pages = append(pages, page.Bytes())
+	}
+	return pages, nil
+}
+
+// unfilterPNGRow reverses the PNG filter of a row in place, given the unfiltered previous row
+func unfilterPNGRow(filter byte, row, prev []byte, bpp int) error {
+	switch filter {
+	case 0: // None
+	case 1: // Sub
+		for i := bpp; i < len(row); i++ {
+			row[i] += row[i-bpp]
+		}
+	case 2: // Up
+		for i := range row {
+			row[i] += prev[i]
+		}
+	case 3: // Average
+		for i := range row {
+			var left int
+			if i >= bpp {
+				left = int(row[i-bpp])
+			}
+			row[i] += byte((left + int(prev[i])) / 2)
+		}
+	case 4: // Paeth
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prev[i-bpp]
+			}
+			row[i] += paeth(left, prev[i], upLeft)
+		}
+	default:
+		return fmt.Errorf("invalid filter type %d", filter)
+	}
+	return nil
+}
+
+// paeth returns the Paeth predictor of a pixel byte from its left, upper and upper-left neighbours
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// writePNGChunk appends a chunk with its length and checksum to a PNG file
+func writePNGChunk(w *bytes.Buffer, kind string, payload []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(payload)))
+	w.Write(length[:])
+
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(payload)
+	w.WriteString(kind)
+	w.Write(payload)
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	w.Write(sum[:])
+}
@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// minPageFill is the share of a page that has to be filled before a page may end at a clean break;
+// above it, the page is cut where it ends
+const minPageFill = 0.5
+
+// SplitPages cuts a capture taller than a page into page-sized PNG images for LayoutSlices, so a long
+// dashboard is not squeezed onto one page. breaks are the y positions in image pixels where the
+// capture can be cut without slicing through a panel, e.g. the tops of dashboard rows and panels.
+// Each page ends at the lowest break that fits; a page without one (e.g. below a panel taller than a
+// page) is cut where it ends. A capture that fits one page is returned as it is.
+func SplitPages(imageData []byte, breaks []int, opts Options) ([][]byte, error) {
+	config, err := png.DecodeConfig(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image size: %w", err)
+	}
+
+	areaWidth, areaHeight := pageArea(pageFormat(opts))
+	pageHeight := int(float64(config.Width) * areaHeight / areaWidth)
+	if config.Height <= pageHeight {
+		return [][]byte{imageData}, nil
+	}
+
+	cuts := pageCuts(config.Height, pageHeight, breaks)
+	pages, err := splitPNG(imageData, cuts)
+	if !errors.Is(err, errUnsupportedPNG) {
+		return pages, err
+	}
+	return splitDecoded(imageData, cuts)
+}
+
+// splitDecoded cuts any PNG into strips ending at cuts by decoding it as a whole; splitPNG handles
+// the images browsers capture without doing so
+func splitDecoded(imageData []byte, cuts []int) ([][]byte, error) {
+	img, err := png.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	cropper, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("unsupported image type %T", img)
+	}
+
+	bounds := img.Bounds()
+	pages := make([][]byte, 0, len(cuts))
+	top := 0
+	for _, cut := range cuts {
+		page := cropper.SubImage(image.Rect(bounds.Min.X, bounds.Min.Y+top, bounds.Max.X, bounds.Min.Y+cut))
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, page); err != nil {
+			return nil, fmt.Errorf("failed to encode page %d: %w", len(pages)+1, err)
+		}
+		pages = append(pages, buf.Bytes())
+		top = cut
+	}
+	return pages, nil
+}
+
+// pageCuts returns where the pages of an image of the given height end, the last one at height
+func pageCuts(height, pageHeight int, breaks []int) []int {
+	var cuts []int
+	top := 0
+	for height-top > pageHeight {
+		cut := top + pageHeight
+		best := 0
+		for _, b := range breaks {
+			if b > top+int(float64(pageHeight)*minPageFill) && b <= top+pageHeight && b > best {
+				best = b
+			}
+		}
+		if best > 0 {
+			cut = best
+		}
+		cuts = append(cuts, cut)
+		top = cut
+	}
+	return append(cuts, height)
+}
@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestPageCuts(t *testing.T) {
+	tests := []struct {
+		name   string
+		height int
+		breaks []int
+		want   []int
+	}{
+		{name: "fits one page", height: 80, breaks: []int{40}, want: []int{80}},
+		{name: "no breaks", height: 250, want: []int{100, 200, 250}},
+		{name: "lowest break that fits", height: 250, breaks: []int{30, 60, 90, 170}, want: []int{90, 170, 250}},
+		{name: "break too high on the page", height: 150, breaks: []int{40}, want: []int{100, 150}},
+		{name: "breaks out of order", height: 150, breaks: []int{120, 70, 95}, want: []int{95, 150}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageCuts(tt.height, 100, tt.breaks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageCuts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitPages(t *testing.T) {
+	// An A4 landscape page holds 277x190 mm, so a 277 pixel wide capture has 190 pixels per page
+	capture := testPNG(t, 277, 500)
+	pages, err := SplitPages(capture, []int{150, 300}, Options{PageSize: "A4"})
+	if err != nil {
+		t.Fatalf("SplitPages() error = %v", err)
+	}
+
+	var heights []int
+	for _, page := range pages {
+		config, err := png.DecodeConfig(bytes.NewReader(page))
+		if err != nil {
+			t.Fatalf("page is not a PNG: %v", err)
+		}
+		if config.Width != 277 {
+			t.Errorf("page width = %d, want 277", config.Width)
+		}
+		heights = append(heights, config.Height)
+	}
+	if want := []int{150, 150, 190, 10}; !reflect.DeepEqual(heights, want) {
+		t.Errorf("SplitPages() page heights = %v, want %v", heights, want)
+	}
+
+	short := testPNG(t, 277, 190)
+	if pages, err := SplitPages(short, nil, Options{}); err != nil || len(pages) != 1 || !bytes.Equal(pages[0], short) {
+		t.Errorf("SplitPages() of a capture fitting one page = %d pages, %v; want it unchanged", len(pages), err)
+	}
+}
+
+func TestSplitPNG(t *testing.T) {
+	// Varied pixels make the encoder use all of PNG's row filters
+	pattern := func(x, y int) color.NRGBA {
+		return color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x*y + y), A: 255}
+	}
+	tests := []struct {
+		name  string
+		alpha bool
+	}{
+		{name: "RGB"},
+		{name: "RGBA", alpha: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+			for x := 0; x < 40; x++ {
+				for y := 0; y < 30; y++ {
+					c := pattern(x, y)
+					if tt.alpha {
+						c.A = uint8(100 + y)
+					}
+					img.SetNRGBA(x, y, c)
+				}
+			}
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err != nil {
+				t.Fatalf("png.Encode() error = %v", err)
+			}
+
+			strips, err := splitPNG(buf.Bytes(), []int{7, 20, 30})
+			if err != nil {
+				t.Fatalf("splitPNG() error = %v", err)
+			}
+			if len(strips) != 3 {
+				t.Fatalf("splitPNG() = %d strips, want 3", len(strips))
+			}
+			top := 0
+			for i, strip := range strips {
+				decoded, err := png.Decode(bytes.NewReader(strip))
+				if err != nil {
+					t.Fatalf("strip %d is not a valid PNG: %v", i, err)
+				}
+				bounds := decoded.Bounds()
+				for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+					for x := bounds.Min.X; x < bounds.Max.X; x++ {
+						got := color.NRGBAModel.Convert(decoded.At(x, y))
+						if want := img.NRGBAAt(x, top+y); got != want {
+							t.Fatalf("strip %d pixel (%d,%d) = %v, want %v", i, x, y, got, want)
+						}
+					}
+				}
+				top += bounds.Dy()
+			}
+			if top != 30 {
+				t.Errorf("strips cover %d rows, want 30", top)
+			}
+		})
+	}
+
+	// Other encodings are left to SplitPages' fallback
+	paletted := image.NewPaletted(image.Rect(0, 0, 277, 400), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, paletted); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	if _, err := splitPNG(buf.Bytes(), []int{190, 400}); !errors.Is(err, errUnsupportedPNG) {
+		t.Errorf("splitPNG() of a paletted image error = %v, want errUnsupportedPNG", err)
+	}
+	if pages, err := SplitPages(buf.Bytes(), nil, Options{}); err != nil || len(pages) != 3 {
+		t.Errorf("SplitPages() of a paletted image = %d pages, %v; want 3", len(pages), err)
+	}
+}
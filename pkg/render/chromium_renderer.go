@@ -4,9 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net/url"
 	"os"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -20,7 +19,9 @@ import (
 type ChromiumRenderer struct {
 	grafanaURL string
 	config     model.RendererConfig
-	browser    *rod.Browser
+
+	mu      sync.Mutex // Guards browser: concurrent runs of the org share the renderer
+	browser *rod.Browser
 }
 
 // NewChromiumRenderer creates a new Chromium renderer instance
@@ -52,6 +53,9 @@ func NewChromiumRenderer(grafanaURL string, config model.RendererConfig) *Chromi
 
 // getBrowser initializes or returns existing browser instance
 func (r *ChromiumRenderer) getBrowser() (*rod.Browser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.browser != nil {
 		return r.browser, nil
 	}
@@ -97,6 +101,11 @@ func (r *ChromiumRenderer) getBrowser() (*rod.Browser, error) {
 	return browser, nil
 }
 
+// ServiceAccountToken returns the token the plugin uses to call Grafana APIs (see getServiceAccountToken)
+func ServiceAccountToken(ctx context.Context) (string, error) {
+	return getServiceAccountToken(ctx)
+}
+
 // getServiceAccountToken retrieves the service account token from context or environment
 func getServiceAccountToken(ctx context.Context) (string, error) {
 	// Try to get token from Grafana config (for managed service accounts in Grafana 10.3+)
@@ -126,13 +135,17 @@ func (r *ChromiumRenderer) RenderDashboard(ctx context.Context, schedule *model.
 	saToken, err := getServiceAccountToken(ctx)
 	if err != nil {
 		log.Printf("Warning: Failed to get service account token: %v", err)
-		return nil, fmt.Errorf("no service account token available: %w", err)
+		return nil, permanent(fmt.Errorf("no service account token available: %w", err))
 	}
 
 	// Build dashboard URL
 	dashboardURL, err := r.buildDashboardURL(schedule)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build dashboard URL: %w", err)
+		return nil, permanent(fmt.Errorf("failed to build dashboard URL: %w", err))
+	}
+
+	if err := checkDashboard(ctx, dashboardURL, schedule.DashboardUID, saToken, schedule.OrgID, r.config.SkipTLSVerify); err != nil {
+		return nil, err
 	}
 
 	log.Printf("DEBUG: Dashboard URL: %s", dashboardURL)
@@ -144,6 +157,51 @@ func (r *ChromiumRenderer) RenderDashboard(ctx context.Context, schedule *model.
 		return nil, fmt.Errorf("failed to initialize browser: %w", err)
 	}
 
+	return r.capture(ctx, browser, saToken, dashboardURL, r.config.ViewportWidth, r.config.ViewportHeight, r.config.FullPage)
+}
+
+// RenderPanels renders each panel of the schedule to its own PNG using Chromium
+func (r *ChromiumRenderer) RenderPanels(ctx context.Context, schedule *model.Schedule) ([][]byte, error) {
+	saToken, err := getServiceAccountToken(ctx)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("no service account token available: %w", err))
+	}
+
+	dashboardURL, err := r.buildDashboardURL(schedule)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("failed to build dashboard URL: %w", err))
+	}
+
+	if err := checkDashboard(ctx, dashboardURL, schedule.DashboardUID, saToken, schedule.OrgID, r.config.SkipTLSVerify); err != nil {
+		return nil, err
+	}
+
+	browser, err := r.getBrowser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize browser: %w", err)
+	}
+
+	width, height := panelSize(r.config)
+	images := make([][]byte, 0, len(schedule.PanelIDs))
+	for _, panelID := range schedule.PanelIDs {
+		panelURL, err := buildPanelURL(dashboardURL, panelID, width, height)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("failed to build panel URL: %w", err))
+		}
+		log.Printf("DEBUG: Panel URL: %s", panelURL)
+
+		imageData, err := r.capture(ctx, browser, saToken, panelURL, width, height, false)
+		if err != nil {
+			return nil, fmt.Errorf("panel %d: %w", panelID, err)
+		}
+		images = append(images, imageData)
+	}
+	return images, nil
+}
+
+// capture loads a Grafana page in a new tab of the given viewport size and screenshots it as PNG. With
+// fullPage, the viewport grows to the height of the dashboard.
+func (r *ChromiumRenderer) capture(ctx context.Context, browser *rod.Browser, saToken, pageURL string, width, height int, fullPage bool) ([]byte, error) {
 	// Create a new page
 	page, err := browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
@@ -153,8 +211,8 @@ func (r *ChromiumRenderer) RenderDashboard(ctx context.Context, schedule *model.
 
 	// Set viewport size
 	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-		Width:             r.config.ViewportWidth,
-		Height:            r.config.ViewportHeight,
+		Width:             width,
+		Height:            height,
 		DeviceScaleFactor: r.config.DeviceScaleFactor,
 		Mobile:            false,
 	}); err != nil {
@@ -169,11 +227,16 @@ func (r *ChromiumRenderer) RenderDashboard(ctx context.Context, schedule *model.
 	})
 	go router.Run()
 
-	// Set timeout
-	page = page.Timeout(time.Duration(r.config.TimeoutMS) * time.Millisecond)
+	// Set timeout; cancelling ctx (e.g. a cancelled run) aborts the page operations as well
+	page = page.Context(ctx).Timeout(time.Duration(r.config.TimeoutMS) * time.Millisecond)
+
+	// Follow the page's requests from the start, so queries sent while it loads are counted
+	trackCtx, stopTracking := context.WithCancel(page.GetContext())
+	defer stopTracking()
+	tracker := trackRequests(trackCtx, page)
 
 	// Navigate to dashboard
-	if err := page.Navigate(dashboardURL); err != nil {
+	if err := page.Navigate(pageURL); err != nil {
 		return nil, fmt.Errorf("failed to navigate to dashboard: %w", err)
 	}
 
@@ -182,10 +245,55 @@ func (r *ChromiumRenderer) RenderDashboard(ctx context.Context, schedule *model.
 		return nil, fmt.Errorf("failed to wait for page load: %w", err)
 	}
 
-	// Additional delay for queries to finish (if configured)
-	if r.config.DelayMS > 0 {
-		time.Sleep(time.Duration(r.config.DelayMS) * time.Millisecond)
-		log.Printf("DEBUG: Waited %dms for dashboard queries to complete", r.config.DelayMS)
+	// Wait until the panels finished loading their data, keeping part of the timeout for the
+	// screenshot: a dashboard that never settles (e.g. streaming panels) is captured as it is
+	idle := defaultNetworkIdle
+	if r.config.NetworkIdleMS > 0 {
+		idle = time.Duration(r.config.NetworkIdleMS) * time.Millisecond
+	}
+	readyCtx := page.GetContext()
+	if deadline, ok := readyCtx.Deadline(); ok {
+		var cancel context.CancelFunc
+		readyCtx, cancel = context.WithDeadline(readyCtx, deadline.Add(-screenshotReserve))
+		defer cancel()
+	}
+	waitLoaded := func() error {
+		started := time.Now()
+		ready := waitReady(page.Context(readyCtx), tracker, idle)
+		waited := time.Since(started)
+		addWait(ctx, waited)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if ready {
+			log.Printf("DEBUG: Dashboard ready after waiting %dms", waited.Milliseconds())
+		} else {
+			log.Printf("WARNING: Dashboard still loading after %dms, capturing it anyway", waited.Milliseconds())
+		}
+		return nil
+	}
+	if err := waitLoaded(); err != nil {
+		return nil, err
+	}
+
+	// Capture the whole dashboard instead of the viewport: the panels below the fold only load once
+	// they are scrolled into view
+	if fullPage {
+		fullHeight, err := r.expandToFullPage(page, width, height)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("DEBUG: Capturing full dashboard height of %dpx", fullHeight)
+		if err := waitLoaded(); err != nil {
+			return nil, err
+		}
+
+		// Tell the PDF generator where the capture can be split into pages
+		breaks, err := r.gridBreaks(page)
+		if err != nil {
+			log.Printf("WARNING: %v; pages will be split without regard to panels", err)
+		}
+		setBreaks(ctx, breaks)
 	}
 
 	// Take screenshot
@@ -208,9 +316,14 @@ func (r *ChromiumRenderer) RenderDashboard(ctx context.Context, schedule *model.
 
 // Close closes the browser instance
 func (r *ChromiumRenderer) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.browser != nil {
 		log.Printf("Closing Chromium browser")
-		return r.browser.Close()
+		browser := r.browser
+		r.browser = nil
+		return browser.Close()
 	}
 	return nil
 }
@@ -222,51 +335,5 @@ func (r *ChromiumRenderer) Name() string {
 
 // buildDashboardURL constructs the Grafana dashboard URL
 func (r *ChromiumRenderer) buildDashboardURL(schedule *model.Schedule) (string, error) {
-	// Use configured grafanaURL
-	baseURL := r.grafanaURL
-
-	u, err := url.Parse(baseURL)
-	if err != nil {
-		return "", err
-	}
-
-	// Only convert localhost to grafana hostname if explicitly configured to do so
-	// This is needed for Docker deployments where the plugin runs in a separate container
-	// For non-Docker deployments, use the actual configured hostname
-	// Note: This conversion should only happen if GRAFANA_HOSTNAME env var is set
-	if targetHost := os.Getenv("GRAFANA_HOSTNAME"); targetHost != "" {
-		if u.Host == "localhost:3000" || u.Host == "127.0.0.1:3000" || u.Host == "localhost" || u.Host == "127.0.0.1" {
-			// Parse target to preserve protocol
-			if u.Port() != "" {
-				u.Host = fmt.Sprintf("%s:%s", targetHost, u.Port())
-			} else {
-				u.Host = targetHost
-			}
-			log.Printf("DEBUG: Converted localhost to %s for Docker deployment", u.Host)
-		}
-	}
-
-	// Preserve any subpath from base URL (e.g., /dna from root_url)
-	basePath := u.Path
-	if basePath == "" || basePath == "/" {
-		basePath = ""
-	}
-
-	u.Path = fmt.Sprintf("%s/d/%s", basePath, schedule.DashboardUID)
-
-	q := u.Query()
-	q.Set("from", schedule.RangeFrom)
-	q.Set("to", schedule.RangeTo)
-	q.Set("kiosk", "tv") // Hide menu, header, and time picker
-	q.Set("orgId", strconv.FormatInt(schedule.OrgID, 10))
-	q.Set("tz", schedule.Timezone)
-
-	// Add dashboard variables
-	for k, v := range schedule.Variables {
-		q.Set("var-"+k, v)
-	}
-
-	u.RawQuery = q.Encode()
-
-	return u.String(), nil
+	return dashboardURL(r.grafanaURL, schedule)
 }
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDashboardNotFound is returned when the scheduled dashboard no longer exists
+	ErrDashboardNotFound = errors.New("dashboard not found")
+	// ErrDashboardAccessDenied is returned when the service account may not view the dashboard
+	ErrDashboardAccessDenied = errors.New("access to dashboard denied")
+)
+
+// PermanentError wraps a rendering failure that retrying will not fix, such as a deleted dashboard
+// or a misconfigured renderer
+type PermanentError struct {
+	Err error
+}
+
+// Error implements error
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks the error as not retryable
+func (e *PermanentError) Permanent() bool {
+	return true
+}
+
+// permanent wraps err in a PermanentError
+func permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// checkDashboard looks the dashboard up through Grafana's API before rendering, so a deleted
+// dashboard or missing permission fails with a permanent error instead of a screenshot of an error
+// page. Other failures (e.g. Grafana being unreachable) are returned as transient errors.
+func checkDashboard(ctx context.Context, dashboardURL, uid, token string, orgID int64, skipTLSVerify bool) error {
+	_, err := lookupDashboard(ctx, dashboardURL, uid, token, orgID, skipTLSVerify)
+	return err
+}
+
+// dashboardLayout is the part of a dashboard's JSON model that places its rows and panels. Panels of
+// collapsed rows are nested in their row, so the top-level panels are the ones on screen.
+type dashboardLayout struct {
+	Panels []struct {
+		GridPos struct {
+			Y int `json:"y"`
+			H int `json:"h"`
+		} `json:"gridPos"`
+	} `json:"panels"`
+}
+
+// lookupDashboard is checkDashboard returning the dashboard's layout
+func lookupDashboard(ctx context.Context, dashboardURL, uid, token string, orgID int64, skipTLSVerify bool) (*dashboardLayout, error) {
+	u, err := url.Parse(dashboardURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/d/"+uid) + "/api/dashboards/uid/" + url.PathEscape(uid)
+	u.RawQuery = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(orgID, 10))
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipTLSVerify},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up dashboard: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, permanent(fmt.Errorf("%w: %s", ErrDashboardNotFound, uid))
+	case http.StatusForbidden:
+		return nil, permanent(fmt.Errorf("%w: %s", ErrDashboardAccessDenied, uid))
+	default:
+		return nil, fmt.Errorf("failed to look up dashboard: HTTP %d", resp.StatusCode)
+	}
+
+	// The lookup is about the dashboard existing and being accessible: a model that cannot be read
+	// just leaves the layout empty
+	var body struct {
+		Dashboard dashboardLayout `json:"dashboard"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		log.Printf("WARNING: Failed to read layout of dashboard %s: %v", uid, err)
+	}
+	return &body.Dashboard, nil
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckDashboard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/grafana/api/dashboards/uid/ok":
+			w.Write([]byte(`{"dashboard": {}}`))
+		case "/grafana/api/dashboards/uid/private":
+			w.WriteHeader(http.StatusForbidden)
+		case "/grafana/api/dashboards/uid/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		uid           string
+		wantErr       error
+		wantPermanent bool
+	}{
+		{uid: "ok"},
+		{uid: "deleted", wantErr: ErrDashboardNotFound, wantPermanent: true},
+		{uid: "private", wantErr: ErrDashboardAccessDenied, wantPermanent: true},
+		{uid: "broken", wantPermanent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			dashboardURL := server.URL + "/grafana/d/" + tt.uid + "?orgId=1&kiosk=tv"
+			err := checkDashboard(context.Background(), dashboardURL, tt.uid, "token", 1, false)
+			if tt.uid == "ok" {
+				if err != nil {
+					t.Fatalf("checkDashboard() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("checkDashboard() succeeded, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkDashboard() error = %v, want %v", err, tt.wantErr)
+			}
+			var permanent *PermanentError
+			if got := errors.As(err, &permanent); got != tt.wantPermanent {
+				t.Errorf("checkDashboard() permanent = %v, want %v", got, tt.wantPermanent)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package render
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxFullPageHeight caps the height of full-page captures in image pixels (CSS pixels times the device
+// scale factor), beyond which Chromium does not capture reliably and splitting the capture into PDF
+// pages takes too much memory; taller dashboards are cut off at the bottom
+const maxFullPageHeight = 16384
+
+// fullPageJS scrolls through the dashboard so Grafana loads the panels below the fold and returns the
+// viewport height that shows the whole dashboard. Grafana scrolls the dashboard inside a container
+// rather than the document, so the tallest scrollable element is taken. Collapsed rows are expanded
+// first when requested.
+const fullPageJS = `async (expandRows) => {
+	const sleep = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+	if (expandRows) {
+		const collapsed = document.querySelectorAll([
+			'.dashboard-row--collapsed .dashboard-row__title',
+			'button[aria-expanded="false"][data-testid^="data-testid dashboard-row-title"]',
+		].join(','));
+		collapsed.forEach((title) => title.click());
+		if (collapsed.length > 0) {
+			await sleep(500);
+		}
+	}
+
+	let scroller = null;
+	for (const el of [document.scrollingElement, ...document.querySelectorAll('*')]) {
+		if (!el || el.scrollHeight <= el.clientHeight + 1) {
+			continue;
+		}
+		if (el !== document.scrollingElement && !/(auto|scroll)/.test(getComputedStyle(el).overflowY)) {
+			continue;
+		}
+		if (!scroller || el.scrollHeight > scroller.scrollHeight) {
+			scroller = el;
+		}
+	}
+	if (!scroller) {
+		return window.innerHeight;
+	}
+
+	for (let top = 0; top < scroller.scrollHeight; top += scroller.clientHeight) {
+		scroller.scrollTop = top;
+		await sleep(200);
+	}
+	scroller.scrollTop = 0;
+	return window.innerHeight + scroller.scrollHeight - scroller.clientHeight;
+}`
+
+// gridItemBoundsJS returns the top and bottom of every dashboard row and panel in CSS pixels
+const gridItemBoundsJS = `() => Array.from(document.querySelectorAll('.react-grid-item')).map((item) => {
+	const rect = item.getBoundingClientRect();
+	return [rect.top + window.scrollY, rect.bottom + window.scrollY];
+})`
+
+// gridBreaks reads where a full-page capture of the dashboard can be cut without slicing through a
+// row or panel, in image pixels
+func (r *ChromiumRenderer) gridBreaks(page *rod.Page) ([]int, error) {
+	result, err := page.Eval(gridItemBoundsJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dashboard layout: %w", err)
+	}
+	var bounds [][2]float64
+	if err := result.Value.Unmarshal(&bounds); err != nil {
+		return nil, fmt.Errorf("failed to read dashboard layout: %w", err)
+	}
+	return cleanBreaks(bounds, r.config.DeviceScaleFactor), nil
+}
+
+// cleanBreaks returns the tops of the rows and panels that no other row or panel overlaps, i.e. the
+// positions a page can end at above them, scaled to image pixels and in ascending order
+func cleanBreaks(bounds [][2]float64, scale float64) []int {
+	seen := make(map[int]bool)
+	breaks := make([]int, 0)
+	for _, candidate := range bounds {
+		top := candidate[0]
+		if top <= 0 {
+			continue
+		}
+		clean := true
+		for _, other := range bounds {
+			if other[0] < top && top < other[1] {
+				clean = false
+				break
+			}
+		}
+		y := int(top * scale)
+		if clean && !seen[y] {
+			seen[y] = true
+			breaks = append(breaks, y)
+		}
+	}
+	sort.Ints(breaks)
+	return breaks
+}
+
+// Geometry of Grafana's dashboard grid in CSS pixels
+const (
+	gridCellHeight  = 30 // Height of one grid unit (GRID_CELL_HEIGHT)
+	gridCellVMargin = 8  // Space between grid units (GRID_CELL_VMARGIN)
+)
+
+// gridPosBreaks derives the clean cut positions of a full-page capture from the dashboard's gridPos,
+// for backends that cannot read the page's DOM. The grid is assumed to sit in the middle of the
+// capture, with the same padding above and below it.
+func gridPosBreaks(layout *dashboardLayout, imageHeight int, scale float64) []int {
+	bounds := make([][2]float64, 0, len(layout.Panels))
+	gridHeight := 0.0
+	for _, panel := range layout.Panels {
+		top := float64(panel.GridPos.Y * (gridCellHeight + gridCellVMargin))
+		bottom := top + float64(panel.GridPos.H*(gridCellHeight+gridCellVMargin)-gridCellVMargin)
+		bounds = append(bounds, [2]float64{top, bottom})
+		gridHeight = max(gridHeight, bottom)
+	}
+
+	padding := max((float64(imageHeight)/scale-gridHeight)/2, 0)
+	for i := range bounds {
+		bounds[i][0] += padding
+		bounds[i][1] += padding
+	}
+	return cleanBreaks(bounds, scale)
+}
+
+// expandToFullPage scrolls through the dashboard to trigger lazy loading and grows the viewport until
+// the whole dashboard fits, so the screenshot covers it from top to bottom. Expanding rows and loading
+// panels can make the dashboard taller, so it measures again until the height stays the same.
+func (r *ChromiumRenderer) expandToFullPage(page *rod.Page, width, height int) (int, error) {
+	maxHeight := int(maxFullPageHeight / r.config.DeviceScaleFactor)
+	for attempt := 0; attempt < 3; attempt++ {
+		result, err := page.Eval(fullPageJS, r.config.ExpandRows)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scroll through dashboard: %w", err)
+		}
+
+		fullHeight := result.Value.Int()
+		if fullHeight > maxHeight {
+			log.Printf("WARNING: Dashboard is %dpx tall, capturing the first %dpx", fullHeight, maxHeight)
+			fullHeight = maxHeight
+		}
+		if fullHeight <= height {
+			return height, nil
+		}
+
+		height = fullHeight
+		if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             width,
+			Height:            height,
+			DeviceScaleFactor: r.config.DeviceScaleFactor,
+			Mobile:            false,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to resize viewport: %w", err)
+		}
+	}
+	return height, nil
+}
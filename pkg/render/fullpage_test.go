@@ -0,0 +1,48 @@
+package render
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCleanBreaks(t *testing.T) {
+	bounds := [][2]float64{
+		{0, 30},      // Row at the top of the page
+		{38, 338},    // Two panels side by side
+		{38, 338},    //
+		{346, 646},   // A panel next to a taller one...
+		{346, 1000},  //
+		{654, 954},   // ...so the panel below the first one starts inside the taller one
+		{1008, 1038}, // Next row
+	}
+
+	want := []int{76, 692, 2016}
+	if got := cleanBreaks(bounds, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("cleanBreaks() = %v, want %v", got, want)
+	}
+	if got := cleanBreaks(nil, 2); len(got) != 0 {
+		t.Errorf("cleanBreaks(nil) = %v, want none", got)
+	}
+}
+
+func TestGridPosBreaks(t *testing.T) {
+	var layout dashboardLayout
+	err := json.Unmarshal([]byte(`{"panels": [
+		{"gridPos": {"y": 0, "h": 1}},
+		{"gridPos": {"y": 1, "h": 8}},
+		{"gridPos": {"y": 1, "h": 16}},
+		{"gridPos": {"y": 9, "h": 8}},
+		{"gridPos": {"y": 17, "h": 1}}
+	]}`), &layout)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	// The grid is 676px tall; a 1416px capture at scale 2 leaves 16px of padding above and below it.
+	// The panel at y=9 starts next to the tall panel, so no page can end above it.
+	want := []int{32, 108, 1324}
+	if got := gridPosBreaks(&layout, 1416, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("gridPosBreaks() = %v, want %v", got, want)
+	}
+}
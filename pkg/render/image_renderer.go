@@ -0,0 +1,224 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// maxRenderedImageBytes caps the size of an image returned by the image renderer service
+const maxRenderedImageBytes = 100 << 20
+
+// ImageRenderer renders dashboards through a remote Grafana image renderer service
+// (grafana-image-renderer), so no browser has to be installed next to Grafana
+type ImageRenderer struct {
+	grafanaURL string
+	config     model.RendererConfig
+	client     *http.Client
+}
+
+// NewImageRenderer creates a renderer calling the image renderer service at config.URL
+func NewImageRenderer(grafanaURL string, config model.RendererConfig) *ImageRenderer {
+	// Set defaults
+	if config.ViewportWidth == 0 {
+		config.ViewportWidth = 1920
+	}
+	if config.ViewportHeight == 0 {
+		config.ViewportHeight = 1080
+	}
+	if config.TimeoutMS == 0 {
+		config.TimeoutMS = 30000
+	}
+	if config.DeviceScaleFactor == 0 {
+		config.DeviceScaleFactor = 2.0
+	}
+
+	return &ImageRenderer{
+		grafanaURL: grafanaURL,
+		config:     config,
+		client: &http.Client{
+			// The service enforces the render timeout itself; leave time to transfer the image
+			Timeout: time.Duration(config.TimeoutMS)*time.Millisecond + 15*time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipTLSVerify},
+			},
+		},
+	}
+}
+
+// RenderDashboard renders a dashboard to PNG through the image renderer service
+func (r *ImageRenderer) RenderDashboard(ctx context.Context, schedule *model.Schedule) ([]byte, error) {
+	saToken, err := getServiceAccountToken(ctx)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("no service account token available: %w", err))
+	}
+
+	dashboardURL, err := r.buildDashboardURL(schedule)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("failed to build dashboard URL: %w", err))
+	}
+
+	layout, err := lookupDashboard(ctx, dashboardURL, schedule.DashboardUID, saToken, schedule.OrgID, r.config.SkipTLSVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("DEBUG: Dashboard URL: %s", dashboardURL)
+	if !r.config.FullPage {
+		return r.render(ctx, saToken, dashboardURL, schedule.Timezone, r.config.ViewportWidth, r.config.ViewportHeight)
+	}
+
+	// A height of -1 makes the service scroll through the dashboard and capture all of it. The
+	// service cannot report where rows and panels ended up, so the PDF generator is told where to
+	// split the capture from the dashboard's grid.
+	imageData, err := r.render(ctx, saToken, dashboardURL, schedule.Timezone, r.config.ViewportWidth, -1)
+	if err != nil {
+		return nil, err
+	}
+	if size, err := png.DecodeConfig(bytes.NewReader(imageData)); err == nil {
+		setBreaks(ctx, gridPosBreaks(layout, size.Height, r.config.DeviceScaleFactor))
+	}
+	return imageData, nil
+}
+
+// RenderPanels renders each panel of the schedule to its own PNG through the image renderer service
+func (r *ImageRenderer) RenderPanels(ctx context.Context, schedule *model.Schedule) ([][]byte, error) {
+	saToken, err := getServiceAccountToken(ctx)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("no service account token available: %w", err))
+	}
+
+	dashboardURL, err := r.buildDashboardURL(schedule)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("failed to build dashboard URL: %w", err))
+	}
+
+	if err := checkDashboard(ctx, dashboardURL, schedule.DashboardUID, saToken, schedule.OrgID, r.config.SkipTLSVerify); err != nil {
+		return nil, err
+	}
+
+	width, height := panelSize(r.config)
+	images := make([][]byte, 0, len(schedule.PanelIDs))
+	for _, panelID := range schedule.PanelIDs {
+		panelURL, err := buildPanelURL(dashboardURL, panelID, width, height)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("failed to build panel URL: %w", err))
+		}
+
+		imageData, err := r.render(ctx, saToken, panelURL, schedule.Timezone, width, height)
+		if err != nil {
+			return nil, fmt.Errorf("panel %d: %w", panelID, err)
+		}
+		images = append(images, imageData)
+	}
+	return images, nil
+}
+
+// render asks the image renderer service to screenshot a Grafana page as PNG. The service loads the
+// page with the service account token as Authorization header; the renderer token authenticates the
+// plugin to the service.
+func (r *ImageRenderer) render(ctx context.Context, saToken, pageURL, timezone string, width, height int) ([]byte, error) {
+	if r.config.URL == "" {
+		return nil, permanent(fmt.Errorf("no image renderer URL configured"))
+	}
+	u, err := url.Parse(r.config.URL)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("invalid image renderer URL: %w", err))
+	}
+
+	headers, err := json.Marshal(map[string]string{"Authorization": "Bearer " + saToken})
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	q.Set("url", pageURL)
+	q.Set("width", strconv.Itoa(width))
+	q.Set("height", strconv.Itoa(height))
+	q.Set("deviceScaleFactor", strconv.FormatFloat(r.config.DeviceScaleFactor, 'f', -1, 64))
+	q.Set("timeout", strconv.Itoa((r.config.TimeoutMS+999)/1000)) // Seconds
+	q.Set("encoding", "png")
+	q.Set("timezone", timezone)
+	q.Set("headers", string(headers))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, permanent(err)
+	}
+	token := r.config.RendererToken
+	if token == "" {
+		token = "-" // Default token of the image renderer service
+	}
+	req.Header.Set("X-Auth-Token", token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call image renderer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRenderedImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image renderer response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, permanent(fmt.Errorf("image renderer rejected the renderer token (HTTP %d)", resp.StatusCode))
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, permanent(fmt.Errorf("image renderer URL %s not found (HTTP 404)", r.config.URL))
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, permanent(fmt.Errorf("image renderer rejected the request: %s", responseSnippet(body)))
+	default:
+		return nil, fmt.Errorf("image renderer failed with HTTP %d: %s", resp.StatusCode, responseSnippet(body))
+	}
+
+	if len(body) > maxRenderedImageBytes {
+		return nil, fmt.Errorf("image renderer returned more than %d MB", maxRenderedImageBytes>>20)
+	}
+	if len(body) < 8 || string(body[1:4]) != "PNG" {
+		return nil, fmt.Errorf("image renderer did not return a PNG image (got %d bytes)", len(body))
+	}
+
+	log.Printf("DEBUG: Image renderer returned %d bytes", len(body))
+	return body, nil
+}
+
+// responseSnippet shortens an error response body for error messages
+func responseSnippet(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return text
+}
+
+// Close releases idle connections to the image renderer service
+func (r *ImageRenderer) Close() error {
+	r.client.CloseIdleConnections()
+	return nil
+}
+
+// Name returns the backend name
+func (r *ImageRenderer) Name() string {
+	return string(BackendImageRenderer)
+}
+
+// buildDashboardURL constructs the Grafana dashboard URL
+func (r *ImageRenderer) buildDashboardURL(schedule *model.Schedule) (string, error) {
+	return dashboardURL(r.grafanaURL, schedule)
+}
@@ -0,0 +1,144 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// pngData is enough of a PNG for the renderer's magic byte check
+var pngData = []byte("\x89PNG\r\n\x1a\nimage")
+
+func TestImageRenderer(t *testing.T) {
+	t.Setenv("GF_PLUGIN_SA_TOKEN", "sa-token")
+
+	// One server stands in for both Grafana's dashboard API and the image renderer service
+	var rendered []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/dashboards/uid/abc":
+			w.Write([]byte(`{"dashboard": {}}`))
+		case "/render":
+			if r.Header.Get("X-Auth-Token") != "renderer-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			rendered = append(rendered, r.URL.Query())
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngData)
+		case "/render-broken":
+			http.Error(w, "browser crashed", http.StatusInternalServerError)
+		case "/render-html":
+			w.Write([]byte("<html>login</html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	schedule := &model.Schedule{
+		OrgID: 1, DashboardUID: "abc", RangeFrom: "now-7d", RangeTo: "now", Timezone: "Europe/Berlin",
+		Variables: model.JSONMap{"region": "eu"},
+	}
+	config := model.RendererConfig{
+		URL: server.URL + "/render", RendererToken: "renderer-secret", TimeoutMS: 20000,
+		ViewportWidth: 1600, ViewportHeight: 900, DeviceScaleFactor: 1.5,
+	}
+
+	t.Run("dashboard", func(t *testing.T) {
+		rendered = nil
+		image, err := NewImageRenderer(server.URL, config).RenderDashboard(context.Background(), schedule)
+		if err != nil {
+			t.Fatalf("RenderDashboard() error = %v", err)
+		}
+		if string(image) != string(pngData) || len(rendered) != 1 {
+			t.Fatalf("RenderDashboard() = %q after %d render calls, want the PNG after 1", image, len(rendered))
+		}
+
+		q := rendered[0]
+		want := map[string]string{
+			"width": "1600", "height": "900", "deviceScaleFactor": "1.5", "timeout": "20",
+			"encoding": "png", "timezone": "Europe/Berlin",
+		}
+		for key, value := range want {
+			if q.Get(key) != value {
+				t.Errorf("render %s = %q, want %q", key, q.Get(key), value)
+			}
+		}
+		if !strings.Contains(q.Get("url"), "/d/abc?") || !strings.Contains(q.Get("url"), "var-region=eu") {
+			t.Errorf("render url = %q, want the dashboard with its variables", q.Get("url"))
+		}
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(q.Get("headers")), &headers); err != nil || headers["Authorization"] != "Bearer sa-token" {
+			t.Errorf("render headers = %q, want the service account token", q.Get("headers"))
+		}
+	})
+
+	t.Run("full page", func(t *testing.T) {
+		rendered = nil
+		fullPage := config
+		fullPage.FullPage = true
+		if _, err := NewImageRenderer(server.URL, fullPage).RenderDashboard(context.Background(), schedule); err != nil {
+			t.Fatalf("RenderDashboard() error = %v", err)
+		}
+		if len(rendered) != 1 || rendered[0].Get("height") != "-1" {
+			t.Fatalf("render calls = %v, want one with height -1", rendered)
+		}
+	})
+
+	t.Run("panels", func(t *testing.T) {
+		rendered = nil
+		panels := *schedule
+		panels.PanelIDs = model.IntSlice{3, 8}
+		images, err := NewImageRenderer(server.URL, config).RenderPanels(context.Background(), &panels)
+		if err != nil {
+			t.Fatalf("RenderPanels() error = %v", err)
+		}
+		if len(images) != 2 || len(rendered) != 2 {
+			t.Fatalf("RenderPanels() = %d images after %d render calls, want 2", len(images), len(rendered))
+		}
+		for i, panelID := range []string{"3", "8"} {
+			if u := rendered[i].Get("url"); !strings.Contains(u, "/d-solo/abc?") || !strings.Contains(u, "panelId="+panelID) {
+				t.Errorf("render url = %q, want solo panel %s", u, panelID)
+			}
+			if rendered[i].Get("width") != "1000" || rendered[i].Get("height") != "500" {
+				t.Errorf("render size = %sx%s, want the default panel size", rendered[i].Get("width"), rendered[i].Get("height"))
+			}
+		}
+	})
+
+	errorTests := []struct {
+		name          string
+		url           string
+		token         string
+		wantPermanent bool
+	}{
+		{name: "wrong renderer token", url: server.URL + "/render", token: "wrong", wantPermanent: true},
+		{name: "wrong renderer URL", url: server.URL + "/missing", token: "renderer-secret", wantPermanent: true},
+		{name: "no renderer URL", wantPermanent: true},
+		{name: "renderer error", url: server.URL + "/render-broken", wantPermanent: false},
+		{name: "not an image", url: server.URL + "/render-html", wantPermanent: false},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := config
+			broken.URL, broken.RendererToken = tt.url, tt.token
+			_, err := NewImageRenderer(server.URL, broken).RenderDashboard(context.Background(), schedule)
+			if err == nil {
+				t.Fatal("RenderDashboard() succeeded, want error")
+			}
+			var permanent *PermanentError
+			if got := errors.As(err, &permanent); got != tt.wantPermanent {
+				t.Errorf("RenderDashboard() error = %v, permanent = %v, want %v", err, got, tt.wantPermanent)
+			}
+		})
+	}
+}
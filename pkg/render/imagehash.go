@@ -0,0 +1,88 @@
+package render
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png" // Register the PNG decoder for ImageHash
+	"math/bits"
+)
+
+// hashSize is the edge length of the gradient grid of ImageHash (hashSize² bits)
+const hashSize = 16
+
+// ImageHash computes a perceptual difference hash (dHash) of an encoded image. The image is reduced
+// to a (hashSize+1)×hashSize grayscale grid and each bit records whether brightness increases from
+// one cell to its right neighbour, so small changes such as an updated timestamp flip few bits while
+// a changed graph flips many.
+func ImageHash(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	grid := grayGrid(img, hashSize+1, hashSize)
+	hash := make([]byte, hashSize*hashSize/8)
+	for y := 0; y < hashSize; y++ {
+		for x := 0; x < hashSize; x++ {
+			if grid[y][x] < grid[y][x+1] {
+				bit := y*hashSize + x
+				hash[bit/8] |= 1 << (7 - bit%8)
+			}
+		}
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// ImageHashDistance returns the number of differing bits between two hashes from ImageHash
+func ImageHashDistance(a, b string) (int, error) {
+	ha, err := hex.DecodeString(a)
+	if err != nil {
+		return 0, fmt.Errorf("invalid image hash %q: %w", a, err)
+	}
+	hb, err := hex.DecodeString(b)
+	if err != nil {
+		return 0, fmt.Errorf("invalid image hash %q: %w", b, err)
+	}
+	if len(ha) != len(hb) {
+		return 0, fmt.Errorf("image hashes have different lengths")
+	}
+
+	distance := 0
+	for i := range ha {
+		distance += bits.OnesCount8(ha[i] ^ hb[i])
+	}
+	return distance, nil
+}
+
+// grayGrid averages the luminance of the image over a width×height grid of equally sized cells
+func grayGrid(img image.Image, width, height int) [][]float64 {
+	bounds := img.Bounds()
+	sums := make([][]float64, height)
+	counts := make([][]int, height)
+	for y := range sums {
+		sums[y] = make([]float64, width)
+		counts[y] = make([]int, width)
+	}
+
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		cy := (py - bounds.Min.Y) * height / bounds.Dy()
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			cx := (px - bounds.Min.X) * width / bounds.Dx()
+			gray := color.GrayModel.Convert(img.At(px, py)).(color.Gray)
+			sums[cy][cx] += float64(gray.Y)
+			counts[cy][cx]++
+		}
+	}
+
+	for y := range sums {
+		for x := range sums[y] {
+			if counts[y][x] > 0 {
+				sums[y][x] /= float64(counts[y][x])
+			}
+		}
+	}
+	return sums
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// dashboardPNG draws a fake dashboard: a gradient background with a bar chart whose bar heights are
+// given, and a small "timestamp" block whose shade can vary
+func dashboardPNG(t *testing.T, bars []int, timestampShade uint8) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 640, 360))
+	for y := 0; y < 360; y++ {
+		for x := 0; x < 640; x++ {
+			img.Set(x, y, color.Gray{Y: uint8(40 + x/8)})
+		}
+	}
+	for i, height := range bars {
+		for y := 360 - height; y < 360; y++ {
+			for x := i * 80; x < i*80+60; x++ {
+				img.Set(x, y, color.RGBA{R: 255, G: 200, A: 255})
+			}
+		}
+	}
+	for y := 4; y < 10; y++ {
+		for x := 600; x < 636; x++ {
+			img.Set(x, y, color.Gray{Y: timestampShade})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageHash(t *testing.T) {
+	base, err := ImageHash(dashboardPNG(t, []int{100, 200, 150, 300}, 0))
+	if err != nil {
+		t.Fatalf("ImageHash() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		png         []byte
+		maxDistance int
+		minDistance int
+	}{
+		{name: "same image", png: dashboardPNG(t, []int{100, 200, 150, 300}, 0), maxDistance: 0},
+		{name: "changed timestamp", png: dashboardPNG(t, []int{100, 200, 150, 300}, 255), maxDistance: 8},
+		{name: "changed data", png: dashboardPNG(t, []int{300, 50, 250, 20, 180, 90}, 0), minDistance: 20, maxDistance: 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := ImageHash(tt.png)
+			if err != nil {
+				t.Fatalf("ImageHash() error = %v", err)
+			}
+			distance, err := ImageHashDistance(base, hash)
+			if err != nil {
+				t.Fatalf("ImageHashDistance() error = %v", err)
+			}
+			if distance < tt.minDistance || distance > tt.maxDistance {
+				t.Errorf("distance = %d, want between %d and %d", distance, tt.minDistance, tt.maxDistance)
+			}
+		})
+	}
+}
+
+func TestImageHash_InvalidImage(t *testing.T) {
+	if _, err := ImageHash([]byte("%PDF-1.4")); err == nil {
+		t.Error("ImageHash() of a PDF succeeded, want error")
+	}
+}
@@ -11,6 +11,11 @@ type Backend interface {
 	// RenderDashboard renders a Grafana dashboard to an image
 	RenderDashboard(ctx context.Context, schedule *model.Schedule) ([]byte, error)
 
+	// RenderPanels renders each of the schedule's panels on its own, in order, at the configured
+	// panel size. Chromium returns one PNG per panel; wkhtmltopdf returns a single PDF with one page
+	// per panel.
+	RenderPanels(ctx context.Context, schedule *model.Schedule) ([][]byte, error)
+
 	// Close cleans up resources used by the backend
 	Close() error
 
@@ -22,8 +27,9 @@ type Backend interface {
 type BackendType string
 
 const (
-	BackendChromium    BackendType = "chromium"
-	BackendWkhtmltopdf BackendType = "wkhtmltopdf"
+	BackendChromium      BackendType = "chromium"
+	BackendWkhtmltopdf   BackendType = "wkhtmltopdf"
+	BackendImageRenderer BackendType = "image-renderer"
 )
 
 // NewBackend creates a new rendering backend based on the specified type
@@ -33,6 +39,8 @@ func NewBackend(backendType BackendType, grafanaURL string, config model.Rendere
 		return NewChromiumRenderer(grafanaURL, config), nil
 	case BackendWkhtmltopdf:
 		return NewWkhtmltopdfRenderer(grafanaURL, config), nil
+	case BackendImageRenderer:
+		return NewImageRenderer(grafanaURL, config), nil
 	default:
 		return NewChromiumRenderer(grafanaURL, config), nil // Default to Chromium
 	}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// Default size of individually rendered panels in pixels
+const (
+	defaultPanelWidth  = 1000
+	defaultPanelHeight = 500
+)
+
+// panelSize returns the size panels are rendered at
+func panelSize(config model.RendererConfig) (int, int) {
+	width, height := config.PanelWidth, config.PanelHeight
+	if width <= 0 {
+		width = defaultPanelWidth
+	}
+	if height <= 0 {
+		height = defaultPanelHeight
+	}
+	return width, height
+}
+
+// buildPanelURL turns a dashboard URL from buildDashboardURL into the URL of one of its panels
+// rendered on its own (/d-solo), keeping the time range, timezone, org and variables
+func buildPanelURL(dashboardURL string, panelID int64, width, height int) (string, error) {
+	u, err := url.Parse(dashboardURL)
+	if err != nil {
+		return "", err
+	}
+
+	i := strings.LastIndex(u.Path, "/d/")
+	if i < 0 {
+		return "", fmt.Errorf("not a dashboard URL: %s", dashboardURL)
+	}
+	u.Path = u.Path[:i] + "/d-solo/" + u.Path[i+len("/d/"):]
+
+	q := u.Query()
+	q.Del("kiosk") // Solo panels have no chrome to hide
+	q.Set("panelId", strconv.FormatInt(panelID, 10))
+	q.Set("width", strconv.Itoa(width))
+	q.Set("height", strconv.Itoa(height))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
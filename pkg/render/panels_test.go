@@ -0,0 +1,61 @@
+package render
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestBuildPanelURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		dashboardURL string
+		wantPath     string
+		wantErr      bool
+	}{
+		{
+			name:         "root path",
+			dashboardURL: "http://grafana:3000/d/abc?from=now-7d&kiosk=tv&orgId=1&to=now&tz=UTC&var-region=eu",
+			wantPath:     "/d-solo/abc",
+		},
+		{
+			name:         "subpath",
+			dashboardURL: "https://example.com/grafana/d/abc?kiosk=tv&orgId=2",
+			wantPath:     "/grafana/d-solo/abc",
+		},
+		{
+			name:         "not a dashboard",
+			dashboardURL: "http://grafana:3000/explore",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildPanelURL(tt.dashboardURL, 4, 800, 400)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildPanelURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatalf("buildPanelURL() = %q is not a URL: %v", got, err)
+			}
+			if u.Path != tt.wantPath {
+				t.Errorf("buildPanelURL() path = %q, want %q", u.Path, tt.wantPath)
+			}
+			want, _ := url.Parse(tt.dashboardURL)
+			q, wantQ := u.Query(), want.Query()
+			if q.Get("panelId") != "4" || q.Get("width") != "800" || q.Get("height") != "400" || q.Has("kiosk") {
+				t.Errorf("buildPanelURL() query = %v, want panelId 4 at 800x400 without kiosk", q)
+			}
+			for _, key := range []string{"from", "to", "orgId", "tz", "var-region"} {
+				if q.Get(key) != wantQ.Get(key) {
+					t.Errorf("buildPanelURL() %s = %q, want %q", key, q.Get(key), wantQ.Get(key))
+				}
+			}
+		})
+	}
+}
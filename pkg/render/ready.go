@@ -0,0 +1,156 @@
+package render
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	// defaultNetworkIdle is how long no request may be pending before a page counts as loaded
+	defaultNetworkIdle = 500 * time.Millisecond
+
+	// readyPollInterval is how often the page is checked for loading indicators
+	readyPollInterval = 100 * time.Millisecond
+
+	// screenshotReserve is the part of the render timeout kept for the screenshot when the page
+	// never becomes ready
+	screenshotReserve = 5 * time.Second
+)
+
+// loadingIndicatorsJS reports whether Grafana still shows its boot screen or a panel loading indicator
+// (the selectors cover the loading bars and spinners of Grafana 8 to 11)
+const loadingIndicatorsJS = `() => document.readyState !== 'complete' || document.querySelector([
+	'.preloader',
+	'.panel-loading',
+	'[aria-label="Panel loading bar"]',
+	'[data-testid="data-testid Panel loading bar"]',
+	'[aria-label="Loading indicator"]',
+	'[data-testid="Spinner"]',
+].join(',')) !== null`
+
+// Stats collects measurements of the renders made with a context, so the caller can record them
+// without the Backend interface returning them
+type Stats struct {
+	mu     sync.Mutex
+	wait   time.Duration
+	breaks []int
+}
+
+type statsKey struct{}
+
+// WithStats returns a context whose renders add their measurements to the returned Stats
+func WithStats(ctx context.Context) (context.Context, *Stats) {
+	stats := &Stats{}
+	return context.WithValue(ctx, statsKey{}, stats), stats
+}
+
+// Wait returns the total time the renders waited for pages to finish loading
+func (s *Stats) Wait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.wait
+}
+
+// Breaks returns the y positions in image pixels where a full-page dashboard capture can be cut
+// between rows and panels, in ascending order. It is empty when the backend does not report them.
+func (s *Stats) Breaks() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.breaks
+}
+
+// setBreaks records the clean cut positions of a capture in the stats of ctx, if it has any
+func setBreaks(ctx context.Context, breaks []int) {
+	if stats, ok := ctx.Value(statsKey{}).(*Stats); ok {
+		stats.mu.Lock()
+		stats.breaks = breaks
+		stats.mu.Unlock()
+	}
+}
+
+// addWait adds a page's loading wait to the stats of ctx, if it has any
+func addWait(ctx context.Context, d time.Duration) {
+	if stats, ok := ctx.Value(statsKey{}).(*Stats); ok {
+		stats.mu.Lock()
+		stats.wait += d
+		stats.mu.Unlock()
+	}
+}
+
+// requestTracker follows the network requests of a page to tell when it has gone quiet. Streams,
+// media, images and fonts are ignored: they may stay open or never matter for the data shown.
+type requestTracker struct {
+	mu           sync.Mutex
+	pending      map[proto.NetworkRequestID]struct{}
+	lastActivity time.Time
+}
+
+// trackRequests starts following the requests of page until ctx ends
+func trackRequests(ctx context.Context, page *rod.Page) *requestTracker {
+	tracker := &requestTracker{
+		pending:      make(map[proto.NetworkRequestID]struct{}),
+		lastActivity: time.Now(),
+	}
+	wait := page.Context(ctx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		switch e.Type {
+		case proto.NetworkResourceTypeWebSocket, proto.NetworkResourceTypeEventSource,
+			proto.NetworkResourceTypeMedia, proto.NetworkResourceTypeImage, proto.NetworkResourceTypeFont:
+			return
+		}
+		tracker.mu.Lock()
+		tracker.pending[e.RequestID] = struct{}{}
+		tracker.lastActivity = time.Now()
+		tracker.mu.Unlock()
+	}, func(e *proto.NetworkLoadingFinished) {
+		tracker.done(e.RequestID)
+	}, func(e *proto.NetworkLoadingFailed) {
+		tracker.done(e.RequestID)
+	})
+	go wait()
+	return tracker
+}
+
+// done marks a request as finished
+func (t *requestTracker) done(id proto.NetworkRequestID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.pending[id]; ok {
+		delete(t.pending, id)
+		t.lastActivity = time.Now()
+	}
+}
+
+// idleFor returns how long no request has been pending
+func (t *requestTracker) idleFor() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) > 0 {
+		return 0
+	}
+	return time.Since(t.lastActivity)
+}
+
+// waitReady waits until the page shows no loading indicator and the network has been idle for idle,
+// or until page's context ends. It reports whether the page became ready.
+func waitReady(page *rod.Page, tracker *requestTracker, idle time.Duration) bool {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if tracker.idleFor() >= idle {
+			loading, err := page.Eval(loadingIndicatorsJS)
+			if err == nil && !loading.Value.Bool() {
+				return true
+			}
+		}
+		select {
+		case <-page.GetContext().Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestStats(t *testing.T) {
+	// Renders without stats in their context record nothing
+	addWait(context.Background(), time.Second)
+
+	ctx, stats := WithStats(context.Background())
+	addWait(ctx, 300*time.Millisecond)
+	addWait(ctx, 200*time.Millisecond)
+	if got := stats.Wait(); got != 500*time.Millisecond {
+		t.Errorf("Wait() = %v, want 500ms", got)
+	}
+}
+
+func TestRequestTracker_IdleFor(t *testing.T) {
+	tracker := &requestTracker{
+		pending:      make(map[proto.NetworkRequestID]struct{}),
+		lastActivity: time.Now().Add(-time.Second),
+	}
+	if got := tracker.idleFor(); got < time.Second {
+		t.Errorf("idleFor() = %v without requests, want at least 1s", got)
+	}
+
+	tracker.pending["query"] = struct{}{}
+	if got := tracker.idleFor(); got != 0 {
+		t.Errorf("idleFor() = %v with a pending request, want 0", got)
+	}
+
+	tracker.done("unknown")
+	if got := tracker.idleFor(); got != 0 {
+		t.Errorf("idleFor() = %v after an untracked request finished, want 0", got)
+	}
+
+	tracker.done("query")
+	if got := tracker.idleFor(); got >= time.Second {
+		t.Errorf("idleFor() = %v right after the last request finished, want under 1s", got)
+	}
+}
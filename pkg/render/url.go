@@ -0,0 +1,59 @@
+package render
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// dashboardURL constructs the URL of a schedule's dashboard in kiosk mode, shared by all backends
+func dashboardURL(grafanaURL string, schedule *model.Schedule) (string, error) {
+	u, err := url.Parse(grafanaURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Only convert localhost to grafana hostname if explicitly configured to do so
+	// This is needed for Docker deployments where the plugin runs in a separate container
+	// For non-Docker deployments, use the actual configured hostname
+	// Note: This conversion should only happen if GRAFANA_HOSTNAME env var is set
+	if targetHost := os.Getenv("GRAFANA_HOSTNAME"); targetHost != "" {
+		if u.Host == "localhost:3000" || u.Host == "127.0.0.1:3000" || u.Host == "localhost" || u.Host == "127.0.0.1" {
+			// Parse target to preserve protocol
+			if u.Port() != "" {
+				u.Host = fmt.Sprintf("%s:%s", targetHost, u.Port())
+			} else {
+				u.Host = targetHost
+			}
+			log.Printf("DEBUG: Converted localhost to %s for Docker deployment", u.Host)
+		}
+	}
+
+	// Preserve any subpath from base URL (e.g., /dna from root_url)
+	basePath := u.Path
+	if basePath == "" || basePath == "/" {
+		basePath = ""
+	}
+
+	u.Path = fmt.Sprintf("%s/d/%s", basePath, schedule.DashboardUID)
+
+	q := u.Query()
+	q.Set("from", schedule.RangeFrom)
+	q.Set("to", schedule.RangeTo)
+	q.Set("kiosk", "tv") // Hide menu, header, and time picker
+	q.Set("orgId", strconv.FormatInt(schedule.OrgID, 10))
+	q.Set("tz", schedule.Timezone)
+
+	// Add dashboard variables
+	for k, v := range schedule.Variables {
+		q.Set("var-"+k, v)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
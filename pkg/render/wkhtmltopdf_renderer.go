@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net/url"
 	"os"
-	"strconv"
 
 	wkhtmltopdf "github.com/SebastiaanKlippert/go-wkhtmltopdf"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -70,18 +68,61 @@ func (r *WkhtmltopdfRenderer) RenderDashboard(ctx context.Context, schedule *mod
 	saToken, err := r.getServiceAccountToken(ctx)
 	if err != nil {
 		log.Printf("Warning: Failed to get service account token: %v", err)
-		return nil, fmt.Errorf("no service account token available: %w", err)
+		return nil, permanent(fmt.Errorf("no service account token available: %w", err))
 	}
 
 	// Build dashboard URL
 	dashboardURL, err := r.buildDashboardURL(schedule)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build dashboard URL: %w", err)
+		return nil, permanent(fmt.Errorf("failed to build dashboard URL: %w", err))
+	}
+
+	if err := checkDashboard(ctx, dashboardURL, schedule.DashboardUID, saToken, schedule.OrgID, r.config.SkipTLSVerify); err != nil {
+		return nil, err
 	}
 
 	log.Printf("DEBUG: Dashboard URL: %s", dashboardURL)
 	log.Printf("DEBUG: Using service account token (length: %d)", len(saToken))
 
+	return r.generate(ctx, saToken, []string{dashboardURL})
+}
+
+// RenderPanels renders the panels of the schedule into a single PDF with one page per panel using
+// wkhtmltopdf. It cannot lay panels out in a grid.
+func (r *WkhtmltopdfRenderer) RenderPanels(ctx context.Context, schedule *model.Schedule) ([][]byte, error) {
+	saToken, err := r.getServiceAccountToken(ctx)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("no service account token available: %w", err))
+	}
+
+	dashboardURL, err := r.buildDashboardURL(schedule)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("failed to build dashboard URL: %w", err))
+	}
+
+	if err := checkDashboard(ctx, dashboardURL, schedule.DashboardUID, saToken, schedule.OrgID, r.config.SkipTLSVerify); err != nil {
+		return nil, err
+	}
+
+	width, height := panelSize(r.config)
+	panelURLs := make([]string, 0, len(schedule.PanelIDs))
+	for _, panelID := range schedule.PanelIDs {
+		panelURL, err := buildPanelURL(dashboardURL, panelID, width, height)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("failed to build panel URL: %w", err))
+		}
+		panelURLs = append(panelURLs, panelURL)
+	}
+
+	pdfBytes, err := r.generate(ctx, saToken, panelURLs)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{pdfBytes}, nil
+}
+
+// generate renders the given Grafana pages into one PDF, one page (or more, for long pages) each
+func (r *WkhtmltopdfRenderer) generate(ctx context.Context, saToken string, pageURLs []string) ([]byte, error) {
 	// Set binary path if configured
 	if r.config.WkhtmltopdfPath != "" {
 		wkhtmltopdf.SetPath(r.config.WkhtmltopdfPath)
@@ -90,7 +131,7 @@ func (r *WkhtmltopdfRenderer) RenderDashboard(ctx context.Context, schedule *mod
 	// Create new PDF generator
 	pdfg, err := wkhtmltopdf.NewPDFGenerator()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create PDF generator: %w", err)
+		return nil, permanent(fmt.Errorf("failed to create PDF generator: %w", err))
 	}
 
 	// Set global options
@@ -103,39 +144,43 @@ func (r *WkhtmltopdfRenderer) RenderDashboard(ctx context.Context, schedule *mod
 	pdfg.MarginLeft.Set(10)
 	pdfg.MarginRight.Set(10)
 
-	// Create page from URL
-	page := wkhtmltopdf.NewPage(dashboardURL)
+	if r.config.DelayMS > 0 {
+		log.Printf("DEBUG: Waiting %dms for dashboard queries to complete", r.config.DelayMS)
+	}
+	for _, pageURL := range pageURLs {
+		// Create page from URL
+		page := wkhtmltopdf.NewPage(pageURL)
 
-	// Set page-specific options
-	// Note: JavaScript is enabled by default in wkhtmltopdf
-	page.NoStopSlowScripts.Set(true)
-	page.LoadErrorHandling.Set("ignore")
-	page.LoadMediaErrorHandling.Set("ignore")
+		// Set page-specific options
+		// Note: JavaScript is enabled by default in wkhtmltopdf
+		page.NoStopSlowScripts.Set(true)
+		page.LoadErrorHandling.Set("ignore")
+		page.LoadMediaErrorHandling.Set("ignore")
 
-	// Set zoom based on device scale factor
-	if r.config.DeviceScaleFactor > 0 {
-		page.Zoom.Set(r.config.DeviceScaleFactor)
-	}
+		// Set zoom based on device scale factor
+		if r.config.DeviceScaleFactor > 0 {
+			page.Zoom.Set(r.config.DeviceScaleFactor)
+		}
 
-	// Add custom header with auth token (CustomHeader is a mapOption)
-	page.CustomHeader.Set("Authorization", "Bearer "+saToken)
+		// Add custom header with auth token (CustomHeader is a mapOption)
+		page.CustomHeader.Set("Authorization", "Bearer "+saToken)
 
-	// JavaScript delay to let queries finish
-	if r.config.DelayMS > 0 {
-		page.JavascriptDelay.Set(uint(r.config.DelayMS))
-		log.Printf("DEBUG: Waiting %dms for dashboard queries to complete", r.config.DelayMS)
-	} else {
-		page.JavascriptDelay.Set(2000) // Default 2 second delay
-	}
+		// JavaScript delay to let queries finish
+		if r.config.DelayMS > 0 {
+			page.JavascriptDelay.Set(uint(r.config.DelayMS))
+		} else {
+			page.JavascriptDelay.Set(2000) // Default 2 second delay
+		}
 
-	// Add page to document
-	pdfg.AddPage(page)
+		// Add page to document
+		pdfg.AddPage(page)
+	}
 
 	// Note: Timeout and SSL verification are handled at command level in wkhtmltopdf
 	// The library doesn't expose these as options in the current API
 
 	// Generate PDF
-	err = pdfg.Create()
+	err = pdfg.CreateContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate PDF: %w", err)
 	}
@@ -165,51 +210,5 @@ func (r *WkhtmltopdfRenderer) Name() string {
 
 // buildDashboardURL constructs the Grafana dashboard URL
 func (r *WkhtmltopdfRenderer) buildDashboardURL(schedule *model.Schedule) (string, error) {
-	// Use configured grafanaURL
-	baseURL := r.grafanaURL
-
-	u, err := url.Parse(baseURL)
-	if err != nil {
-		return "", err
-	}
-
-	// Only convert localhost to grafana hostname if explicitly configured to do so
-	// This is needed for Docker deployments where the plugin runs in a separate container
-	// For non-Docker deployments, use the actual configured hostname
-	// Note: This conversion should only happen if GRAFANA_HOSTNAME env var is set
-	if targetHost := os.Getenv("GRAFANA_HOSTNAME"); targetHost != "" {
-		if u.Host == "localhost:3000" || u.Host == "127.0.0.1:3000" || u.Host == "localhost" || u.Host == "127.0.0.1" {
-			// Parse target to preserve protocol
-			if u.Port() != "" {
-				u.Host = fmt.Sprintf("%s:%s", targetHost, u.Port())
-			} else {
-				u.Host = targetHost
-			}
-			log.Printf("DEBUG: Converted localhost to %s for Docker deployment", u.Host)
-		}
-	}
-
-	// Preserve any subpath from base URL (e.g., /dna from root_url)
-	basePath := u.Path
-	if basePath == "" || basePath == "/" {
-		basePath = ""
-	}
-
-	u.Path = fmt.Sprintf("%s/d/%s", basePath, schedule.DashboardUID)
-
-	q := u.Query()
-	q.Set("from", schedule.RangeFrom)
-	q.Set("to", schedule.RangeTo)
-	q.Set("kiosk", "tv") // Hide menu, header, and time picker
-	q.Set("orgId", strconv.FormatInt(schedule.OrgID, 10))
-	q.Set("tz", schedule.Timezone)
-
-	// Add dashboard variables
-	for k, v := range schedule.Variables {
-		q.Set("var-"+k, v)
-	}
-
-	u.RawQuery = q.Encode()
-
-	return u.String(), nil
+	return dashboardURL(r.grafanaURL, schedule)
 }
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// backfillColumns is the column list shared by all backfill queries (order matches scanBackfill)
+const backfillColumns = `id, schedule_id, org_id, range_from, range_to, send_email, status, run_count, error_text,
+	created_at, finished_at`
+
+// scanBackfill scans a row selected with backfillColumns
+func scanBackfill(row rowScanner) (*model.Backfill, error) {
+	backfill := &model.Backfill{}
+	err := row.Scan(
+		&backfill.ID, &backfill.ScheduleID, &backfill.OrgID, &backfill.From, &backfill.To, &backfill.SendEmail,
+		&backfill.Status, &backfill.RunCount, &backfill.ErrorText, &backfill.CreatedAt, &backfill.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return backfill, nil
+}
+
+// CreateBackfill creates a backfill and queues its runs in one transaction
+func (s *Store) CreateBackfill(backfill *model.Backfill, runs []*model.Run) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	backfill.Status = "running"
+	backfill.RunCount = len(runs)
+	backfill.CreatedAt = time.Now()
+	result, err := tx.Exec(`
+		INSERT INTO backfills (schedule_id, org_id, range_from, range_to, send_email, status, run_count, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		backfill.ScheduleID, backfill.OrgID, backfill.From.UTC(), backfill.To.UTC(), backfill.SendEmail,
+		backfill.Status, backfill.RunCount, backfill.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	backfill.ID, err = result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		run.BackfillID = &backfill.ID
+		if _, err := enqueueRun(tx, run, "backfill"); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetBackfill retrieves a backfill by ID
+func (s *Store) GetBackfill(orgID, id int64) (*model.Backfill, error) {
+	backfill, err := scanBackfill(s.db.QueryRow(`
+		SELECT `+backfillColumns+`
+		FROM backfills WHERE id = ? AND org_id = ?`,
+		id, orgID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("backfill not found")
+	}
+	return backfill, err
+}
+
+// ListBackfills retrieves the backfills of a schedule, newest first
+func (s *Store) ListBackfills(orgID, scheduleID int64) ([]*model.Backfill, error) {
+	return s.queryBackfills(`
+		SELECT `+backfillColumns+`
+		FROM backfills WHERE schedule_id = ? AND org_id = ? ORDER BY created_at DESC, id DESC LIMIT 50`,
+		scheduleID, orgID,
+	)
+}
+
+// ListRunningBackfills retrieves the backfills whose runs have not all finished yet
+func (s *Store) ListRunningBackfills() ([]*model.Backfill, error) {
+	return s.queryBackfills(`
+		SELECT ` + backfillColumns + `
+		FROM backfills WHERE status = 'running'`,
+	)
+}
+
+// queryBackfills runs a query selecting backfillColumns
+func (s *Store) queryBackfills(query string, args ...interface{}) ([]*model.Backfill, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backfills := make([]*model.Backfill, 0)
+	for rows.Next() {
+		backfill, err := scanBackfill(rows)
+		if err != nil {
+			return nil, err
+		}
+		backfills = append(backfills, backfill)
+	}
+	return backfills, rows.Err()
+}
+
+// ListBackfillRuns retrieves the runs of a backfill, oldest occurrence first
+func (s *Store) ListBackfillRuns(backfillID int64) ([]*model.Run, error) {
+	rows, err := s.db.Query(`
+		SELECT `+runColumns+`
+		FROM runs WHERE backfill_id = ? ORDER BY scheduled_for ASC, id ASC`,
+		backfillID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]*model.Run, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// FinishBackfillRuns moves a running backfill whose runs have all finished to the given status
+// ("delivering" or "completed"). It returns false while runs are still queued or running, and when
+// another worker already finished the backfill; only the caller that gets true may deliver it.
+func (s *Store) FinishBackfillRuns(id int64, status string) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE backfills SET status = ?, finished_at = ?
+		WHERE id = ? AND status = 'running'
+		  AND NOT EXISTS (
+			SELECT 1 FROM runs WHERE backfill_id = ? AND status IN ('queued', 'running', 'interrupted')
+		  )`,
+		status, time.Now(), id, id,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UpdateBackfillResult records the final status of a backfill after its bundle was delivered
+func (s *Store) UpdateBackfillResult(id int64, status, errorText string) error {
+	_, err := s.db.Exec(
+		"UPDATE backfills SET status = ?, error_text = ?, finished_at = ? WHERE id = ?",
+		status, errorText, time.Now(), id,
+	)
+	return err
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// calendarColumns is the column list shared by all calendar queries (order matches scanCalendar)
+const calendarColumns = `id, org_id, name, holidays, blackouts, created_at, updated_at`
+
+// scanCalendar scans a row selected with calendarColumns
+func scanCalendar(row rowScanner) (*model.Calendar, error) {
+	calendar := &model.Calendar{}
+	err := row.Scan(
+		&calendar.ID, &calendar.OrgID, &calendar.Name, &calendar.Holidays, &calendar.Blackouts,
+		&calendar.CreatedAt, &calendar.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+// CreateCalendar creates a new calendar
+func (s *Store) CreateCalendar(calendar *model.Calendar) error {
+	now := time.Now()
+	calendar.CreatedAt = now
+	calendar.UpdatedAt = now
+
+	result, err := s.db.Exec(`
+		INSERT INTO calendars (org_id, name, holidays, blackouts, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		calendar.OrgID, calendar.Name, calendar.Holidays, calendar.Blackouts, now, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	calendar.ID = id
+
+	return nil
+}
+
+// GetCalendar retrieves a calendar by ID
+func (s *Store) GetCalendar(orgID, id int64) (*model.Calendar, error) {
+	calendar, err := scanCalendar(s.db.QueryRow(`
+		SELECT `+calendarColumns+`
+		FROM calendars WHERE id = ? AND org_id = ?`,
+		id, orgID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("calendar not found")
+	}
+	return calendar, err
+}
+
+// ListCalendars retrieves all calendars for an organization
+func (s *Store) ListCalendars(orgID int64) ([]*model.Calendar, error) {
+	rows, err := s.db.Query(`
+		SELECT `+calendarColumns+`
+		FROM calendars WHERE org_id = ? ORDER BY name ASC`,
+		orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calendars := make([]*model.Calendar, 0)
+	for rows.Next() {
+		calendar, err := scanCalendar(rows)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, rows.Err()
+}
+
+// UpdateCalendar updates an existing calendar
+func (s *Store) UpdateCalendar(calendar *model.Calendar) error {
+	calendar.UpdatedAt = time.Now()
+
+	result, err := s.db.Exec(`
+		UPDATE calendars SET name = ?, holidays = ?, blackouts = ?, updated_at = ?
+		WHERE id = ? AND org_id = ?`,
+		calendar.Name, calendar.Holidays, calendar.Blackouts, calendar.UpdatedAt, calendar.ID, calendar.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("calendar not found")
+	}
+	return nil
+}
+
+// DeleteCalendar deletes a calendar and detaches it from the schedules that reference it
+func (s *Store) DeleteCalendar(orgID, id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE schedules SET calendar_id = NULL WHERE calendar_id = ? AND org_id = ?", id, orgID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM calendars WHERE id = ? AND org_id = ?", id, orgID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListSchedulesByCalendar retrieves the schedules that reference a calendar
+func (s *Store) ListSchedulesByCalendar(orgID, calendarID int64) ([]*model.Schedule, error) {
+	rows, err := s.db.Query(`
+		SELECT `+scheduleColumns+`
+		FROM schedules WHERE org_id = ? AND calendar_id = ?`,
+		orgID, calendarID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]*model.Schedule, 0)
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
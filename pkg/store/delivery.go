@@ -0,0 +1,74 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Delivery states reported by DeliveryState
+const (
+	DeliveryNone    = ""        // Not reserved: the report can be sent
+	DeliverySending = "sending" // Reserved by a worker sending it right now
+	DeliverySent    = "sent"    // Sent
+	DeliveryUnknown = "unknown" // Reserved but never completed: a send was interrupted, e.g. by a crash
+)
+
+// BeginDelivery reserves the delivery of a report identified by key. It returns false when the
+// key was already reserved, i.e. the report was delivered or is being delivered by another worker.
+func (s *Store) BeginDelivery(key string) (bool, error) {
+	result, err := s.db.Exec(`
+		INSERT INTO deliveries (delivery_key, status, created_at) VALUES (?, 'sending', ?)
+		ON CONFLICT (delivery_key) DO NOTHING`,
+		key, time.Now().UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CompleteDelivery marks a reserved delivery as sent
+func (s *Store) CompleteDelivery(key string) error {
+	_, err := s.db.Exec(
+		"UPDATE deliveries SET status = 'sent', delivered_at = ? WHERE delivery_key = ?",
+		time.Now().UTC(), key,
+	)
+	return err
+}
+
+// AbortDelivery releases a reservation after a failed send so the delivery can be retried
+func (s *Store) AbortDelivery(key string) error {
+	_, err := s.db.Exec("DELETE FROM deliveries WHERE delivery_key = ? AND status = 'sending'", key)
+	return err
+}
+
+// DeliveryState reports the state of the delivery of a report identified by key. A reservation
+// older than staleAfter (the longest a run may take) was left behind by a send that never finished,
+// so whether the report went out is unknown. Such reports are not sent again: reports are sent at
+// most once.
+func (s *Store) DeliveryState(key string, staleAfter time.Duration) (string, error) {
+	var status string
+	var createdAt time.Time
+	err := s.db.QueryRow("SELECT status, created_at FROM deliveries WHERE delivery_key = ?", key).Scan(&status, &createdAt)
+	if err == sql.ErrNoRows {
+		return DeliveryNone, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if status == DeliverySending && time.Since(createdAt) > staleAfter {
+		return DeliveryUnknown, nil
+	}
+	return status, nil
+}
+
+// PruneDeliveries deletes the delivery records reserved before the given time. By then their runs
+// have long finished and will not be retried, so the records no longer prevent duplicate sends.
+func (s *Store) PruneDeliveries(before time.Time) (int64, error) {
+	result, err := s.db.Exec("DELETE FROM deliveries WHERE created_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
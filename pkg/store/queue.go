@@ -0,0 +1,377 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// queueItemColumns is the column list shared by all queue queries (order matches scanQueueItem)
+const queueItemColumns = `id, run_id, schedule_id, org_id, source, status, attempts, enqueued_at, claimed_at, finished_at,
+	claimed_by, lease_expires_at, cancel_requested, not_before, priority`
+
+// scanQueueItem scans a row selected with queueItemColumns
+func scanQueueItem(row rowScanner) (*model.QueueItem, error) {
+	item := &model.QueueItem{}
+	err := row.Scan(
+		&item.ID, &item.RunID, &item.ScheduleID, &item.OrgID, &item.Source, &item.Status,
+		&item.Attempts, &item.EnqueuedAt, &item.ClaimedAt, &item.FinishedAt,
+		&item.ClaimedBy, &item.LeaseExpiresAt, &item.CancelRequested, &item.NotBefore, &item.Priority,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Queue priorities; items with a higher priority are claimed first
+const (
+	PriorityBackground  = 0  // Scheduled runs
+	PriorityInteractive = 10 // Runs a user is waiting for, e.g. "run now"
+)
+
+// sourcePriority returns the queue priority of runs queued by the given source
+func sourcePriority(source string) int {
+	if source == "manual" {
+		return PriorityInteractive
+	}
+	return PriorityBackground
+}
+
+// EnqueueRun creates a queued run together with the queue item that will execute it.
+// It returns ErrDuplicateOccurrence when the run's occurrence key is already taken.
+func (s *Store) EnqueueRun(run *model.Run, source string) (*model.QueueItem, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	item, err := enqueueRun(tx, run, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, tx.Commit()
+}
+
+// EnqueueScheduledRuns advances a schedule to its next run time and queues the occurrences that
+// became due in a single transaction, so a crash can neither lose an occurrence nor queue it twice.
+// Occurrences whose key already has a run (e.g. a manual run) are left out. The schedule is only
+// advanced while it is still due, which makes this a compare-and-set between plugin instances sharing
+// the database: when another instance already queued the occurrences, nothing is written and the
+// returned items are nil. The runs count towards the schedule's occurrence limit; a nil nextRunAt
+// means the schedule has no occurrences left and disables it.
+func (s *Store) EnqueueScheduledRuns(schedule *model.Schedule, nextRunAt *time.Time, runs []*model.Run) ([]*model.QueueItem, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE schedules SET next_run_at = ?, occurrence_count = occurrence_count + ?, enabled = ?
+		WHERE id = ? AND org_id = ? AND enabled = 1
+		  AND (next_run_at IS NULL OR next_run_at <= ?)`,
+		utcTime(nextRunAt), len(runs), nextRunAt != nil, schedule.ID, schedule.OrgID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, err
+	}
+	schedule.NextRunAt = nextRunAt
+	schedule.OccurrenceCount += len(runs)
+	schedule.Enabled = nextRunAt != nil
+
+	items := make([]*model.QueueItem, 0, len(runs))
+	for _, run := range runs {
+		item, err := enqueueRun(tx, run, "schedule")
+		if errors.Is(err, ErrDuplicateOccurrence) {
+			// The occurrence was already queued (e.g. by a manual run)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, tx.Commit()
+}
+
+// enqueueRun inserts a queued run and its queue item inside a transaction
+func enqueueRun(tx *sql.Tx, run *model.Run, source string) (*model.QueueItem, error) {
+	now := time.Now().UTC()
+	run.Status = "queued"
+	if run.StartedAt.IsZero() {
+		run.StartedAt = now
+	}
+	if err := insertRun(tx, run); err != nil {
+		return nil, err
+	}
+
+	item := &model.QueueItem{
+		RunID:      run.ID,
+		ScheduleID: run.ScheduleID,
+		OrgID:      run.OrgID,
+		Source:     source,
+		Status:     "queued",
+		EnqueuedAt: now,
+		NotBefore:  utcTime(run.NotBefore),
+		Priority:   sourcePriority(source),
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO run_queue (run_id, schedule_id, org_id, source, status, attempts, enqueued_at, not_before, priority)
+		VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?)`,
+		item.RunID, item.ScheduleID, item.OrgID, item.Source, item.Status, item.EnqueuedAt, item.NotBefore, item.Priority,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	item.ID = id
+
+	return item, nil
+}
+
+// ClaimNextQueueItem atomically claims the next queued item of at least minPriority for a plugin
+// instance, leasing it for the given duration. Items are claimed by priority, then from the org with
+// the fewest runs executing (across all instances), then oldest first, so an org with many schedules
+// cannot starve the others. Orgs at their concurrency limit (Limits.MaxConcurrentRenders, 0 = no
+// limit) and staggered items before their not-before time are passed over. It returns nil when no
+// item is ready.
+func (s *Store) ClaimNextQueueItem(nodeID string, lease time.Duration, minPriority int) (*model.QueueItem, error) {
+	now := time.Now().UTC()
+	item, err := scanQueueItem(s.db.QueryRow(`
+		UPDATE run_queue SET status = 'claimed', claimed_at = ?, attempts = attempts + 1,
+			claimed_by = ?, lease_expires_at = ?
+		WHERE id = (
+			WITH running AS (
+				SELECT org_id, COUNT(*) AS runs FROM run_queue WHERE status = 'claimed' GROUP BY org_id
+			), quotas AS (
+				SELECT org_id, COALESCE(json_extract(CAST(limits AS TEXT), '$.max_concurrent_renders'), 0) AS max_runs
+				FROM settings
+			)
+			SELECT item.id FROM run_queue item
+			LEFT JOIN running ON running.org_id = item.org_id
+			LEFT JOIN quotas ON quotas.org_id = item.org_id
+			WHERE item.status = 'queued' AND item.priority >= ?
+			  AND (item.not_before IS NULL OR item.not_before <= ?)
+			  AND (COALESCE(quotas.max_runs, 0) <= 0 OR COALESCE(running.runs, 0) < quotas.max_runs)
+			ORDER BY item.priority DESC, COALESCE(running.runs, 0) ASC, item.enqueued_at ASC, item.id ASC
+			LIMIT 1
+		) AND status = 'queued'
+		RETURNING `+queueItemColumns,
+		now, nodeID, now.Add(lease), minPriority, now,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return item, err
+}
+
+// QueuePosition returns the 1-based position of a queued run among the ready items of its org,
+// i.e. how many of them go before it plus one. Other orgs' items are left out: they are interleaved
+// by fair share and their number is not the org's business. It returns 0 when the run is not waiting
+// in the queue.
+func (s *Store) QueuePosition(runID int64) (int, error) {
+	var ahead int
+	err := s.db.QueryRow(`
+		SELECT COUNT(other.id) FROM run_queue item
+		LEFT JOIN run_queue other ON other.org_id = item.org_id AND other.status = 'queued'
+			AND (other.not_before IS NULL OR other.not_before <= ?)
+			AND (other.priority > item.priority OR (other.priority = item.priority
+				AND (other.enqueued_at < item.enqueued_at OR (other.enqueued_at = item.enqueued_at AND other.id < item.id))))
+		WHERE item.run_id = ? AND item.status = 'queued'
+		GROUP BY item.id`,
+		time.Now().UTC(), runID,
+	).Scan(&ahead)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return ahead + 1, nil
+}
+
+// RenewQueueLease extends the lease on a claimed item. It returns false when the instance no
+// longer holds the lease, i.e. the item was recovered by another instance after the lease expired.
+func (s *Store) RenewQueueLease(id int64, nodeID string, lease time.Duration) (bool, error) {
+	result, err := s.db.Exec(
+		"UPDATE run_queue SET lease_expires_at = ? WHERE id = ? AND status = 'claimed' AND claimed_by = ?",
+		time.Now().UTC().Add(lease), id, nodeID,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// FinishQueueItem marks an item claimed by the given instance as finished with the given status
+// ("done", "failed" or "cancelled")
+func (s *Store) FinishQueueItem(id int64, nodeID, status string) error {
+	_, err := s.db.Exec(`
+		UPDATE run_queue SET status = ?, finished_at = ?, lease_expires_at = NULL
+		WHERE id = ? AND claimed_by = ?`,
+		status, time.Now().UTC(), id, nodeID,
+	)
+	return err
+}
+
+// RequeueQueueItem releases an item claimed by the given instance back to the queue, e.g. when its
+// run was interrupted by a shutdown. The attempt still counts towards the item's attempts.
+func (s *Store) RequeueQueueItem(id int64, nodeID string) error {
+	_, err := s.db.Exec(`
+		UPDATE run_queue SET status = 'queued', claimed_at = NULL, claimed_by = '', lease_expires_at = NULL
+		WHERE id = ? AND claimed_by = ? AND status = 'claimed'`,
+		id, nodeID,
+	)
+	return err
+}
+
+// CancelRun cancels a queued or running run. A queued run is cancelled right away and cancelled
+// is true. A running run is only flagged: the instance executing it must stop it (see
+// QueueCancelRequested) and record the cancellation. ErrRunNotActive is returned for runs that
+// already finished.
+func (s *Store) CancelRun(orgID, runID int64) (cancelled bool, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.Exec(`
+		UPDATE run_queue SET status = 'cancelled', finished_at = ?
+		WHERE run_id = ? AND org_id = ? AND status = 'queued'`,
+		now, runID, orgID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if affected > 0 {
+		if _, err := tx.Exec(`
+			UPDATE runs SET status = 'cancelled', finished_at = ?, error_text = 'Cancelled before it started'
+			WHERE id = ?`,
+			now, runID,
+		); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	result, err = tx.Exec(`
+		UPDATE run_queue SET cancel_requested = 1
+		WHERE run_id = ? AND org_id = ? AND status = 'claimed'`,
+		runID, orgID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if affected == 0 {
+		return false, ErrRunNotActive
+	}
+	return false, tx.Commit()
+}
+
+// QueueCancelRequested reports whether cancellation was requested for a claimed queue item
+func (s *Store) QueueCancelRequested(id int64) (bool, error) {
+	var requested bool
+	err := s.db.QueryRow("SELECT cancel_requested FROM run_queue WHERE id = ?", id).Scan(&requested)
+	return requested, err
+}
+
+// RecoverQueue handles work orphaned by plugin instances that died. Claimed items whose lease has
+// expired, or that were claimed by staleNodeID (a previous process of this instance), are cancelled
+// when cancellation was requested, re-queued while they have attempts left and failed otherwise.
+// Runs left in "running" without a claimed queue item (created before the queue existed) are failed.
+func (s *Store) RecoverQueue(staleNodeID string, maxAttempts int) (requeued, failed int64, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	orphaned := `status = 'claimed' AND (claimed_by = ? OR lease_expires_at IS NULL OR lease_expires_at < ?)`
+
+	// Runs cancelled while their instance was dying are not worth another attempt
+	if _, err := tx.Exec(`
+		UPDATE runs SET status = 'cancelled', finished_at = ?, error_text = 'Cancelled'
+		WHERE id IN (SELECT run_id FROM run_queue WHERE `+orphaned+` AND cancel_requested = 1)`,
+		now, staleNodeID, now,
+	); err != nil {
+		return 0, 0, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE run_queue SET status = 'cancelled', finished_at = ?, lease_expires_at = NULL
+		WHERE `+orphaned+` AND cancel_requested = 1`,
+		now, staleNodeID, now,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE runs SET status = 'queued', error_text = 'Re-queued after plugin restart'
+		WHERE id IN (SELECT run_id FROM run_queue WHERE `+orphaned+` AND attempts < ?)`,
+		staleNodeID, now, maxAttempts,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE run_queue SET status = 'queued', claimed_at = NULL, claimed_by = '', lease_expires_at = NULL
+		WHERE `+orphaned+` AND attempts < ?`,
+		staleNodeID, now, maxAttempts,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	requeued, _ = result.RowsAffected()
+
+	if _, err := tx.Exec(`
+		UPDATE runs SET status = 'failed', finished_at = ?, error_text = 'Interrupted by plugin restart (no attempts left)'
+		WHERE id IN (SELECT run_id FROM run_queue WHERE `+orphaned+`)`,
+		now, staleNodeID, now,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	result, err = tx.Exec(`
+		UPDATE run_queue SET status = 'failed', finished_at = ?, lease_expires_at = NULL
+		WHERE `+orphaned,
+		now, staleNodeID, now,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	failed, _ = result.RowsAffected()
+
+	result, err = tx.Exec(`
+		UPDATE runs SET status = 'failed', finished_at = ?, error_text = 'Interrupted by plugin restart'
+		WHERE status = 'running' AND id NOT IN (SELECT run_id FROM run_queue WHERE status = 'claimed')`,
+		now,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	interrupted, _ := result.RowsAffected()
+	failed += interrupted
+
+	return requeued, failed, tx.Commit()
+}
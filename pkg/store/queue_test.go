@@ -0,0 +1,541 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	st, err := NewStore(filepath.Join(t.TempDir(), "reporting.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func createTestSchedule(t *testing.T, st *Store) *model.Schedule {
+	t.Helper()
+	past := time.Now().Add(-time.Minute)
+	schedule := &model.Schedule{
+		OrgID:        1,
+		Name:         "test",
+		DashboardUID: "abc123",
+		RangeFrom:    "now-7d",
+		RangeTo:      "now",
+		IntervalType: "daily",
+		Timezone:     "UTC",
+		Format:       "pdf",
+		Recipients:   model.Recipients{To: []string{"ops@example.com"}},
+		Enabled:      true,
+		NextRunAt:    &past,
+		OwnerUserID:  1,
+	}
+	if err := st.CreateSchedule(schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	return schedule
+}
+
+func TestEnqueueScheduledRuns(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	due, err := st.GetDueSchedules()
+	if err != nil || len(due) != 1 {
+		t.Fatalf("GetDueSchedules() = %d schedules, err %v; want 1", len(due), err)
+	}
+
+	// Two missed occurrences are queued together, oldest first
+	next := time.Now().Add(24 * time.Hour)
+	first := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	runs := []*model.Run{
+		{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: "1@first", ScheduledFor: &first},
+		{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: "1@second", ScheduledFor: &second},
+	}
+	items, err := st.EnqueueScheduledRuns(schedule, &next, runs)
+	if err != nil {
+		t.Fatalf("EnqueueScheduledRuns() error = %v", err)
+	}
+	if len(items) != 2 || items[0].RunID != runs[0].ID || runs[0].Status != "queued" {
+		t.Fatalf("EnqueueScheduledRuns() = %d items, want 2 queued in order", len(items))
+	}
+
+	stored, err := st.GetRun(schedule.OrgID, runs[1].ID)
+	if err != nil {
+		t.Fatalf("GetRun() error = %v", err)
+	}
+	if stored.ScheduledFor == nil || !stored.ScheduledFor.Equal(second) {
+		t.Errorf("GetRun().ScheduledFor = %v, want %v", stored.ScheduledFor, second)
+	}
+
+	due, err = st.GetDueSchedules()
+	if err != nil || len(due) != 0 {
+		t.Errorf("GetDueSchedules() after enqueue = %d schedules, err %v; want 0", len(due), err)
+	}
+
+	// A second instance that read the schedule while it was due must not queue it again
+	duplicate, err := st.EnqueueScheduledRuns(schedule, &next, []*model.Run{{ScheduleID: schedule.ID, OrgID: schedule.OrgID}})
+	if err != nil || len(duplicate) != 0 {
+		t.Errorf("second EnqueueScheduledRuns() = %v, %v; want no items", duplicate, err)
+	}
+
+	claimed, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+	if err != nil || claimed == nil {
+		t.Fatalf("ClaimNextQueueItem() = %v, %v", claimed, err)
+	}
+	if claimed.ID != items[0].ID || claimed.Status != "claimed" || claimed.Attempts != 1 {
+		t.Errorf("claimed item = %+v, want id %d claimed with 1 attempt", claimed, items[0].ID)
+	}
+
+	if _, err := st.ClaimNextQueueItem("node-b", time.Minute, PriorityBackground); err != nil {
+		t.Fatalf("ClaimNextQueueItem() error = %v", err)
+	}
+	empty, err := st.ClaimNextQueueItem("node-b", time.Minute, PriorityBackground)
+	if err != nil || empty != nil {
+		t.Errorf("ClaimNextQueueItem() on empty queue = %v, %v; want nil", empty, err)
+	}
+}
+
+func TestEnqueueScheduledRuns_Expires(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	// The last occurrence of the schedule counts towards its limit and disables it
+	runs := []*model.Run{{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: "1@last"}}
+	if _, err := st.EnqueueScheduledRuns(schedule, nil, runs); err != nil {
+		t.Fatalf("EnqueueScheduledRuns() error = %v", err)
+	}
+
+	stored, err := st.GetSchedule(schedule.OrgID, schedule.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if stored.Enabled || stored.NextRunAt != nil || stored.OccurrenceCount != 1 {
+		t.Errorf("schedule = enabled %v, next run %v, %d occurrence(s); want disabled without next run after 1",
+			stored.Enabled, stored.NextRunAt, stored.OccurrenceCount)
+	}
+	if schedule.Enabled {
+		t.Error("EnqueueScheduledRuns() left the schedule enabled")
+	}
+}
+
+func TestRecoverQueue(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	run := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(run, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	// Simulate a process that claimed the run and died mid-render, maxAttempts times
+	for attempt := 1; attempt <= 2; attempt++ {
+		item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+		if err != nil || item == nil {
+			t.Fatalf("attempt %d: ClaimNextQueueItem() = %v, %v", attempt, item, err)
+		}
+
+		requeued, failed, err := st.RecoverQueue("node-a", 2)
+		if err != nil {
+			t.Fatalf("attempt %d: RecoverQueue() error = %v", attempt, err)
+		}
+
+		got, err := st.GetRun(run.OrgID, run.ID)
+		if err != nil {
+			t.Fatalf("GetRun() error = %v", err)
+		}
+
+		if attempt == 1 {
+			if requeued != 1 || failed != 0 || got.Status != "queued" {
+				t.Errorf("first recovery: requeued=%d failed=%d status=%s; want 1, 0, queued", requeued, failed, got.Status)
+			}
+		} else {
+			if requeued != 0 || failed != 1 || got.Status != "failed" || got.FinishedAt == nil {
+				t.Errorf("final recovery: requeued=%d failed=%d status=%s; want 0, 1, failed", requeued, failed, got.Status)
+			}
+		}
+	}
+}
+
+func TestRecoverQueue_ExpiredLease(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	run := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(run, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	live, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+	if err != nil || live == nil {
+		t.Fatalf("ClaimNextQueueItem() = %v, %v", live, err)
+	}
+
+	// Another instance must leave a live lease alone
+	if requeued, failed, err := st.RecoverQueue("node-b", 3); err != nil || requeued != 0 || failed != 0 {
+		t.Fatalf("RecoverQueue() with live lease = %d, %d, %v; want nothing recovered", requeued, failed, err)
+	}
+	if held, err := st.RenewQueueLease(live.ID, "node-a", -time.Second); err != nil || !held {
+		t.Fatalf("RenewQueueLease() = %v, %v; want lease held", held, err)
+	}
+
+	// Once the lease has expired the item is recovered and can be claimed elsewhere
+	if requeued, _, err := st.RecoverQueue("node-b", 3); err != nil || requeued != 1 {
+		t.Fatalf("RecoverQueue() with expired lease = %d, %v; want 1 re-queued", requeued, err)
+	}
+	if held, err := st.RenewQueueLease(live.ID, "node-a", time.Minute); err != nil || held {
+		t.Errorf("RenewQueueLease() after recovery = %v, %v; want lease lost", held, err)
+	}
+
+	reclaimed, err := st.ClaimNextQueueItem("node-b", time.Minute, PriorityBackground)
+	if err != nil || reclaimed == nil || reclaimed.ClaimedBy != "node-b" || reclaimed.Attempts != 2 {
+		t.Errorf("ClaimNextQueueItem() after recovery = %+v, %v; want claimed by node-b on attempt 2", reclaimed, err)
+	}
+}
+
+func TestClaimNextQueueItem_NotBefore(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	// A staggered run waits for its start while a later unstaggered one goes ahead
+	later := time.Now().Add(time.Hour).Truncate(time.Second)
+	staggered := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID, NotBefore: &later}
+	if _, err := st.EnqueueRun(staggered, "schedule"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+	manual := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(manual, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+	if err != nil || item == nil || item.RunID != manual.ID {
+		t.Fatalf("ClaimNextQueueItem() = %+v, %v; want the unstaggered run", item, err)
+	}
+	if item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground); err != nil || item != nil {
+		t.Errorf("ClaimNextQueueItem() = %+v, %v; want nothing before the staggered start", item, err)
+	}
+
+	stored, err := st.GetRun(schedule.OrgID, staggered.ID)
+	if err != nil || stored.NotBefore == nil || !stored.NotBefore.Equal(later) {
+		t.Errorf("GetRun().NotBefore = %v, %v; want %v", stored.NotBefore, err, later)
+	}
+}
+
+func TestClaimNextQueueItem_Priority(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	var scheduled []*model.Run
+	for i := 0; i < 3; i++ {
+		run := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+		if _, err := st.EnqueueRun(run, "schedule"); err != nil {
+			t.Fatalf("EnqueueRun() error = %v", err)
+		}
+		scheduled = append(scheduled, run)
+	}
+	manual := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(manual, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	// The manual run goes ahead of the scheduled runs queued before it
+	for _, tt := range []struct {
+		run  *model.Run
+		want int
+	}{{manual, 1}, {scheduled[0], 2}, {scheduled[2], 4}} {
+		if got, err := st.QueuePosition(tt.run.ID); err != nil || got != tt.want {
+			t.Errorf("QueuePosition(%d) = %d, %v; want %d", tt.run.ID, got, err, tt.want)
+		}
+	}
+
+	// The interactive lane only takes interactive items
+	item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityInteractive)
+	if err != nil || item == nil || item.RunID != manual.ID || item.Priority != PriorityInteractive {
+		t.Fatalf("ClaimNextQueueItem(interactive) = %+v, %v; want the manual run", item, err)
+	}
+	if item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityInteractive); err != nil || item != nil {
+		t.Errorf("ClaimNextQueueItem(interactive) = %+v, %v; want nothing", item, err)
+	}
+	if got, err := st.QueuePosition(manual.ID); err != nil || got != 0 {
+		t.Errorf("QueuePosition(claimed) = %d, %v; want 0", got, err)
+	}
+
+	item, err = st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+	if err != nil || item == nil || item.RunID != scheduled[0].ID {
+		t.Fatalf("ClaimNextQueueItem(background) = %+v, %v; want the oldest scheduled run", item, err)
+	}
+	if got, err := st.QueuePosition(scheduled[2].ID); err != nil || got != 2 {
+		t.Errorf("QueuePosition() = %d, %v; want 2", got, err)
+	}
+}
+
+func TestClaimNextQueueItem_FairShare(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+	enqueue := func(orgID int64) *model.Run {
+		t.Helper()
+		run := &model.Run{ScheduleID: schedule.ID, OrgID: orgID}
+		if _, err := st.EnqueueRun(run, "schedule"); err != nil {
+			t.Fatalf("EnqueueRun() error = %v", err)
+		}
+		return run
+	}
+	busy, first, second := enqueue(1), enqueue(1), enqueue(1)
+	other := enqueue(2)
+	claim := func() *model.QueueItem {
+		t.Helper()
+		item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+		if err != nil {
+			t.Fatalf("ClaimNextQueueItem() error = %v", err)
+		}
+		return item
+	}
+
+	// Org 2 goes ahead of org 1's older runs once org 1 has a run executing
+	if item := claim(); item == nil || item.RunID != busy.ID {
+		t.Fatalf("first claim = %+v, want run %d", item, busy.ID)
+	}
+	if item := claim(); item == nil || item.RunID != other.ID {
+		t.Fatalf("second claim = %+v, want org 2's run %d", item, other.ID)
+	}
+
+	// Org 1 is held at its concurrency limit
+	limit := func(maxRuns int) {
+		t.Helper()
+		settings := &model.Settings{OrgID: 1, Limits: model.Limits{MaxConcurrentRenders: maxRuns}}
+		if err := st.UpsertSettings(settings); err != nil {
+			t.Fatalf("UpsertSettings() error = %v", err)
+		}
+	}
+	limit(1)
+	if item := claim(); item != nil {
+		t.Fatalf("claim at the concurrency limit = %+v, want nothing", item)
+	}
+	limit(2)
+	if item := claim(); item == nil || item.RunID != first.ID {
+		t.Fatalf("claim below the concurrency limit = %+v, want run %d", item, first.ID)
+	}
+	if got, err := st.QueuePosition(second.ID); err != nil || got != 1 {
+		t.Errorf("QueuePosition() = %d, %v; want 1", got, err)
+	}
+}
+
+func TestCancelRun(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	running := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(running, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+	item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+	if err != nil || item == nil {
+		t.Fatalf("ClaimNextQueueItem() = %v, %v", item, err)
+	}
+	queued := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(queued, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	// A queued run is cancelled right away and never claimed
+	if cancelled, err := st.CancelRun(schedule.OrgID, queued.ID); err != nil || !cancelled {
+		t.Fatalf("CancelRun(queued) = %v, %v; want cancelled", cancelled, err)
+	}
+	if run, _ := st.GetRun(schedule.OrgID, queued.ID); run.Status != "cancelled" {
+		t.Errorf("queued run status = %q, want cancelled", run.Status)
+	}
+	if next, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground); err != nil || next != nil {
+		t.Errorf("ClaimNextQueueItem() = %+v, %v; want empty queue", next, err)
+	}
+
+	// A running run is flagged for the instance executing it
+	if _, err := st.CancelRun(2, running.ID); err != ErrRunNotActive {
+		t.Errorf("CancelRun() from another org error = %v, want ErrRunNotActive", err)
+	}
+	if cancelled, err := st.CancelRun(schedule.OrgID, running.ID); err != nil || cancelled {
+		t.Fatalf("CancelRun(running) = %v, %v; want cancellation requested", cancelled, err)
+	}
+	if requested, err := st.QueueCancelRequested(item.ID); err != nil || !requested {
+		t.Errorf("QueueCancelRequested() = %v, %v; want true", requested, err)
+	}
+
+	// If that instance dies, recovery cancels the run instead of retrying it
+	if requeued, failed, err := st.RecoverQueue("node-a", 3); err != nil || requeued != 0 || failed != 0 {
+		t.Fatalf("RecoverQueue() = %d, %d, %v; want nothing re-queued or failed", requeued, failed, err)
+	}
+	if run, _ := st.GetRun(schedule.OrgID, running.ID); run.Status != "cancelled" {
+		t.Errorf("running run status after recovery = %q, want cancelled", run.Status)
+	}
+	if _, err := st.CancelRun(schedule.OrgID, running.ID); err != ErrRunNotActive {
+		t.Errorf("CancelRun() of a finished run error = %v, want ErrRunNotActive", err)
+	}
+}
+
+func TestDeleteSchedule_CancelsQueue(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	running := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(running, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+	item, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground)
+	if err != nil || item == nil {
+		t.Fatalf("ClaimNextQueueItem() = %v, %v", item, err)
+	}
+	queued := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID}
+	if _, err := st.EnqueueRun(queued, "schedule"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	if err := st.DeleteSchedule(schedule.OrgID, schedule.ID); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	if run, _ := st.GetRun(schedule.OrgID, queued.ID); run.Status != "cancelled" {
+		t.Errorf("queued run status = %q, want cancelled", run.Status)
+	}
+	if next, err := st.ClaimNextQueueItem("node-a", time.Minute, PriorityBackground); err != nil || next != nil {
+		t.Errorf("ClaimNextQueueItem() = %+v, %v; want empty queue", next, err)
+	}
+	if requested, err := st.QueueCancelRequested(item.ID); err != nil || !requested {
+		t.Errorf("QueueCancelRequested() of the running run = %v, %v; want true", requested, err)
+	}
+}
+
+func TestNewStore_PathWithQuery(t *testing.T) {
+	st, err := NewStore(filepath.Join(t.TempDir(), "reporting.db") + "?_pragma=journal_mode(wal)")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer st.Close()
+
+	var timeout int
+	if err := st.db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil || timeout != 5000 {
+		t.Errorf("busy_timeout = %d, %v; want 5000", timeout, err)
+	}
+}
+
+func TestEnqueue_DuplicateOccurrence(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+	key := "1@2025-01-01T08:00:00Z"
+
+	// A manual run takes over the due occurrence first
+	manual := &model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: key}
+	if _, err := st.EnqueueRun(manual, "manual"); err != nil {
+		t.Fatalf("EnqueueRun() error = %v", err)
+	}
+
+	if _, err := st.EnqueueRun(&model.Run{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: key}, "manual"); err != ErrDuplicateOccurrence {
+		t.Errorf("second EnqueueRun() error = %v, want ErrDuplicateOccurrence", err)
+	}
+
+	// The scheduler tick for the same occurrence only advances the schedule
+	next := time.Now().Add(24 * time.Hour)
+	items, err := st.EnqueueScheduledRuns(schedule, &next, []*model.Run{{ScheduleID: schedule.ID, OrgID: schedule.OrgID, OccurrenceKey: key}})
+	if err != nil || len(items) != 0 {
+		t.Fatalf("EnqueueScheduledRuns() = %v, %v; want no items", items, err)
+	}
+	if due, err := st.GetDueSchedules(); err != nil || len(due) != 0 {
+		t.Errorf("GetDueSchedules() = %d schedules, err %v; want schedule advanced", len(due), err)
+	}
+}
+
+func TestDeliveryLedger(t *testing.T) {
+	st := newTestStore(t)
+	key := "1@2025-01-01T08:00:00Z"
+
+	if ok, err := st.BeginDelivery(key); err != nil || !ok {
+		t.Fatalf("BeginDelivery() = %v, %v; want reserved", ok, err)
+	}
+	if ok, err := st.BeginDelivery(key); err != nil || ok {
+		t.Errorf("BeginDelivery() while sending = %v, %v; want refused", ok, err)
+	}
+	if state, err := st.DeliveryState(key, time.Minute); err != nil || state != DeliverySending {
+		t.Errorf("DeliveryState() while sending = %q, %v; want sending", state, err)
+	}
+
+	// A reservation older than a run may take was left behind by an interrupted send
+	if state, err := st.DeliveryState(key, -time.Second); err != nil || state != DeliveryUnknown {
+		t.Errorf("DeliveryState() of a stale reservation = %q, %v; want unknown", state, err)
+	}
+
+	// A failed send releases the reservation
+	if err := st.AbortDelivery(key); err != nil {
+		t.Fatalf("AbortDelivery() error = %v", err)
+	}
+	if state, err := st.DeliveryState(key, time.Minute); err != nil || state != DeliveryNone {
+		t.Errorf("DeliveryState() after abort = %q, %v; want none", state, err)
+	}
+
+	if ok, err := st.BeginDelivery(key); err != nil || !ok {
+		t.Fatalf("BeginDelivery() after abort = %v, %v; want reserved", ok, err)
+	}
+	if err := st.CompleteDelivery(key); err != nil {
+		t.Fatalf("CompleteDelivery() error = %v", err)
+	}
+	if err := st.AbortDelivery(key); err != nil {
+		t.Fatalf("AbortDelivery() error = %v", err)
+	}
+	if state, err := st.DeliveryState(key, -time.Second); err != nil || state != DeliverySent {
+		t.Errorf("DeliveryState() after completion = %q, %v; want sent", state, err)
+	}
+
+	// Records are kept until they are older than the retention
+	if pruned, err := st.PruneDeliveries(time.Now().Add(-time.Hour)); err != nil || pruned != 0 {
+		t.Errorf("PruneDeliveries() of recent records = %d, %v; want 0", pruned, err)
+	}
+	if pruned, err := st.PruneDeliveries(time.Now().Add(time.Minute)); err != nil || pruned != 1 {
+		t.Errorf("PruneDeliveries() = %d, %v; want 1", pruned, err)
+	}
+	if state, err := st.DeliveryState(key, time.Minute); err != nil || state != DeliveryNone {
+		t.Errorf("DeliveryState() after pruning = %q, %v; want none", state, err)
+	}
+}
+
+func TestFinishBackfillRuns(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	backfill := &model.Backfill{ScheduleID: schedule.ID, OrgID: schedule.OrgID, From: time.Now().Add(-48 * time.Hour), To: time.Now(), SendEmail: true}
+	runs := []*model.Run{
+		{ScheduleID: schedule.ID, OrgID: schedule.OrgID},
+		{ScheduleID: schedule.ID, OrgID: schedule.OrgID},
+	}
+	if err := st.CreateBackfill(backfill, runs); err != nil {
+		t.Fatalf("CreateBackfill() error = %v", err)
+	}
+	if stored, err := st.ListBackfillRuns(backfill.ID); err != nil || len(stored) != 2 || *stored[0].BackfillID != backfill.ID {
+		t.Fatalf("ListBackfillRuns() = %v, %v; want both runs", stored, err)
+	}
+
+	// The backfill stays running while one of its runs is queued
+	runs[0].Status = "completed"
+	if err := st.UpdateRun(runs[0]); err != nil {
+		t.Fatalf("UpdateRun() error = %v", err)
+	}
+	if finished, err := st.FinishBackfillRuns(backfill.ID, "delivering"); err != nil || finished {
+		t.Fatalf("FinishBackfillRuns() = %v, %v; want false with a run queued", finished, err)
+	}
+
+	// Once all runs ended, exactly one caller finishes it
+	if _, err := st.CancelRun(schedule.OrgID, runs[1].ID); err != nil {
+		t.Fatalf("CancelRun() error = %v", err)
+	}
+	if finished, err := st.FinishBackfillRuns(backfill.ID, "delivering"); err != nil || !finished {
+		t.Fatalf("FinishBackfillRuns() = %v, %v; want true", finished, err)
+	}
+	if finished, err := st.FinishBackfillRuns(backfill.ID, "delivering"); err != nil || finished {
+		t.Errorf("FinishBackfillRuns() again = %v, %v; want false", finished, err)
+	}
+	if got, err := st.GetBackfill(schedule.OrgID, backfill.ID); err != nil || got.Status != "delivering" || got.RunCount != 2 {
+		t.Errorf("GetBackfill() = %+v, %v; want delivering with 2 runs", got, err)
+	}
+}
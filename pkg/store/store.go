@@ -2,13 +2,21 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
 	"github.com/yourusername/sheduled-reports-app/pkg/model"
 )
 
+// ErrDuplicateOccurrence is returned when a run for the same schedule occurrence already exists
+var ErrDuplicateOccurrence = errors.New("a run for this occurrence already exists")
+
+// ErrRunNotActive is returned when cancelling a run that is neither queued nor running
+var ErrRunNotActive = errors.New("run is not queued or running")
+
 // Store handles database operations
 type Store struct {
 	db *sql.DB
@@ -16,7 +24,12 @@ type Store struct {
 
 // NewStore creates a new store instance
 func NewStore(dbPath string) (*Store, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	// Wait on locks instead of failing immediately: several Grafana replicas may share the database
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	db, err := sql.Open("sqlite", dbPath+separator+"_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -101,6 +114,63 @@ func (s *Store) migrate() error {
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`CREATE TABLE IF NOT EXISTS run_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL UNIQUE,
+			schedule_id INTEGER NOT NULL,
+			org_id INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			enqueued_at DATETIME NOT NULL,
+			claimed_at DATETIME,
+			finished_at DATETIME,
+			FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_run_queue_status ON run_queue(status, enqueued_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_run_queue_org_status ON run_queue(org_id, status)`,
+		`CREATE TABLE IF NOT EXISTS deliveries (
+			delivery_key TEXT PRIMARY KEY,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			delivered_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS calendars (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			org_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			holidays TEXT NOT NULL,
+			blackouts TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_calendars_org_id ON calendars(org_id)`,
+		`CREATE TABLE IF NOT EXISTS backfills (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			schedule_id INTEGER NOT NULL,
+			org_id INTEGER NOT NULL,
+			range_from DATETIME NOT NULL,
+			range_to DATETIME NOT NULL,
+			send_email INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL,
+			run_count INTEGER NOT NULL DEFAULT 0,
+			error_text TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			finished_at DATETIME,
+			FOREIGN KEY (schedule_id) REFERENCES schedules(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_backfills_schedule_id ON backfills(schedule_id)`,
+		`CREATE TABLE IF NOT EXISTS trigger_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			schedule_id INTEGER NOT NULL,
+			org_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			created_at DATETIME NOT NULL,
+			last_used_at DATETIME,
+			FOREIGN KEY (schedule_id) REFERENCES schedules(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trigger_tokens_schedule_id ON trigger_tokens(schedule_id)`,
 	}
 
 	for _, migration := range migrations {
@@ -109,9 +179,222 @@ func (s *Store) migrate() error {
 		}
 	}
 
+	// Schedules created before presets had a time of day are given one below
+	hasPresetTimes, err := s.hasColumn("schedules", "time_of_day")
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	// Columns added after the initial schema; applied to existing databases in place
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"schedules", "time_of_day", "TEXT NOT NULL DEFAULT ''"},
+		{"schedules", "day_of_week", "INTEGER NOT NULL DEFAULT 0"},
+		{"schedules", "day_of_month", "INTEGER NOT NULL DEFAULT 1"},
+		{"schedules", "business_day", "INTEGER NOT NULL DEFAULT 0"},
+		{"run_queue", "claimed_by", "TEXT NOT NULL DEFAULT ''"},
+		{"run_queue", "lease_expires_at", "DATETIME"},
+		{"runs", "occurrence_key", "TEXT"},
+		{"schedules", "misfire_policy", "TEXT NOT NULL DEFAULT ''"},
+		{"runs", "scheduled_for", "DATETIME"},
+		{"runs", "range_from", "TEXT"},
+		{"runs", "range_to", "TEXT"},
+		{"schedules", "calendar_id", "INTEGER"},
+		{"schedules", "calendar_policy", "TEXT NOT NULL DEFAULT ''"},
+		{"schedules", "condition", "TEXT"},
+		{"runs", "condition_value", "REAL"},
+		{"schedules", "skip_unchanged", "TEXT NOT NULL DEFAULT ''"},
+		{"runs", "image_hash", "TEXT"},
+		{"schedules", "retry_policy", "TEXT"},
+		{"settings", "retry_policy", "TEXT"},
+		{"run_queue", "cancel_requested", "INTEGER NOT NULL DEFAULT 0"},
+		{"schedules", "run_timeout_seconds", "INTEGER NOT NULL DEFAULT 0"},
+		{"schedules", "start_date", "DATETIME"},
+		{"schedules", "end_date", "DATETIME"},
+		{"schedules", "max_occurrences", "INTEGER NOT NULL DEFAULT 0"},
+		{"schedules", "occurrence_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"runs", "not_before", "DATETIME"},
+		{"run_queue", "not_before", "DATETIME"},
+		{"schedules", "spread_minutes", "INTEGER NOT NULL DEFAULT 0"},
+		{"run_queue", "priority", "INTEGER NOT NULL DEFAULT 0"},
+		{"runs", "backfill_id", "INTEGER"},
+		{"runs", "trigger_id", "INTEGER"},
+		{"runs", "trigger_source", "TEXT"},
+		{"runs", "variables", "TEXT"},
+		{"runs", "render_wait_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"schedules", "panel_layout", "TEXT NOT NULL DEFAULT ''"},
+	}
+
+	for _, column := range columns {
+		if err := s.addColumnIfMissing(column.table, column.name, column.definition); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	if !hasPresetTimes {
+		if err := s.migratePresetTimes(); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	// Indexes on added columns
+	indexes := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_runs_occurrence_key ON runs(occurrence_key)`,
+	}
+
+	for _, index := range indexes {
+		if _, err := s.db.Exec(index); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migratePresetTimes gives daily, weekly and monthly schedules created before presets had a time
+// of day the time, weekday and day of month of their next run in their timezone (or of their last
+// update, when they have no next run). They used to fire a day, week or month after they were saved,
+// so this keeps their reports at the wall-clock time they were sent at instead of midnight.
+func (s *Store) migratePresetTimes() error {
+	rows, err := s.db.Query(`
+		SELECT id, timezone, next_run_at, updated_at FROM schedules
+		WHERE interval_type IN ('daily', 'weekly', 'monthly') AND time_of_day = ''`)
+	if err != nil {
+		return err
+	}
+
+	type presetTime struct {
+		id  int64
+		at time.Time
+	}
+	var presets []presetTime
+	for rows.Next() {
+		var (
+			id        int64
+			timezone  string
+			nextRunAt *time.Time
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&id, &timezone, &nextRunAt, &updatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		at := updatedAt
+		if nextRunAt != nil {
+			at = *nextRunAt
+		}
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		presets = append(presets, presetTime{id: id, at: at.In(loc)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, preset := range presets {
+		if _, err := s.db.Exec(
+			"UPDATE schedules SET time_of_day = ?, day_of_week = ?, day_of_month = ? WHERE id = ?",
+			preset.at.Format("15:04"), int(preset.at.Weekday()), preset.at.Day(), preset.id,
+		); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// hasColumn reports whether a table has a column
+func (s *Store) hasColumn(table, column string) (bool, error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name, ctype  string
+			notNull, pk  int
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func (s *Store) addColumnIfMissing(table, column, definition string) error {
+	exists, err := s.hasColumn(table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// scheduleColumns is the column list shared by all schedule queries (order matches scanSchedule)
+const scheduleColumns = `id, org_id, name, dashboard_uid, dashboard_title, panel_ids, panel_layout, range_from, range_to,
+		       interval_type, cron_expr, time_of_day, day_of_week, day_of_month, business_day,
+		       timezone, misfire_policy, calendar_id, calendar_policy, condition, skip_unchanged, retry_policy,
+		       run_timeout_seconds, start_date, end_date, max_occurrences, occurrence_count, spread_minutes,
+		       format, variables, recipients, email_subject, email_body, template_id, enabled,
+		       last_run_at, next_run_at,
+		       owner_user_id, created_at, updated_at`
+
+// utcTime normalizes a timestamp to UTC so stored values compare correctly against each other and
+// against UTC time parameters (datetime('now') only has second precision).
+// Next run times are computed in the schedule's timezone and would otherwise be persisted with its offset.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// nullString stores empty strings as NULL (e.g. for columns with a unique index)
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSchedule scans a row selected with scheduleColumns
+func scanSchedule(row rowScanner) (*model.Schedule, error) {
+	schedule := &model.Schedule{}
+	err := row.Scan(
+		&schedule.ID, &schedule.OrgID, &schedule.Name, &schedule.DashboardUID,
+		&schedule.DashboardTitle, &schedule.PanelIDs, &schedule.PanelLayout, &schedule.RangeFrom, &schedule.RangeTo,
+		&schedule.IntervalType, &schedule.CronExpr, &schedule.TimeOfDay, &schedule.DayOfWeek,
+		&schedule.DayOfMonth, &schedule.BusinessDay, &schedule.Timezone, &schedule.MisfirePolicy,
+		&schedule.CalendarID, &schedule.CalendarPolicy, &schedule.Condition, &schedule.SkipUnchanged, &schedule.RetryPolicy,
+		&schedule.RunTimeoutSeconds, &schedule.StartDate, &schedule.EndDate, &schedule.MaxOccurrences, &schedule.OccurrenceCount,
+		&schedule.SpreadMinutes, &schedule.Format,
+		&schedule.Variables, &schedule.Recipients, &schedule.EmailSubject, &schedule.EmailBody,
+		&schedule.TemplateID, &schedule.Enabled, &schedule.LastRunAt, &schedule.NextRunAt,
+		&schedule.OwnerUserID, &schedule.CreatedAt, &schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
 // CreateSchedule creates a new schedule
 func (s *Store) CreateSchedule(schedule *model.Schedule) error {
 	now := time.Now()
@@ -120,16 +403,21 @@ func (s *Store) CreateSchedule(schedule *model.Schedule) error {
 
 	result, err := s.db.Exec(`
 		INSERT INTO schedules (
-			org_id, name, dashboard_uid, dashboard_title, panel_ids, range_from, range_to,
-			interval_type, cron_expr, timezone, format, variables, recipients,
-			email_subject, email_body, template_id, enabled, owner_user_id,
-			next_run_at, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			org_id, name, dashboard_uid, dashboard_title, panel_ids, panel_layout, range_from, range_to,
+			interval_type, cron_expr, time_of_day, day_of_week, day_of_month, business_day,
+			timezone, misfire_policy, calendar_id, calendar_policy, condition, skip_unchanged, retry_policy,
+			run_timeout_seconds, start_date, end_date, max_occurrences, spread_minutes, format, variables, recipients,
+			email_subject, email_body, template_id, enabled, owner_user_id, next_run_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		schedule.OrgID, schedule.Name, schedule.DashboardUID, schedule.DashboardTitle,
-		schedule.PanelIDs, schedule.RangeFrom, schedule.RangeTo, schedule.IntervalType,
-		schedule.CronExpr, schedule.Timezone, schedule.Format, schedule.Variables,
-		schedule.Recipients, schedule.EmailSubject, schedule.EmailBody, schedule.TemplateID,
-		schedule.Enabled, schedule.OwnerUserID, schedule.NextRunAt, now, now,
+		schedule.PanelIDs, schedule.PanelLayout, schedule.RangeFrom, schedule.RangeTo, schedule.IntervalType,
+		schedule.CronExpr, schedule.TimeOfDay, schedule.DayOfWeek, schedule.DayOfMonth, schedule.BusinessDay,
+		schedule.Timezone, schedule.MisfirePolicy, schedule.CalendarID, schedule.CalendarPolicy, schedule.Condition,
+		schedule.SkipUnchanged, schedule.RetryPolicy, schedule.RunTimeoutSeconds, utcTime(schedule.StartDate),
+		utcTime(schedule.EndDate), schedule.MaxOccurrences, schedule.SpreadMinutes, schedule.Format, schedule.Variables,
+		schedule.Recipients,
+		schedule.EmailSubject, schedule.EmailBody,
+		schedule.TemplateID, schedule.Enabled, schedule.OwnerUserID, utcTime(schedule.NextRunAt), now, now,
 	)
 	if err != nil {
 		return err
@@ -146,22 +434,11 @@ func (s *Store) CreateSchedule(schedule *model.Schedule) error {
 
 // GetSchedule retrieves a schedule by ID
 func (s *Store) GetSchedule(orgID, id int64) (*model.Schedule, error) {
-	schedule := &model.Schedule{}
-	err := s.db.QueryRow(`
-		SELECT id, org_id, name, dashboard_uid, dashboard_title, panel_ids, range_from, range_to,
-		       interval_type, cron_expr, timezone, format, variables, recipients,
-		       email_subject, email_body, template_id, enabled, last_run_at, next_run_at,
-		       owner_user_id, created_at, updated_at
+	schedule, err := scanSchedule(s.db.QueryRow(`
+		SELECT `+scheduleColumns+`
 		FROM schedules WHERE id = ? AND org_id = ?`,
 		id, orgID,
-	).Scan(
-		&schedule.ID, &schedule.OrgID, &schedule.Name, &schedule.DashboardUID,
-		&schedule.DashboardTitle, &schedule.PanelIDs, &schedule.RangeFrom, &schedule.RangeTo,
-		&schedule.IntervalType, &schedule.CronExpr, &schedule.Timezone, &schedule.Format,
-		&schedule.Variables, &schedule.Recipients, &schedule.EmailSubject, &schedule.EmailBody,
-		&schedule.TemplateID, &schedule.Enabled, &schedule.LastRunAt, &schedule.NextRunAt,
-		&schedule.OwnerUserID, &schedule.CreatedAt, &schedule.UpdatedAt,
-	)
+	))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("schedule not found")
 	}
@@ -171,10 +448,7 @@ func (s *Store) GetSchedule(orgID, id int64) (*model.Schedule, error) {
 // ListSchedules retrieves all schedules for an organization
 func (s *Store) ListSchedules(orgID int64) ([]*model.Schedule, error) {
 	rows, err := s.db.Query(`
-		SELECT id, org_id, name, dashboard_uid, dashboard_title, panel_ids, range_from, range_to,
-		       interval_type, cron_expr, timezone, format, variables, recipients,
-		       email_subject, email_body, template_id, enabled, last_run_at, next_run_at,
-		       owner_user_id, created_at, updated_at
+		SELECT `+scheduleColumns+`
 		FROM schedules WHERE org_id = ? ORDER BY created_at DESC`,
 		orgID,
 	)
@@ -185,15 +459,7 @@ func (s *Store) ListSchedules(orgID int64) ([]*model.Schedule, error) {
 
 	schedules := make([]*model.Schedule, 0)
 	for rows.Next() {
-		schedule := &model.Schedule{}
-		err := rows.Scan(
-			&schedule.ID, &schedule.OrgID, &schedule.Name, &schedule.DashboardUID,
-			&schedule.DashboardTitle, &schedule.PanelIDs, &schedule.RangeFrom, &schedule.RangeTo,
-			&schedule.IntervalType, &schedule.CronExpr, &schedule.Timezone, &schedule.Format,
-			&schedule.Variables, &schedule.Recipients, &schedule.EmailSubject, &schedule.EmailBody,
-			&schedule.TemplateID, &schedule.Enabled, &schedule.LastRunAt, &schedule.NextRunAt,
-			&schedule.OwnerUserID, &schedule.CreatedAt, &schedule.UpdatedAt,
-		)
+		schedule, err := scanSchedule(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -209,39 +475,120 @@ func (s *Store) UpdateSchedule(schedule *model.Schedule) error {
 
 	_, err := s.db.Exec(`
 		UPDATE schedules SET
-			name = ?, dashboard_uid = ?, dashboard_title = ?, panel_ids = ?,
+			name = ?, dashboard_uid = ?, dashboard_title = ?, panel_ids = ?, panel_layout = ?,
 			range_from = ?, range_to = ?, interval_type = ?, cron_expr = ?,
-			timezone = ?, format = ?, variables = ?, recipients = ?,
+			time_of_day = ?, day_of_week = ?, day_of_month = ?, business_day = ?,
+			timezone = ?, misfire_policy = ?, calendar_id = ?, calendar_policy = ?, condition = ?,
+			skip_unchanged = ?, retry_policy = ?, run_timeout_seconds = ?, start_date = ?, end_date = ?,
+			max_occurrences = ?, spread_minutes = ?, format = ?, variables = ?, recipients = ?,
 			email_subject = ?, email_body = ?, template_id = ?, enabled = ?,
 			next_run_at = ?, updated_at = ?
 		WHERE id = ? AND org_id = ?`,
-		schedule.Name, schedule.DashboardUID, schedule.DashboardTitle, schedule.PanelIDs,
+		schedule.Name, schedule.DashboardUID, schedule.DashboardTitle, schedule.PanelIDs, schedule.PanelLayout,
 		schedule.RangeFrom, schedule.RangeTo, schedule.IntervalType, schedule.CronExpr,
-		schedule.Timezone, schedule.Format, schedule.Variables, schedule.Recipients,
+		schedule.TimeOfDay, schedule.DayOfWeek, schedule.DayOfMonth, schedule.BusinessDay,
+		schedule.Timezone, schedule.MisfirePolicy, schedule.CalendarID, schedule.CalendarPolicy, schedule.Condition,
+		schedule.SkipUnchanged, schedule.RetryPolicy, schedule.RunTimeoutSeconds, utcTime(schedule.StartDate),
+		utcTime(schedule.EndDate), schedule.MaxOccurrences, schedule.SpreadMinutes, schedule.Format, schedule.Variables,
+		schedule.Recipients,
 		schedule.EmailSubject, schedule.EmailBody, schedule.TemplateID, schedule.Enabled,
-		schedule.NextRunAt, schedule.UpdatedAt, schedule.ID, schedule.OrgID,
+		utcTime(schedule.NextRunAt), schedule.UpdatedAt, schedule.ID, schedule.OrgID,
 	)
 	return err
 }
 
-// DeleteSchedule deletes a schedule
+// DeleteSchedule deletes a schedule with its trigger tokens and backfills. Its queued runs are
+// cancelled and cancellation is requested for the running ones, so no work is left behind for a
+// schedule that no longer exists.
 func (s *Store) DeleteSchedule(orgID, id int64) error {
-	_, err := s.db.Exec("DELETE FROM schedules WHERE id = ? AND org_id = ?", id, orgID)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(`
+		UPDATE runs SET status = 'cancelled', finished_at = ?, error_text = 'Schedule deleted before it started'
+		WHERE id IN (SELECT run_id FROM run_queue WHERE schedule_id = ? AND org_id = ? AND status = 'queued')`,
+		now, id, orgID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE run_queue SET status = 'cancelled', finished_at = ?
+		WHERE schedule_id = ? AND org_id = ? AND status = 'queued'`,
+		now, id, orgID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE run_queue SET cancel_requested = 1
+		WHERE schedule_id = ? AND org_id = ? AND status = 'claimed'`,
+		id, orgID,
+	); err != nil {
+		return err
+	}
+
+	// Foreign keys are not enforced, so the schedule's trigger tokens and backfills go explicitly
+	for _, table := range []string{"trigger_tokens", "backfills"} {
+		if _, err := tx.Exec(
+			fmt.Sprintf("DELETE FROM %s WHERE schedule_id = ? AND org_id = ?", table), id, orgID,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM schedules WHERE id = ? AND org_id = ?", id, orgID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateScheduleNextRun sets the next run time of a schedule without touching its other fields
+func (s *Store) UpdateScheduleNextRun(id int64, nextRunAt time.Time) error {
+	_, err := s.db.Exec("UPDATE schedules SET next_run_at = ? WHERE id = ?", nextRunAt.UTC(), id)
+	return err
+}
+
+// UpdateScheduleLastRun records when a schedule last ran without touching its other fields
+func (s *Store) UpdateScheduleLastRun(id int64, lastRunAt time.Time) error {
+	_, err := s.db.Exec("UPDATE schedules SET last_run_at = ? WHERE id = ?", lastRunAt.UTC(), id)
 	return err
 }
 
 // CreateRun creates a new run record
 func (s *Store) CreateRun(run *model.Run) error {
+	return insertRun(s.db, run)
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertRun inserts a run record using the given connection or transaction.
+// It returns ErrDuplicateOccurrence when a run for the same occurrence key already exists.
+func insertRun(db execer, run *model.Run) error {
 	run.CreatedAt = time.Now()
 
-	result, err := s.db.Exec(`
-		INSERT INTO runs (schedule_id, org_id, started_at, status, created_at)
-		VALUES (?, ?, ?, ?, ?)`,
-		run.ScheduleID, run.OrgID, run.StartedAt, run.Status, run.CreatedAt,
+	result, err := db.Exec(`
+		INSERT INTO runs (schedule_id, org_id, started_at, status, occurrence_key, scheduled_for,
+			range_from, range_to, not_before, backfill_id, trigger_id, trigger_source, variables, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (occurrence_key) DO NOTHING`,
+		run.ScheduleID, run.OrgID, run.StartedAt, run.Status, nullString(run.OccurrenceKey), utcTime(run.ScheduledFor),
+		nullString(run.RangeFrom), nullString(run.RangeTo), utcTime(run.NotBefore), run.BackfillID, run.TriggerID,
+		nullString(run.TriggerSource), run.Variables, run.CreatedAt,
 	)
 	if err != nil {
 		return err
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrDuplicateOccurrence
+	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
@@ -256,31 +603,24 @@ func (s *Store) CreateRun(run *model.Run) error {
 func (s *Store) UpdateRun(run *model.Run) error {
 	_, err := s.db.Exec(`
 		UPDATE runs SET
-			finished_at = ?, status = ?, error_text = ?, artifact_path = ?,
-			rendered_pages = ?, bytes = ?, checksum = ?
+			started_at = ?, finished_at = ?, status = ?, error_text = ?, artifact_path = ?,
+			rendered_pages = ?, bytes = ?, checksum = ?, image_hash = ?, range_from = ?, range_to = ?,
+			condition_value = ?, render_wait_ms = ?
 		WHERE id = ?`,
-		run.FinishedAt, run.Status, run.ErrorText, run.ArtifactPath,
-		run.RenderedPages, run.Bytes, run.Checksum, run.ID,
+		run.StartedAt, run.FinishedAt, run.Status, run.ErrorText, run.ArtifactPath,
+		run.RenderedPages, run.Bytes, run.Checksum, nullString(run.ImageHash), nullString(run.RangeFrom),
+		nullString(run.RangeTo), run.ConditionValue, run.RenderWaitMS, run.ID,
 	)
 	return err
 }
 
 // GetRun retrieves a run by ID
 func (s *Store) GetRun(orgID, id int64) (*model.Run, error) {
-	run := &model.Run{}
-	var finishedAt sql.NullTime
-	var errorText, artifactPath, checksum sql.NullString
-
-	err := s.db.QueryRow(`
-		SELECT id, schedule_id, org_id, started_at, finished_at, status, error_text,
-		       artifact_path, rendered_pages, bytes, checksum, created_at
+	run, err := scanRun(s.db.QueryRow(`
+		SELECT `+runColumns+`
 		FROM runs WHERE id = ? AND org_id = ?`,
 		id, orgID,
-	).Scan(
-		&run.ID, &run.ScheduleID, &run.OrgID, &run.StartedAt, &finishedAt,
-		&run.Status, &errorText, &artifactPath, &run.RenderedPages,
-		&run.Bytes, &checksum, &run.CreatedAt,
-	)
+	))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("run not found")
 	}
@@ -288,28 +628,13 @@ func (s *Store) GetRun(orgID, id int64) (*model.Run, error) {
 		return nil, err
 	}
 
-	// Convert nullable fields
-	if finishedAt.Valid {
-		run.FinishedAt = &finishedAt.Time
-	}
-	if errorText.Valid {
-		run.ErrorText = errorText.String
-	}
-	if artifactPath.Valid {
-		run.ArtifactPath = artifactPath.String
-	}
-	if checksum.Valid {
-		run.Checksum = checksum.String
-	}
-
 	return run, nil
 }
 
 // ListRuns retrieves runs for a schedule
 func (s *Store) ListRuns(orgID, scheduleID int64) ([]*model.Run, error) {
 	rows, err := s.db.Query(`
-		SELECT id, schedule_id, org_id, started_at, finished_at, status, error_text,
-		       artifact_path, rendered_pages, bytes, checksum, created_at
+		SELECT `+runColumns+`
 		FROM runs WHERE schedule_id = ? AND org_id = ? ORDER BY started_at DESC LIMIT 50`,
 		scheduleID, orgID,
 	)
@@ -320,49 +645,99 @@ func (s *Store) ListRuns(orgID, scheduleID int64) ([]*model.Run, error) {
 
 	runs := make([]*model.Run, 0)
 	for rows.Next() {
-		run := &model.Run{}
-		var finishedAt sql.NullTime
-		var errorText, artifactPath, checksum sql.NullString
-
-		err := rows.Scan(
-			&run.ID, &run.ScheduleID, &run.OrgID, &run.StartedAt, &finishedAt,
-			&run.Status, &errorText, &artifactPath, &run.RenderedPages,
-			&run.Bytes, &checksum, &run.CreatedAt,
-		)
+		run, err := scanRun(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		// Convert nullable fields
-		if finishedAt.Valid {
-			run.FinishedAt = &finishedAt.Time
-		}
-		if errorText.Valid {
-			run.ErrorText = errorText.String
-		}
-		if artifactPath.Valid {
-			run.ArtifactPath = artifactPath.String
-		}
-		if checksum.Valid {
-			run.Checksum = checksum.String
-		}
-
 		runs = append(runs, run)
 	}
 
 	return runs, nil
 }
 
+// GetLastCompletedRun returns the most recent completed run of a schedule other than excludeRunID,
+// or nil when the schedule has none. Backfilled reports of past periods are left out.
+func (s *Store) GetLastCompletedRun(scheduleID, excludeRunID int64) (*model.Run, error) {
+	run, err := scanRun(s.db.QueryRow(`
+		SELECT `+runColumns+`
+		FROM runs WHERE schedule_id = ? AND id != ? AND status = 'completed' AND backfill_id IS NULL
+		ORDER BY started_at DESC, id DESC LIMIT 1`,
+		scheduleID, excludeRunID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// runColumns is the column list shared by all run queries (order matches scanRun)
+const runColumns = `id, schedule_id, org_id, started_at, finished_at, status, error_text,
+		       artifact_path, rendered_pages, bytes, checksum, image_hash, occurrence_key, scheduled_for,
+		       range_from, range_to, condition_value, not_before, backfill_id, trigger_id, trigger_source, variables,
+		       render_wait_ms, created_at`
+
+// scanRun scans a row selected with runColumns
+func scanRun(row rowScanner) (*model.Run, error) {
+	run := &model.Run{}
+	var finishedAt, scheduledFor, notBefore sql.NullTime
+	var errorText, artifactPath, checksum, imageHash, occurrenceKey, rangeFrom, rangeTo, triggerSource sql.NullString
+
+	err := row.Scan(
+		&run.ID, &run.ScheduleID, &run.OrgID, &run.StartedAt, &finishedAt,
+		&run.Status, &errorText, &artifactPath, &run.RenderedPages,
+		&run.Bytes, &checksum, &imageHash, &occurrenceKey, &scheduledFor, &rangeFrom, &rangeTo, &run.ConditionValue,
+		&notBefore, &run.BackfillID, &run.TriggerID, &triggerSource, &run.Variables,
+		&run.RenderWaitMS, &run.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert nullable fields
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	if errorText.Valid {
+		run.ErrorText = errorText.String
+	}
+	if artifactPath.Valid {
+		run.ArtifactPath = artifactPath.String
+	}
+	if checksum.Valid {
+		run.Checksum = checksum.String
+	}
+	if occurrenceKey.Valid {
+		run.OccurrenceKey = occurrenceKey.String
+	}
+	if scheduledFor.Valid {
+		run.ScheduledFor = &scheduledFor.Time
+	}
+	if notBefore.Valid {
+		run.NotBefore = &notBefore.Time
+	}
+	run.ImageHash = imageHash.String
+	run.RangeFrom = rangeFrom.String
+	run.RangeTo = rangeTo.String
+	run.TriggerSource = triggerSource.String
+
+	return run, nil
+}
+
 // GetSettings retrieves settings for an organization
 func (s *Store) GetSettings(orgID int64) (*model.Settings, error) {
 	settings := &model.Settings{}
 	err := s.db.QueryRow(`
-		SELECT id, org_id, use_grafana_smtp, smtp_config, renderer_config, limits, created_at, updated_at
+		SELECT id, org_id, use_grafana_smtp, smtp_config, renderer_config, limits, retry_policy,
+		       created_at, updated_at
 		FROM settings WHERE org_id = ?`,
 		orgID,
 	).Scan(
 		&settings.ID, &settings.OrgID, &settings.UseGrafanaSMTP, &settings.SMTPConfig,
-		&settings.RendererConfig, &settings.Limits, &settings.CreatedAt, &settings.UpdatedAt,
+		&settings.RendererConfig, &settings.Limits, &settings.RetryPolicy, &settings.CreatedAt, &settings.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -383,10 +758,11 @@ func (s *Store) UpsertSettings(settings *model.Settings) error {
 	if existing == nil {
 		settings.CreatedAt = now
 		result, err := s.db.Exec(`
-			INSERT INTO settings (org_id, use_grafana_smtp, smtp_config, renderer_config, limits, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			INSERT INTO settings (org_id, use_grafana_smtp, smtp_config, renderer_config, limits, retry_policy,
+				created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 			settings.OrgID, settings.UseGrafanaSMTP, settings.SMTPConfig, settings.RendererConfig,
-			settings.Limits, settings.CreatedAt, settings.UpdatedAt,
+			settings.Limits, settings.RetryPolicy, settings.CreatedAt, settings.UpdatedAt,
 		)
 		if err != nil {
 			return err
@@ -396,10 +772,11 @@ func (s *Store) UpsertSettings(settings *model.Settings) error {
 	} else {
 		_, err := s.db.Exec(`
 			UPDATE settings SET
-				use_grafana_smtp = ?, smtp_config = ?, renderer_config = ?, limits = ?, updated_at = ?
+				use_grafana_smtp = ?, smtp_config = ?, renderer_config = ?, limits = ?, retry_policy = ?,
+				updated_at = ?
 			WHERE org_id = ?`,
 			settings.UseGrafanaSMTP, settings.SMTPConfig, settings.RendererConfig,
-			settings.Limits, settings.UpdatedAt, settings.OrgID,
+			settings.Limits, settings.RetryPolicy, settings.UpdatedAt, settings.OrgID,
 		)
 		return err
 	}
@@ -407,16 +784,45 @@ func (s *Store) UpsertSettings(settings *model.Settings) error {
 	return nil
 }
 
-// GetDueSchedules retrieves schedules that are due to run
+// ListNextRunTimes returns the next run time of every enabled schedule that has occurrences left,
+// by schedule ID. Schedules that were never planned map to the zero time, i.e. they are due.
+func (s *Store) ListNextRunTimes() (map[int64]time.Time, error) {
+	rows, err := s.db.Query(`
+		SELECT id, next_run_at FROM schedules
+		WHERE enabled = 1 AND (max_occurrences = 0 OR occurrence_count < max_occurrences)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var nextRunAt *time.Time
+		if err := rows.Scan(&id, &nextRunAt); err != nil {
+			return nil, err
+		}
+		times[id] = time.Time{}
+		if nextRunAt != nil {
+			times[id] = *nextRunAt
+		}
+	}
+	return times, rows.Err()
+}
+
+// GetDueSchedules retrieves schedules that are due to run. Next run times are compared with the
+// current time at full precision, so a schedule is due the instant its timer fires.
 func (s *Store) GetDueSchedules() ([]*model.Schedule, error) {
+	now := time.Now().UTC()
 	rows, err := s.db.Query(`
-		SELECT id, org_id, name, dashboard_uid, dashboard_title, panel_ids, range_from, range_to,
-		       interval_type, cron_expr, timezone, format, variables, recipients,
-		       email_subject, email_body, template_id, enabled, last_run_at, next_run_at,
-		       owner_user_id, created_at, updated_at
+		SELECT `+scheduleColumns+`
 		FROM schedules
-		WHERE enabled = 1 AND (next_run_at IS NULL OR next_run_at <= datetime('now'))
+		WHERE enabled = 1 AND (next_run_at IS NULL OR next_run_at <= ?)
+		  AND (start_date IS NULL OR start_date <= ?)
+		  AND (max_occurrences = 0 OR occurrence_count < max_occurrences)
 		ORDER BY next_run_at ASC`,
+		now, now,
 	)
 	if err != nil {
 		return nil, err
@@ -425,15 +831,7 @@ func (s *Store) GetDueSchedules() ([]*model.Schedule, error) {
 
 	schedules := make([]*model.Schedule, 0)
 	for rows.Next() {
-		schedule := &model.Schedule{}
-		err := rows.Scan(
-			&schedule.ID, &schedule.OrgID, &schedule.Name, &schedule.DashboardUID,
-			&schedule.DashboardTitle, &schedule.PanelIDs, &schedule.RangeFrom, &schedule.RangeTo,
-			&schedule.IntervalType, &schedule.CronExpr, &schedule.Timezone, &schedule.Format,
-			&schedule.Variables, &schedule.Recipients, &schedule.EmailSubject, &schedule.EmailBody,
-			&schedule.TemplateID, &schedule.Enabled, &schedule.LastRunAt, &schedule.NextRunAt,
-			&schedule.OwnerUserID, &schedule.CreatedAt, &schedule.UpdatedAt,
-		)
+		schedule, err := scanSchedule(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,126 @@
+package store_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/cron"
+	"github.com/yourusername/sheduled-reports-app/pkg/store"
+)
+
+func TestMigratePresetTimes(t *testing.T) {
+	// A database created before presets had a time of day
+	path := filepath.Join(t.TempDir(), "reporting.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		org_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		dashboard_uid TEXT NOT NULL,
+		dashboard_title TEXT,
+		panel_ids TEXT,
+		range_from TEXT NOT NULL,
+		range_to TEXT NOT NULL,
+		interval_type TEXT NOT NULL,
+		cron_expr TEXT,
+		timezone TEXT NOT NULL,
+		format TEXT NOT NULL,
+		variables TEXT,
+		recipients TEXT NOT NULL,
+		email_subject TEXT NOT NULL,
+		email_body TEXT NOT NULL,
+		template_id INTEGER,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		last_run_at DATETIME,
+		next_run_at DATETIME,
+		owner_user_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		t.Fatalf("creating the old schema: %v", err)
+	}
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	nextRun := time.Date(2025, 3, 14, 14, 30, 0, 0, berlin) // A Friday
+	updated := time.Date(2025, 1, 9, 7, 15, 0, 0, time.UTC) // A Thursday
+	legacy := []struct {
+		interval  string
+		nextRunAt *time.Time
+	}{
+		{interval: "daily", nextRunAt: &nextRun},
+		{interval: "weekly", nextRunAt: &nextRun},
+		{interval: "monthly", nextRunAt: &nextRun},
+		{interval: "weekly"},
+		{interval: "cron"},
+	}
+	for _, schedule := range legacy {
+		var nextRunAt interface{}
+		if schedule.nextRunAt != nil {
+			nextRunAt = schedule.nextRunAt.UTC()
+		}
+		timezone := "Europe/Berlin"
+		if schedule.nextRunAt == nil {
+			timezone = "UTC"
+		}
+		if _, err := db.Exec(`
+			INSERT INTO schedules (org_id, name, dashboard_uid, dashboard_title, range_from, range_to, interval_type, cron_expr,
+				timezone, format, recipients, email_subject, email_body, owner_user_id, next_run_at, updated_at)
+			VALUES (1, 'legacy', 'abc123', '', 'now-7d', 'now', ?, '0 9 * * *', ?, 'pdf', '{}', '', '', 1, ?, ?)`,
+			schedule.interval, timezone, nextRunAt, updated,
+		); err != nil {
+			t.Fatalf("inserting a legacy schedule: %v", err)
+		}
+	}
+	db.Close()
+
+	st, err := store.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer st.Close()
+
+	tests := []struct {
+		id         int64
+		timeOfDay  string
+		dayOfWeek  int
+		dayOfMonth int
+	}{
+		{id: 1, timeOfDay: "14:30", dayOfWeek: 5, dayOfMonth: 14},
+		{id: 2, timeOfDay: "14:30", dayOfWeek: 5, dayOfMonth: 14},
+		{id: 3, timeOfDay: "14:30", dayOfWeek: 5, dayOfMonth: 14},
+		{id: 4, timeOfDay: "07:15", dayOfWeek: 4, dayOfMonth: 9}, // No next run: the time of its last update
+		{id: 5, timeOfDay: "", dayOfWeek: 0, dayOfMonth: 1},      // Cron schedules keep their expression
+	}
+	for _, tt := range tests {
+		schedule, err := st.GetSchedule(1, tt.id)
+		if err != nil {
+			t.Fatalf("GetSchedule(%d) error = %v", tt.id, err)
+		}
+		if schedule.TimeOfDay != tt.timeOfDay || schedule.DayOfWeek != tt.dayOfWeek || schedule.DayOfMonth != tt.dayOfMonth {
+			t.Errorf("%s schedule %d = %q, weekday %d, day %d; want %q, weekday %d, day %d", schedule.IntervalType, tt.id,
+				schedule.TimeOfDay, schedule.DayOfWeek, schedule.DayOfMonth, tt.timeOfDay, tt.dayOfWeek, tt.dayOfMonth)
+		}
+	}
+
+	// The first run planned after the upgrade keeps the wall-clock time reports were sent at
+	scheduler := cron.NewScheduler(st, "", t.TempDir(), 1)
+	for _, id := range []int64{1, 2} {
+		schedule, err := st.GetSchedule(1, id)
+		if err != nil {
+			t.Fatalf("GetSchedule(%d) error = %v", id, err)
+		}
+		next := scheduler.CalculateNextRun(schedule)
+		if next == nil {
+			t.Fatalf("CalculateNextRun(%s) = nil", schedule.IntervalType)
+		}
+		local := next.In(berlin)
+		if local.Format("15:04") != "14:30" || (schedule.IntervalType == "weekly" && local.Weekday() != time.Friday) {
+			t.Errorf("CalculateNextRun(%s) = %s, want 14:30 (on a Friday for weekly)", schedule.IntervalType, local.Format(time.RFC1123))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+// ErrTriggerNotFound is returned for trigger tokens that do not exist or were revoked
+var ErrTriggerNotFound = errors.New("trigger token not found")
+
+// triggerTokenColumns is the column list shared by all trigger token queries (order matches scanTriggerToken)
+const triggerTokenColumns = `id, schedule_id, org_id, name, created_at, last_used_at`
+
+// scanTriggerToken scans a row selected with triggerTokenColumns
+func scanTriggerToken(row rowScanner) (*model.TriggerToken, error) {
+	token := &model.TriggerToken{}
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.ScheduleID, &token.OrgID, &token.Name, &token.CreatedAt, &lastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	return token, nil
+}
+
+// hashTriggerToken returns the stored form of a trigger token
+func hashTriggerToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateTriggerToken generates a new trigger token for a schedule. The token is set on the returned
+// struct only; the database keeps its hash.
+func (s *Store) CreateTriggerToken(token *model.TriggerToken) error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	token.Token = hex.EncodeToString(secret)
+	token.CreatedAt = time.Now()
+
+	result, err := s.db.Exec(`
+		INSERT INTO trigger_tokens (schedule_id, org_id, name, token_hash, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		token.ScheduleID, token.OrgID, token.Name, hashTriggerToken(token.Token), token.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	token.ID, err = result.LastInsertId()
+	return err
+}
+
+// ListTriggerTokens retrieves the trigger tokens of a schedule, without their secrets
+func (s *Store) ListTriggerTokens(orgID, scheduleID int64) ([]*model.TriggerToken, error) {
+	rows, err := s.db.Query(`
+		SELECT `+triggerTokenColumns+`
+		FROM trigger_tokens WHERE schedule_id = ? AND org_id = ? ORDER BY created_at ASC, id ASC`,
+		scheduleID, orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*model.TriggerToken, 0)
+	for rows.Next() {
+		token, err := scanTriggerToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteTriggerToken revokes a trigger token of a schedule
+func (s *Store) DeleteTriggerToken(orgID, scheduleID, id int64) error {
+	result, err := s.db.Exec(
+		"DELETE FROM trigger_tokens WHERE id = ? AND schedule_id = ? AND org_id = ?",
+		id, scheduleID, orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrTriggerNotFound
+	}
+	return nil
+}
+
+// FindTriggerToken looks up the token a trigger call presents in the caller's org. Tokens of deleted
+// schedules are not found.
+func (s *Store) FindTriggerToken(orgID int64, secret string) (*model.TriggerToken, error) {
+	token, err := scanTriggerToken(s.db.QueryRow(`
+		SELECT `+triggerTokenColumns+`
+		FROM trigger_tokens
+		WHERE token_hash = ? AND org_id = ?
+		  AND EXISTS (SELECT 1 FROM schedules WHERE schedules.id = trigger_tokens.schedule_id)`,
+		hashTriggerToken(secret), orgID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrTriggerNotFound
+	}
+	return token, err
+}
+
+// MarkTriggerTokenUsed records the use of a token by a trigger call that was accepted
+func (s *Store) MarkTriggerTokenUsed(token *model.TriggerToken) error {
+	now := time.Now().UTC()
+	if _, err := s.db.Exec("UPDATE trigger_tokens SET last_used_at = ? WHERE id = ?", now, token.ID); err != nil {
+		return err
+	}
+	token.LastUsedAt = &now
+	return nil
+}
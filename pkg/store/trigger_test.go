@@ -0,0 +1,74 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/sheduled-reports-app/pkg/model"
+)
+
+func TestTriggerTokens(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	token := &model.TriggerToken{ScheduleID: schedule.ID, OrgID: schedule.OrgID, Name: "etl"}
+	if err := st.CreateTriggerToken(token); err != nil {
+		t.Fatalf("CreateTriggerToken() error = %v", err)
+	}
+	if len(token.Token) != 64 {
+		t.Fatalf("CreateTriggerToken() token = %q, want 64 hex characters", token.Token)
+	}
+
+	found, err := st.FindTriggerToken(schedule.OrgID, token.Token)
+	if err != nil || found.ID != token.ID || found.LastUsedAt != nil {
+		t.Fatalf("FindTriggerToken() = %+v, %v; want token %d not used yet", found, err, token.ID)
+	}
+	if _, err := st.FindTriggerToken(schedule.OrgID+1, token.Token); !errors.Is(err, ErrTriggerNotFound) {
+		t.Errorf("FindTriggerToken() from another org error = %v, want ErrTriggerNotFound", err)
+	}
+
+	// Only accepted calls are recorded
+	if err := st.MarkTriggerTokenUsed(found); err != nil {
+		t.Fatalf("MarkTriggerTokenUsed() error = %v", err)
+	}
+	if used, err := st.FindTriggerToken(schedule.OrgID, token.Token); err != nil || used.LastUsedAt == nil {
+		t.Errorf("FindTriggerToken() after use = %+v, %v; want last use recorded", used, err)
+	}
+
+	tokens, err := st.ListTriggerTokens(schedule.OrgID, schedule.ID)
+	if err != nil || len(tokens) != 1 || tokens[0].Token != "" {
+		t.Fatalf("ListTriggerTokens() = %+v, %v; want one token without its secret", tokens, err)
+	}
+
+	if err := st.DeleteTriggerToken(schedule.OrgID, schedule.ID, token.ID); err != nil {
+		t.Fatalf("DeleteTriggerToken() error = %v", err)
+	}
+	if _, err := st.FindTriggerToken(schedule.OrgID, token.Token); !errors.Is(err, ErrTriggerNotFound) {
+		t.Errorf("FindTriggerToken() after revoking error = %v, want ErrTriggerNotFound", err)
+	}
+}
+
+func TestDeleteSchedule_RemovesTokensAndBackfills(t *testing.T) {
+	st := newTestStore(t)
+	schedule := createTestSchedule(t, st)
+
+	token := &model.TriggerToken{ScheduleID: schedule.ID, OrgID: schedule.OrgID, Name: "etl"}
+	if err := st.CreateTriggerToken(token); err != nil {
+		t.Fatalf("CreateTriggerToken() error = %v", err)
+	}
+	backfill := &model.Backfill{ScheduleID: schedule.ID, OrgID: schedule.OrgID, From: time.Now().Add(-48 * time.Hour), To: time.Now()}
+	if err := st.CreateBackfill(backfill, []*model.Run{{ScheduleID: schedule.ID, OrgID: schedule.OrgID}}); err != nil {
+		t.Fatalf("CreateBackfill() error = %v", err)
+	}
+
+	if err := st.DeleteSchedule(schedule.OrgID, schedule.ID); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	for _, table := range []string{"trigger_tokens", "backfills"} {
+		var count int
+		if err := st.db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE schedule_id = ?", schedule.ID).Scan(&count); err != nil || count != 0 {
+			t.Errorf("%s rows of the deleted schedule = %d, %v; want none", table, count, err)
+		}
+	}
+}
@@ -7,8 +7,16 @@ import { ScheduleEditPage } from '../pages/Schedules/ScheduleEditPage';
 import { RunHistoryPage } from '../pages/RunHistory/RunHistoryPage';
 import { SettingsPage } from '../pages/Settings/SettingsPage';
 import { DocumentationPage } from '../pages/Documentation/DocumentationPage';
+import { CalendarsPage } from '../pages/Calendars/CalendarsPage';
 
-type Page = 'schedules' | 'schedule-new' | 'schedule-edit' | 'run-history' | 'settings' | 'documentation';
+type Page =
+  | 'schedules'
+  | 'schedule-new'
+  | 'schedule-edit'
+  | 'run-history'
+  | 'calendars'
+  | 'settings'
+  | 'documentation';
 
 export const App: React.FC<AppRootProps> = (props) => {
   const [currentPage, setCurrentPage] = useState<Page>('schedules');
@@ -34,6 +42,8 @@ export const App: React.FC<AppRootProps> = (props) => {
       setCurrentPage('schedule-edit');
     } else if (path.includes('history')) {
       setCurrentPage('run-history');
+    } else if (path.includes('calendars')) {
+      setCurrentPage('calendars');
     } else if (path.includes('documentation')) {
       setCurrentPage('documentation');
     } else {
@@ -60,6 +70,9 @@ export const App: React.FC<AppRootProps> = (props) => {
       case 'run-history':
         url = `${baseUrl}/history?scheduleId=${scheduleId}`;
         break;
+      case 'calendars':
+        url = `${baseUrl}/calendars`;
+        break;
       case 'settings':
         url = `${baseUrl}/settings`;
         break;
@@ -81,6 +94,8 @@ export const App: React.FC<AppRootProps> = (props) => {
         return <ScheduleEditPage onNavigate={navigate} isNew={false} scheduleId={selectedScheduleId} />;
       case 'run-history':
         return <RunHistoryPage onNavigate={navigate} scheduleId={selectedScheduleId} />;
+      case 'calendars':
+        return <CalendarsPage onNavigate={navigate} />;
       case 'settings':
         return <SettingsPage onNavigate={navigate} />;
       case 'documentation':
@@ -36,7 +36,7 @@ export const CronEditor: React.FC<CronEditorProps> = ({ value, onChange }) => {
           ))}
         </HorizontalGroup>
       </Field>
-      <Field label="Custom Expression" description="Format: minute hour day month weekday">
+      <Field label="Custom Expression" description="Format: [second] minute hour day month weekday (the seconds field is optional)">
         <Input value={value} onChange={(e) => onChange(e.currentTarget.value)} placeholder="0 8 * * 1" />
       </Field>
     </div>
@@ -0,0 +1,65 @@
+import React from 'react';
+import { Field, Input } from '@grafana/ui';
+import { css } from '@emotion/css';
+import { GrafanaTheme2 } from '@grafana/data';
+import { useStyles2 } from '@grafana/ui';
+import { RetryPolicy } from '../types/types';
+
+interface RetryPolicyEditorProps {
+  value: RetryPolicy;
+  onChange: (value: RetryPolicy) => void;
+}
+
+export const RetryPolicyEditor: React.FC<RetryPolicyEditorProps> = ({ value, onChange }) => {
+  const styles = useStyles2(getStyles);
+
+  const update = (field: keyof RetryPolicy, input: string) => {
+    onChange({ ...value, [field]: field === 'jitter' ? parseFloat(input) || 0 : parseInt(input, 10) || 0 });
+  };
+
+  return (
+    <div className={styles.row}>
+      <Field label="Max Attempts" description="Including the first attempt (1-10)">
+        <Input
+          type="number"
+          min={1}
+          max={10}
+          value={value.max_attempts || 3}
+          onChange={(e) => update('max_attempts', e.currentTarget.value)}
+        />
+      </Field>
+      <Field label="Backoff Base (ms)" description="Delay before the first retry, doubled for each further retry">
+        <Input
+          type="number"
+          value={value.backoff_base_ms || 1000}
+          onChange={(e) => update('backoff_base_ms', e.currentTarget.value)}
+        />
+      </Field>
+      <Field label="Backoff Cap (ms)" description="Longest delay between attempts">
+        <Input
+          type="number"
+          value={value.backoff_max_ms || 30000}
+          onChange={(e) => update('backoff_max_ms', e.currentTarget.value)}
+        />
+      </Field>
+      <Field label="Jitter" description="Randomizes each delay by this fraction (0-1)">
+        <Input
+          type="number"
+          step={0.1}
+          min={0}
+          max={1}
+          value={value.jitter || 0}
+          onChange={(e) => update('jitter', e.currentTarget.value)}
+        />
+      </Field>
+    </div>
+  );
+};
+
+const getStyles = (theme: GrafanaTheme2) => ({
+  row: css`
+    display: flex;
+    gap: ${theme.spacing(2)};
+    flex-wrap: wrap;
+  `,
+});
@@ -0,0 +1,281 @@
+import React, { useState, useEffect } from 'react';
+import { css } from '@emotion/css';
+import { GrafanaTheme2 } from '@grafana/data';
+import { useStyles2, Button, Field, Input, TextArea, FieldSet, LoadingPlaceholder } from '@grafana/ui';
+import { Calendar, CalendarDay, BlackoutWindow } from '../../types/types';
+import { getBackendSrv, getAppEvents } from '@grafana/runtime';
+import { AppEvents } from '@grafana/data';
+
+interface CalendarsPageProps {
+  onNavigate: (page: string) => void;
+}
+
+const apiBase = '/api/plugins/sheduled-reports-app/resources/api/calendars';
+
+// Holidays are edited as one "YYYY-MM-DD Name" line per day
+const formatHolidays = (days: CalendarDay[]) => days.map((d) => `${d.date} ${d.name || ''}`.trim()).join('\n');
+
+const parseHolidays = (text: string): CalendarDay[] =>
+  text
+    .split('\n')
+    .map((line) => line.trim())
+    .filter((line) => line !== '')
+    .map((line) => {
+      const [date, ...name] = line.split(/\s+/);
+      return { date, name: name.join(' ') };
+    });
+
+// Blackout windows are edited as one "YYYY-MM-DD..YYYY-MM-DD Name" line per window
+const formatBlackouts = (windows: BlackoutWindow[]) =>
+  windows.map((w) => `${w.start}..${w.end} ${w.name || ''}`.trim()).join('\n');
+
+const parseBlackouts = (text: string): BlackoutWindow[] =>
+  text
+    .split('\n')
+    .map((line) => line.trim())
+    .filter((line) => line !== '')
+    .map((line) => {
+      const [range, ...name] = line.split(/\s+/);
+      const [start, end] = range.split('..');
+      return { start, end: end || start, name: name.join(' ') };
+    });
+
+export const CalendarsPage: React.FC<CalendarsPageProps> = () => {
+  const styles = useStyles2(getStyles);
+  const [calendars, setCalendars] = useState<Calendar[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [editing, setEditing] = useState<Partial<Calendar> | null>(null);
+  const [holidaysText, setHolidaysText] = useState('');
+  const [blackoutsText, setBlackoutsText] = useState('');
+
+  useEffect(() => {
+    loadCalendars();
+  }, []);
+
+  const loadCalendars = async () => {
+    try {
+      const response = await getBackendSrv().get(apiBase);
+      setCalendars(response.calendars || []);
+    } catch (error) {
+      console.error('Failed to load calendars:', error);
+      setCalendars([]);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const startEditing = (calendar: Partial<Calendar>) => {
+    setEditing(calendar);
+    setHolidaysText(formatHolidays(calendar.holidays || []));
+    setBlackoutsText(formatBlackouts(calendar.blackouts || []));
+  };
+
+  const handleSave = async () => {
+    const appEvents = getAppEvents();
+    const payload = {
+      ...editing,
+      holidays: parseHolidays(holidaysText),
+      blackouts: parseBlackouts(blackoutsText),
+    };
+
+    try {
+      if (editing?.id) {
+        await getBackendSrv().put(`${apiBase}/${editing.id}`, payload);
+      } else {
+        await getBackendSrv().post(apiBase, payload);
+      }
+      appEvents.publish({
+        type: AppEvents.alertSuccess.name,
+        payload: ['Calendar saved'],
+      });
+      setEditing(null);
+      loadCalendars();
+    } catch (error) {
+      console.error('Failed to save calendar:', error);
+      appEvents.publish({
+        type: AppEvents.alertError.name,
+        payload: ['Failed to save calendar'],
+      });
+    }
+  };
+
+  const handleDelete = async (id: number) => {
+    if (!confirm('Delete this calendar? Schedules using it will run on all days.')) {
+      return;
+    }
+    try {
+      await getBackendSrv().delete(`${apiBase}/${id}`);
+      loadCalendars();
+    } catch (error) {
+      console.error('Failed to delete calendar:', error);
+    }
+  };
+
+  const handleImport = async (calendar: Calendar, file: File) => {
+    const appEvents = getAppEvents();
+    try {
+      const content = await file.text();
+      const response = await getBackendSrv().post(`${apiBase}/${calendar.id}/import`, content, {
+        headers: { 'Content-Type': 'text/calendar' },
+      });
+      appEvents.publish({
+        type: AppEvents.alertSuccess.name,
+        payload: [`Imported ${response.added_holidays} holiday(s) and ${response.added_blackouts} blackout window(s)`],
+      });
+      loadCalendars();
+    } catch (error) {
+      console.error('Failed to import calendar:', error);
+      appEvents.publish({
+        type: AppEvents.alertError.name,
+        payload: ['Failed to import iCalendar file'],
+      });
+    }
+  };
+
+  if (loading) {
+    return <LoadingPlaceholder text="Loading calendars..." />;
+  }
+
+  if (editing) {
+    return (
+      <div className={styles.container}>
+        <h2>{editing.id ? 'Edit Calendar' : 'New Calendar'}</h2>
+
+        <FieldSet>
+          <Field label="Name" required>
+            <Input
+              value={editing.name || ''}
+              onChange={(e) => setEditing({ ...editing, name: e.currentTarget.value })}
+              placeholder="Public holidays"
+            />
+          </Field>
+
+          <Field label="Holidays" description="One day per line: YYYY-MM-DD followed by an optional name">
+            <TextArea
+              value={holidaysText}
+              onChange={(e) => setHolidaysText(e.currentTarget.value)}
+              rows={10}
+              placeholder="2025-12-25 Christmas Day"
+            />
+          </Field>
+
+          <Field
+            label="Blackout Windows"
+            description="One window per line: YYYY-MM-DD..YYYY-MM-DD (inclusive) followed by an optional name"
+          >
+            <TextArea
+              value={blackoutsText}
+              onChange={(e) => setBlackoutsText(e.currentTarget.value)}
+              rows={5}
+              placeholder="2025-03-28..2025-04-02 Quarter close"
+            />
+          </Field>
+        </FieldSet>
+
+        <div className={styles.actions}>
+          {/* @ts-ignore */}
+          <Button variant="primary" onClick={handleSave}>
+            Save
+          </Button>
+          {/* @ts-ignore */}
+          <Button variant="secondary" onClick={() => setEditing(null)}>
+            Cancel
+          </Button>
+        </div>
+      </div>
+    );
+  }
+
+  return (
+    <div className={styles.container}>
+      <div className={styles.header}>
+        <h2>Holiday Calendars</h2>
+        {/* @ts-ignore */}
+        <Button icon="plus" onClick={() => startEditing({ name: '', holidays: [], blackouts: [] })}>
+          New Calendar
+        </Button>
+      </div>
+
+      <p>
+        Schedules that reference a calendar skip, postpone or pull forward reports that fall on its holidays and
+        blackout windows. Import public holidays from an iCalendar (.ics) file.
+      </p>
+
+      {calendars.length === 0 ? (
+        <p>No calendars yet.</p>
+      ) : (
+        <table style={{ width: '100%', borderCollapse: 'collapse' }}>
+          <thead>
+            <tr>
+              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Name</th>
+              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Holidays</th>
+              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Blackout Windows</th>
+              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Actions</th>
+            </tr>
+          </thead>
+          <tbody>
+            {calendars.map((calendar) => (
+              <tr key={calendar.id}>
+                <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{calendar.name}</td>
+                <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{calendar.holidays.length}</td>
+                <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{calendar.blackouts.length}</td>
+                <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
+                  <div className={styles.actions}>
+                    {/* @ts-ignore */}
+                    <Button size="sm" variant="secondary" icon="edit" onClick={() => startEditing(calendar)}>
+                      Edit
+                    </Button>
+                    <label className={styles.importLabel}>
+                      Import .ics
+                      <input
+                        type="file"
+                        accept=".ics,text/calendar"
+                        style={{ display: 'none' }}
+                        onChange={(e) => {
+                          const file = e.currentTarget.files?.[0];
+                          if (file) {
+                            handleImport(calendar, file);
+                          }
+                          e.currentTarget.value = '';
+                        }}
+                      />
+                    </label>
+                    {/* @ts-ignore */}
+                    <Button size="sm" variant="destructive" icon="trash-alt" onClick={() => handleDelete(calendar.id)}>
+                      Delete
+                    </Button>
+                  </div>
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      )}
+    </div>
+  );
+};
+
+const getStyles = (theme: GrafanaTheme2) => ({
+  container: css`
+    padding: ${theme.spacing(2)};
+    max-width: 1200px;
+  `,
+  header: css`
+    display: flex;
+    justify-content: space-between;
+    align-items: center;
+    margin-bottom: ${theme.spacing(2)};
+  `,
+  actions: css`
+    display: flex;
+    gap: ${theme.spacing(1)};
+    align-items: center;
+  `,
+  importLabel: css`
+    cursor: pointer;
+    padding: ${theme.spacing(0.5, 1)};
+    border: 1px solid ${theme.colors.border.medium};
+    border-radius: ${theme.shape.borderRadius()};
+    font-size: ${theme.typography.bodySmall.fontSize};
+  `,
+});
@@ -48,6 +48,10 @@ export const DocumentationPage: React.FC = () => {
         <ul>
           <li><strong>Name:</strong> A descriptive name for your schedule (e.g., "Daily Sales Report")</li>
           <li><strong>Dashboard:</strong> Select the dashboard to report</li>
+          <li>
+            <strong>Panels:</strong> Optionally render only some panels, each on its own, laid out one per page or in
+            a grid of four per page (PDF only)
+          </li>
           <li><strong>Format:</strong> Choose PDF or HTML output</li>
           <li><strong>Enabled:</strong> Enable or disable the schedule</li>
         </ul>
@@ -60,14 +64,95 @@ export const DocumentationPage: React.FC = () => {
 
         <h3>Schedule Intervals</h3>
         <ul>
-          <li><strong>Daily:</strong> Runs once per day at the time the schedule was created</li>
-          <li><strong>Weekly:</strong> Runs once per week</li>
-          <li><strong>Monthly:</strong> Runs once per month</li>
+          <li><strong>Daily:</strong> Runs once per day at the configured time of day (e.g., 08:00)</li>
+          <li><strong>Weekly:</strong> Runs once per week on the selected weekday and time (e.g., every Monday 07:30)</li>
+          <li><strong>Monthly:</strong> Runs once per month on the selected day, or the Nth business day when
+            &quot;Business Days&quot; is enabled (e.g., 1st business day of the month at 09:00)</li>
           <li><strong>Custom (Cron):</strong> Use cron expressions for precise scheduling</li>
         </ul>
+        <p>
+          All intervals, including cron expressions, are evaluated in the schedule&apos;s timezone, so a report
+          configured for 08:00 keeps firing at 08:00 local time across daylight saving time changes.
+        </p>
+
+        <h3>Missed Runs</h3>
+        <p>
+          When Grafana was down at a scheduled time, the &quot;Missed Runs&quot; policy decides what happens once it is back:
+        </p>
+        <ul>
+          <li><strong>Run latest missed occurrence</strong> (default): Sends one report for the most recent missed time</li>
+          <li><strong>Skip missed occurrences:</strong> Sends nothing and waits for the next scheduled time</li>
+          <li><strong>Run every missed occurrence:</strong> Sends one report per missed time (up to 50)</li>
+        </ul>
+        <p>
+          A run that starts more than 5 minutes after its scheduled time renders the time range as it was at the
+          scheduled time (e.g., &quot;now-1d/d&quot; still covers the day before the missed run), and Run History shows
+          which scheduled time each run fulfils.
+        </p>
+
+        <h3>Start, End and Max Occurrences</h3>
+        <p>
+          Limit a schedule to a campaign or a fixed number of reports with the optional Start, End and Max
+          Occurrences fields. When a schedule has no occurrences left it is disabled, shown as Ended, and its owner
+          is notified by email. Extend the end date or the occurrence limit to enable it again.
+        </p>
+        <p>
+          Click &quot;Preview Upcoming Runs&quot; to see when the schedule will fire next and which time range each
+          report will cover, taking the timezone, holiday calendar and these limits into account.
+        </p>
+
+        <h3>Spread</h3>
+        <p>
+          Schedules that share a fire time can be staggered across a spread window (set in Settings, or per
+          schedule). Each schedule starts at its own fixed offset within the window, still covers the time range of
+          its fire time, and must finish within its run timeout counted from the fire time.
+        </p>
+
+        <h3>Holiday Calendars</h3>
+        <p>
+          Reference a holiday calendar (see the Calendars page) to keep reports from going out on public holidays
+          or during blackout windows such as quarter-close freezes. Occurrences on a blocked day are skipped,
+          postponed to the next business day, or pulled forward to the previous business day, at the same time of
+          day. Calendars can be filled by hand or imported from an iCalendar (.ics) file.
+        </p>
+
+        <h3>Conditional Reports</h3>
+        <p>
+          Enable a condition to send a report only when it matters, e.g. when the error rate over the report&apos;s
+          time range exceeded 2%. The query (a datasource query model as JSON) runs just before rendering, its
+          series are reduced to one number (last, mean, min, max, sum or count) and compared with the threshold.
+          If the condition is not met or the query returns no data, the run is recorded as skipped together with
+          the evaluated value and no email is sent.
+        </p>
+
+        <h3>Skipping Unchanged Reports</h3>
+        <p>
+          The &quot;Unchanged Reports&quot; option avoids sending the same report twice. &quot;Identical&quot; compares
+          the checksum of the rendered dashboard with the last report sent; &quot;looks the same&quot; compares the
+          screenshots perceptually, so timestamps and other small changes are ignored. Skipped runs appear in Run
+          History as skipped_unchanged.
+        </p>
+
+        <h3>Retries</h3>
+        <p>
+          Failed runs are retried with exponential backoff according to the retry policy in Settings, which a
+          schedule can override (attempts, base delay, maximum delay and jitter). Permanent failures such as a deleted
+          dashboard, an invalid recipient or a message rejected by the mail server fail immediately; timeouts and
+          temporary SMTP errors are retried.
+        </p>
+
+        <h3>Run Deadline</h3>
+        <p>
+          Each run must finish rendering, PDF generation, storage and email delivery, including retries, within its
+          run timeout: the schedule&apos;s own value, otherwise the default in Settings (10 minutes). A run that takes
+          longer fails, and Run History shows which step ran out of time.
+        </p>
 
         <h3>Cron Expression Format</h3>
-        <p>Cron expressions use 5 fields: <code>minute hour day-of-month month day-of-week</code></p>
+        <p>
+          Cron expressions use 5 fields: <code>minute hour day-of-month month day-of-week</code>. For sub-minute
+          precision, add a leading seconds field: <code>second minute hour day-of-month month day-of-week</code>.
+        </p>
 
         <div className={styles.codeBlock}>
           <table>
@@ -102,6 +187,10 @@ export const DocumentationPage: React.FC = () => {
                 <td><code>0 8 * * 0</code></td>
                 <td>Every Sunday at 8:00 AM</td>
               </tr>
+              <tr>
+                <td><code>30 59 7 * * 1-5</code></td>
+                <td>Every weekday at 7:59:30 AM</td>
+              </tr>
             </tbody>
           </table>
         </div>
@@ -152,7 +241,33 @@ export const DocumentationPage: React.FC = () => {
           <li>File size</li>
           <li>Error messages (if failed)</li>
           <li>Download button for successful reports</li>
+          <li>Queue position of reports waiting to start</li>
+          <li>Cancel button for queued and running reports</li>
         </ul>
+        <p>
+          Reports started with Run now go ahead of scheduled reports in the queue, and one worker is kept free for
+          them, so they do not wait for a batch of scheduled reports to finish.
+        </p>
+        <h3>Triggering Reports from Other Systems</h3>
+        <p>
+          The Triggers section of a saved schedule creates tokens that let an external system, such as an ETL
+          pipeline, run the schedule as soon as its data is ready. The system sends a POST request to the trigger
+          URL with a Grafana service account token and may override the time range and dashboard variables of that
+          run. Run History shows which trigger queued each run.
+        </p>
+
+        <h3>Backfilling Past Reports</h3>
+        <p>
+          The Backfill section of a saved schedule generates the reports it would have produced between two past
+          dates, one per occurrence. Each report covers the time range of its own occurrence, so a monthly report
+          backfilled for the last year yields twelve reports, one per month. The reports are stored in Run History
+          and, when requested, emailed together as one bundle once all of them have finished.
+        </p>
+        <p>
+          Cancelling a running report stops rendering right away and records the run as cancelled. An email that the
+          mail server is already receiving may still arrive. Reports still running when the plugin shuts
+          down get 30 seconds to finish; the rest are recorded as interrupted and resume after the restart.
+        </p>
       </section>
 
       <section className={styles.section}>
@@ -196,18 +311,33 @@ export const DocumentationPage: React.FC = () => {
 
         <h3>Renderer Configuration</h3>
         <ul>
-          <li><strong>URL:</strong> Grafana Image Renderer service URL (default: http://renderer:8081/render)</li>
+          <li><strong>Backend:</strong> Chromium, wkhtmltopdf, or a remote Grafana Image Renderer service</li>
+          <li><strong>Image Renderer URL:</strong> Render endpoint of the Grafana Image Renderer service (e.g. http://renderer:8081/render)</li>
+          <li><strong>Renderer Token:</strong> Auth token the image renderer service expects; leave empty for its default</li>
           <li><strong>Timeout:</strong> Maximum time to wait for rendering (milliseconds)</li>
-          <li><strong>Delay:</strong> Wait time before capturing to allow queries to complete</li>
+          <li><strong>Network Idle:</strong> Chromium captures once no panel is loading and no request has been pending for this long (bounded by the timeout)</li>
+          <li><strong>Delay:</strong> wkhtmltopdf only: wait time before capturing to allow queries to complete</li>
           <li><strong>Viewport:</strong> Browser viewport dimensions for rendering</li>
+          <li><strong>Panel Size:</strong> Size at which individually selected panels are rendered</li>
+          <li><strong>Full Page Capture:</strong> Scroll through long dashboards and capture their whole height (Chromium and image renderer); Chromium can also expand collapsed rows. PDF reports spread tall captures over several pages, breaking between rows and panels</li>
         </ul>
 
         <h3>Limits</h3>
         <ul>
           <li><strong>Max Recipients:</strong> Maximum number of email recipients per schedule</li>
           <li><strong>Max Attachment Size:</strong> Maximum report file size in MB</li>
-          <li><strong>Max Concurrent Renders:</strong> Number of reports that can render simultaneously</li>
+          <li>
+            <strong>Max Concurrent Renders:</strong> Number of reports of the organization that can render
+            simultaneously. When several organizations have reports waiting, the one with the fewest reports rendering
+            goes first.
+          </li>
           <li><strong>Retention Days:</strong> How long to keep report artifacts</li>
+          <li><strong>Run Timeout:</strong> Default deadline of a run, from rendering to email delivery</li>
+          <li><strong>Spread Window:</strong> Staggers schedules that share a fire time across this many minutes</li>
+          <li>
+            <strong>Worker Pool Size:</strong> Number of reports each Grafana instance renders at once, across all
+            organizations. Only shown in the main organization; changes apply without a restart.
+          </li>
         </ul>
       </section>
 
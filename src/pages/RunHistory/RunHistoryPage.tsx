@@ -78,6 +78,15 @@ export const RunHistoryPage: React.FC<RunHistoryPageProps> = ({ onNavigate, sche
     window.open(`${appSubUrl}/api/plugins/sheduled-reports-app/resources/api/runs/${runId}/artifact`, '_blank');
   };
 
+  const cancelRun = async (runId: number) => {
+    try {
+      await getBackendSrv().post(`/api/plugins/sheduled-reports-app/resources/api/runs/${runId}/cancel`);
+      loadRuns();
+    } catch (error) {
+      console.error('Failed to cancel run:', error);
+    }
+  };
+
   if (loading) {
     return <LoadingPlaceholder text="Loading run history..." />;
   }
@@ -108,12 +117,13 @@ export const RunHistoryPage: React.FC<RunHistoryPageProps> = ({ onNavigate, sche
               {!scheduleId && (
                 <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Schedule</th>
               )}
+              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Scheduled For</th>
               <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Started</th>
               <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Status</th>
               <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Duration</th>
               <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Pages</th>
               <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Size</th>
-              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Error</th>
+              <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Details</th>
               <th style={{ textAlign: 'left', padding: '8px', borderBottom: '2px solid #ddd' }}>Actions</th>
             </tr>
           </thead>
@@ -125,11 +135,14 @@ export const RunHistoryPage: React.FC<RunHistoryPageProps> = ({ onNavigate, sche
                   ? styles.statusSuccess
                   : status === 'failed'
                   ? styles.statusError
+                  : status.startsWith('skipped') || status === 'cancelled'
+                  ? styles.statusSkipped
                   : styles.statusPending;
 
               const duration = run.finished_at
                 ? `${((new Date(run.finished_at).getTime() - new Date(run.started_at).getTime()) / 1000).toFixed(1)}s`
                 : 'Running...';
+              const loadWait = run.render_wait_ms ? ` (${(run.render_wait_ms / 1000).toFixed(1)}s loading)` : '';
 
               const mb = run.bytes / 1024 / 1024;
               const size = mb > 1 ? `${mb.toFixed(2)} MB` : `${(run.bytes / 1024).toFixed(2)} KB`;
@@ -141,16 +154,31 @@ export const RunHistoryPage: React.FC<RunHistoryPageProps> = ({ onNavigate, sche
                       {run.schedule_name || `Schedule #${run.schedule_id}`}
                     </td>
                   )}
+                  <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
+                    {run.scheduled_for ? new Date(run.scheduled_for).toLocaleString() : '-'}
+                  </td>
                   <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
                     {new Date(run.started_at).toLocaleString()}
                   </td>
                   <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
                     <span className={statusClass}>{status}</span>
+                    {run.backfill_id ? ' (backfill)' : ''}
+                    {run.trigger_source ? ` (triggered by ${run.trigger_source})` : ''}
+                  </td>
+                  <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
+                    {duration}
+                    {loadWait}
                   </td>
-                  <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{duration}</td>
                   <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{run.rendered_pages}</td>
                   <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{size}</td>
-                  <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{run.error_text || '-'}</td>
+                  <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
+                    {run.error_text ||
+                      (run.status === 'queued' && run.not_before && new Date(run.not_before) > new Date()
+                        ? `Staggered: starts at ${new Date(run.not_before).toLocaleString()}`
+                        : run.status === 'queued' && run.queue_position
+                          ? `#${run.queue_position} in queue`
+                          : '-')}
+                  </td>
                   <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
                     {run.status === 'completed' && run.artifact_path ? (
                       // @ts-ignore
@@ -158,6 +186,12 @@ export const RunHistoryPage: React.FC<RunHistoryPageProps> = ({ onNavigate, sche
                         Download
                       </Button>
                     ) : null}
+                    {run.status === 'queued' || run.status === 'running' || run.status === 'interrupted' ? (
+                      // @ts-ignore
+                      <Button size="sm" variant="destructive" icon="times" onClick={() => cancelRun(run.id)}>
+                        Cancel
+                      </Button>
+                    ) : null}
                   </td>
                 </tr>
               );
@@ -188,4 +222,7 @@ const getStyles = (theme: GrafanaTheme2) => ({
   statusPending: css`
     color: ${theme.colors.warning.text};
   `,
+  statusSkipped: css`
+    color: ${theme.colors.text.secondary};
+  `,
 });
@@ -1,14 +1,15 @@
 import React, { useState, useEffect } from 'react';
 import { css } from '@emotion/css';
 import { GrafanaTheme2 } from '@grafana/data';
-import { useStyles2, Button, Field, Input, Select, Switch, TextArea, Form, FieldSet } from '@grafana/ui';
-import { ScheduleFormData } from '../../types/types';
+import { useStyles2, Button, Field, Input, Select, MultiSelect, Switch, TextArea, Form, FieldSet } from '@grafana/ui';
+import { ScheduleFormData, Calendar, UpcomingRun, TriggerToken } from '../../types/types';
 import { getBackendSrv, getAppEvents } from '@grafana/runtime';
 import { AppEvents } from '@grafana/data';
 import { DashboardPicker } from '../../components/DashboardPicker';
 import { CronEditor } from '../../components/CronEditor';
 import { RecipientsEditor } from '../../components/RecipientsEditor';
 import { VariablesEditor } from '../../components/VariablesEditor';
+import { RetryPolicyEditor } from '../../components/RetryPolicyEditor';
 
 interface ScheduleEditPageProps {
   onNavigate: (page: string) => void;
@@ -23,11 +24,83 @@ const intervalOptions = [
   { label: 'Custom (Cron)', value: 'cron' },
 ];
 
+const weekdayOptions = [
+  { label: 'Sunday', value: 0 },
+  { label: 'Monday', value: 1 },
+  { label: 'Tuesday', value: 2 },
+  { label: 'Wednesday', value: 3 },
+  { label: 'Thursday', value: 4 },
+  { label: 'Friday', value: 5 },
+  { label: 'Saturday', value: 6 },
+];
+
+const misfireOptions = [
+  { label: 'Run latest missed occurrence', value: 'run_latest' },
+  { label: 'Skip missed occurrences', value: 'skip' },
+  { label: 'Run every missed occurrence', value: 'run_all' },
+];
+
+const calendarPolicyOptions = [
+  { label: 'Skip the report', value: 'skip' },
+  { label: 'Postpone to the next business day', value: 'postpone' },
+  { label: 'Pull forward to the previous business day', value: 'pull_forward' },
+];
+
+const reducerOptions = [
+  { label: 'Last', value: 'last' },
+  { label: 'Mean', value: 'mean' },
+  { label: 'Min', value: 'min' },
+  { label: 'Max', value: 'max' },
+  { label: 'Sum', value: 'sum' },
+  { label: 'Count', value: 'count' },
+];
+
+const operatorOptions = ['>', '>=', '<', '<=', '==', '!='].map((op) => ({ label: op, value: op }));
+
+const skipUnchangedOptions = [
+  { label: 'Always send', value: '' },
+  { label: 'Skip when identical to the last report sent', value: 'identical' },
+  { label: 'Skip when it looks the same as the last report sent', value: 'similar' },
+];
+
 const formatOptions = [
   { label: 'PDF', value: 'pdf' },
   { label: 'HTML', value: 'html' },
 ];
 
+const panelLayoutOptions = [
+  { label: 'One panel per page', value: 'page' },
+  { label: 'Grid (4 panels per page)', value: 'grid' },
+];
+
+// dashboardPanels lists the panels of a dashboard model, including those nested in collapsed rows
+const dashboardPanels = (panels: any[] = []): Array<{ label: string; value: number }> =>
+  panels.flatMap((panel) =>
+    panel.type === 'row'
+      ? dashboardPanels(panel.panels)
+      : [{ label: panel.title || `Panel ${panel.id}`, value: panel.id }]
+  );
+
+// isValidQuery reports whether text is a JSON object usable as a condition query model
+// Converts between ISO timestamps and the browser-local value of a datetime-local input
+const toLocalInput = (iso?: string) => {
+  if (!iso) {
+    return '';
+  }
+  const date = new Date(iso);
+  return new Date(date.getTime() - date.getTimezoneOffset() * 60000).toISOString().slice(0, 16);
+};
+const fromLocalInput = (value: string) => (value ? new Date(value).toISOString() : undefined);
+
+const isValidQuery = (text: string) => {
+  try {
+    const parsed = JSON.parse(text);
+    return typeof parsed === 'object' && parsed !== null && !Array.isArray(parsed);
+  } catch {
+    return false;
+  }
+};
+
 export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate, isNew, scheduleId }) => {
   const styles = useStyles2(getStyles);
 
@@ -37,6 +110,9 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
     range_from: 'now-7d',
     range_to: 'now',
     interval_type: 'daily',
+    time_of_day: '08:00',
+    day_of_week: 1,
+    day_of_month: 1,
     timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
     format: 'pdf',
     recipients: { to: [] },
@@ -45,16 +121,63 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
     enabled: true,
   });
 
+  const [calendars, setCalendars] = useState<Calendar[]>([]);
+  const [panelOptions, setPanelOptions] = useState<Array<{ label: string; value: number }>>([]);
+  const [conditionQueryText, setConditionQueryText] = useState('');
+  const [upcomingRuns, setUpcomingRuns] = useState<UpcomingRun[] | null>(null);
+  const [backfillFrom, setBackfillFrom] = useState<string | undefined>();
+  const [backfillTo, setBackfillTo] = useState<string | undefined>();
+  const [backfillEmail, setBackfillEmail] = useState(false);
+  const [triggerTokens, setTriggerTokens] = useState<TriggerToken[]>([]);
+  const [triggerName, setTriggerName] = useState('');
+  const [newTriggerToken, setNewTriggerToken] = useState<string | null>(null);
+
   useEffect(() => {
     if (!isNew && scheduleId) {
       loadSchedule();
+      loadTriggerTokens();
     }
   }, [scheduleId]);
 
+  useEffect(() => {
+    loadCalendars();
+  }, []);
+
+  const loadCalendars = async () => {
+    try {
+      const response = await getBackendSrv().get('/api/plugins/sheduled-reports-app/resources/api/calendars');
+      setCalendars(response.calendars || []);
+    } catch (error) {
+      console.error('Failed to load calendars:', error);
+    }
+  };
+
+  const previewUpcomingRuns = async () => {
+    try {
+      const response = await getBackendSrv().post(
+        '/api/plugins/sheduled-reports-app/resources/api/schedules/preview-times?count=5',
+        formData
+      );
+      setUpcomingRuns(response.runs || []);
+    } catch (error: any) {
+      console.error('Failed to preview upcoming runs:', error);
+      getAppEvents().publish({
+        type: AppEvents.alertError.name,
+        payload: [error?.data?.message || error?.data || 'Failed to preview upcoming runs'],
+      });
+    }
+  };
+
   const loadSchedule = async () => {
     try {
       const response = await getBackendSrv().get(`/api/plugins/sheduled-reports-app/resources/api/schedules/${scheduleId}`);
       setFormData(response);
+      if (response.dashboard_uid) {
+        loadDashboardPanels(response.dashboard_uid);
+      }
+      if (response.condition) {
+        setConditionQueryText(JSON.stringify(response.condition.query, null, 2));
+      }
     } catch (error) {
       console.error('Failed to load schedule:', error);
     }
@@ -79,6 +202,75 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
     }
   };
 
+  const loadDashboardPanels = async (dashboardUid: string) => {
+    try {
+      const dashboard = await getBackendSrv().get(`/api/dashboards/uid/${dashboardUid}`);
+      setPanelOptions(dashboardPanels(dashboard.dashboard?.panels));
+    } catch (error) {
+      console.error('Failed to load dashboard panels:', error);
+    }
+  };
+
+  const loadTriggerTokens = async () => {
+    try {
+      const response = await getBackendSrv().get(
+        `/api/plugins/sheduled-reports-app/resources/api/schedules/${scheduleId}/triggers`
+      );
+      setTriggerTokens(response.tokens || []);
+    } catch (error) {
+      console.error('Failed to load trigger tokens:', error);
+    }
+  };
+
+  const createTriggerToken = async () => {
+    try {
+      const response = await getBackendSrv().post(
+        `/api/plugins/sheduled-reports-app/resources/api/schedules/${scheduleId}/triggers`,
+        { name: triggerName }
+      );
+      setNewTriggerToken(response.token);
+      setTriggerName('');
+      loadTriggerTokens();
+    } catch (error) {
+      console.error('Failed to create trigger token:', error);
+      getAppEvents().publish({
+        type: AppEvents.alertError.name,
+        payload: ['Failed to create trigger token'],
+      });
+    }
+  };
+
+  const revokeTriggerToken = async (tokenId: number) => {
+    try {
+      await getBackendSrv().delete(
+        `/api/plugins/sheduled-reports-app/resources/api/schedules/${scheduleId}/triggers/${tokenId}`
+      );
+      loadTriggerTokens();
+    } catch (error) {
+      console.error('Failed to revoke trigger token:', error);
+    }
+  };
+
+  const startBackfill = async () => {
+    const appEvents = getAppEvents();
+    try {
+      const response = await getBackendSrv().post(
+        `/api/plugins/sheduled-reports-app/resources/api/schedules/${scheduleId}/backfill`,
+        { from: backfillFrom, to: backfillTo, send_email: backfillEmail }
+      );
+      appEvents.publish({
+        type: AppEvents.alertSuccess.name,
+        payload: [`Queued ${response.backfill.run_count} past report(s); see Run History for progress`],
+      });
+    } catch (error: any) {
+      console.error('Failed to start backfill:', error);
+      appEvents.publish({
+        type: AppEvents.alertError.name,
+        payload: [error?.data?.message || error?.data || 'Failed to start backfill'],
+      });
+    }
+  };
+
   const handleSubmit = async () => {
     const appEvents = getAppEvents();
     try {
@@ -127,12 +319,35 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
                 <DashboardPicker
                   value={formData.dashboard_uid}
                   onChange={(uid, title) => {
-                    setFormData({ ...formData, dashboard_uid: uid, dashboard_title: title });
+                    setFormData({ ...formData, dashboard_uid: uid, dashboard_title: title, panel_ids: [] });
                     loadDashboardVariables(uid);
+                    loadDashboardPanels(uid);
                   }}
                 />
               </Field>
 
+              <Field
+                label="Panels"
+                description="Render only these panels, each on its own, instead of the whole dashboard (PDF only)"
+              >
+                <MultiSelect
+                  options={panelOptions}
+                  value={formData.panel_ids || []}
+                  onChange={(v) => setFormData({ ...formData, panel_ids: v.map((o) => o.value as number) })}
+                  placeholder="Whole dashboard"
+                />
+              </Field>
+
+              {(formData.panel_ids?.length ?? 0) > 0 && (
+                <Field label="Panel Layout">
+                  <Select
+                    options={panelLayoutOptions}
+                    value={formData.panel_layout || 'page'}
+                    onChange={(v) => setFormData({ ...formData, panel_layout: v.value as 'page' | 'grid' })}
+                  />
+                </Field>
+              )}
+
               <Field label="Format">
                 <Select
                   options={formatOptions}
@@ -176,6 +391,46 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
                 />
               </Field>
 
+              {formData.interval_type !== 'cron' && (
+                <Field label="Time of Day" description="HH:MM in the schedule timezone">
+                  <Input
+                    value={formData.time_of_day || ''}
+                    onChange={(e) => setFormData({ ...formData, time_of_day: e.currentTarget.value })}
+                    placeholder="08:00"
+                  />
+                </Field>
+              )}
+
+              {formData.interval_type === 'weekly' && (
+                <Field label="Day of Week">
+                  <Select
+                    options={weekdayOptions}
+                    value={formData.day_of_week ?? 1}
+                    onChange={(v) => setFormData({ ...formData, day_of_week: v.value })}
+                  />
+                </Field>
+              )}
+
+              {formData.interval_type === 'monthly' && (
+                <>
+                  <Field label="Day of Month" description="Clamped to the last day of shorter months">
+                    <Input
+                      type="number"
+                      min={1}
+                      max={31}
+                      value={formData.day_of_month ?? 1}
+                      onChange={(e) => setFormData({ ...formData, day_of_month: parseInt(e.currentTarget.value, 10) || 1 })}
+                    />
+                  </Field>
+                  <Field label="Business Days" description="Count only Monday-Friday (e.g. 1st business day of the month)">
+                    <Switch
+                      value={formData.business_day || false}
+                      onChange={(e) => setFormData({ ...formData, business_day: e.currentTarget.checked })}
+                    />
+                  </Field>
+                </>
+              )}
+
               {formData.interval_type === 'cron' && (
                 <Field label="Cron Expression">
                   <CronEditor
@@ -191,6 +446,108 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
                   onChange={(e) => setFormData({ ...formData, timezone: e.currentTarget.value })}
                 />
               </Field>
+
+              <Field
+                label="Spread (minutes)"
+                description="Start up to this many minutes after the fire time so schedules sharing a time do not all render at once. 0 uses the default from Settings."
+              >
+                <Input
+                  type="number"
+                  min={0}
+                  max={60}
+                  value={formData.spread_minutes || 0}
+                  onChange={(e) => setFormData({ ...formData, spread_minutes: parseInt(e.currentTarget.value, 10) || 0 })}
+                />
+              </Field>
+
+              <Field
+                label="Missed Runs"
+                description="What to do with occurrences missed while Grafana was down. Late runs cover their original time range."
+              >
+                <Select
+                  options={misfireOptions}
+                  value={formData.misfire_policy || 'run_latest'}
+                  onChange={(v) => setFormData({ ...formData, misfire_policy: v.value as any })}
+                />
+              </Field>
+
+              <Field label="Holiday Calendar" description="Avoid sending reports on the calendar's holidays and blackout windows">
+                <Select
+                  options={[
+                    { label: 'None', value: 0 },
+                    ...calendars.map((c) => ({ label: c.name, value: c.id })),
+                  ]}
+                  value={formData.calendar_id || 0}
+                  onChange={(v) => setFormData({ ...formData, calendar_id: v.value || undefined })}
+                />
+              </Field>
+
+              {formData.calendar_id && (
+                <Field label="On Blocked Days">
+                  <Select
+                    options={calendarPolicyOptions}
+                    value={formData.calendar_policy || 'skip'}
+                    onChange={(v) => setFormData({ ...formData, calendar_policy: v.value as any })}
+                  />
+                </Field>
+              )}
+
+              <div className={styles.row}>
+                <Field label="Start" description="No reports before this time (optional)">
+                  <Input
+                    type="datetime-local"
+                    value={toLocalInput(formData.start_date)}
+                    onChange={(e) => setFormData({ ...formData, start_date: fromLocalInput(e.currentTarget.value) })}
+                  />
+                </Field>
+                <Field label="End" description="The schedule is disabled after this time (optional)">
+                  <Input
+                    type="datetime-local"
+                    value={toLocalInput(formData.end_date)}
+                    onChange={(e) => setFormData({ ...formData, end_date: fromLocalInput(e.currentTarget.value) })}
+                  />
+                </Field>
+                <Field label="Max Occurrences" description="Disable the schedule after this many reports (0 = unlimited)">
+                  <Input
+                    type="number"
+                    min={0}
+                    value={formData.max_occurrences || 0}
+                    onChange={(e) => setFormData({ ...formData, max_occurrences: parseInt(e.currentTarget.value, 10) || 0 })}
+                  />
+                </Field>
+              </div>
+
+              {/* @ts-ignore */}
+              <Button variant="secondary" icon="calendar-alt" onClick={previewUpcomingRuns}>
+                Preview Upcoming Runs
+              </Button>
+              {upcomingRuns && (
+                <table className={styles.preview}>
+                  <thead>
+                    <tr>
+                      <th>Fires at</th>
+                      <th>Starts at</th>
+                      <th>Covers</th>
+                    </tr>
+                  </thead>
+                  <tbody>
+                    {upcomingRuns.length === 0 && (
+                      <tr>
+                        <td colSpan={3}>No upcoming runs: the schedule has no occurrences left</td>
+                      </tr>
+                    )}
+                    {upcomingRuns.map((run) => (
+                      <tr key={run.at}>
+                        <td>{new Date(run.at).toLocaleString()}</td>
+                        <td>{run.start_at ? new Date(run.start_at).toLocaleString() : '-'}</td>
+                        <td>
+                          {new Date(run.range_from).toLocaleString()} – {new Date(run.range_to).toLocaleString()}
+                        </td>
+                      </tr>
+                    ))}
+                  </tbody>
+                </table>
+              )}
             </FieldSet>
 
             <FieldSet label="Dashboard Variables">
@@ -201,6 +558,131 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
               />
             </FieldSet>
 
+            <FieldSet label="Condition">
+              <Field
+                label="Only send when a query condition is met"
+                description="Evaluate a datasource query over the report's time range and skip the report otherwise"
+              >
+                <Switch
+                  value={!!formData.condition}
+                  onChange={(e) => {
+                    if (e.currentTarget.checked) {
+                      setFormData({
+                        ...formData,
+                        condition: { datasource_uid: '', query: {}, reducer: 'last', operator: '>', threshold: 0 },
+                      });
+                      setConditionQueryText('{}');
+                    } else {
+                      setFormData({ ...formData, condition: undefined });
+                    }
+                  }}
+                />
+              </Field>
+
+              {formData.condition && (
+                <>
+                  <Field label="Datasource UID" required>
+                    <Input
+                      value={formData.condition.datasource_uid}
+                      onChange={(e) =>
+                        setFormData({
+                          ...formData,
+                          condition: { ...formData.condition!, datasource_uid: e.currentTarget.value },
+                        })
+                      }
+                    />
+                  </Field>
+
+                  <Field
+                    label="Query"
+                    description='Datasource query model as JSON, e.g. {"expr": "sum(rate(http_errors_total[5m]))"}'
+                    invalid={!isValidQuery(conditionQueryText)}
+                    error="Query must be a JSON object"
+                  >
+                    <TextArea
+                      value={conditionQueryText}
+                      onChange={(e) => {
+                        const text = e.currentTarget.value;
+                        setConditionQueryText(text);
+                        if (isValidQuery(text)) {
+                          setFormData({ ...formData, condition: { ...formData.condition!, query: JSON.parse(text) } });
+                        }
+                      }}
+                      rows={4}
+                    />
+                  </Field>
+
+                  <div className={styles.row}>
+                    <Field label="Reduce">
+                      <Select
+                        options={reducerOptions}
+                        value={formData.condition.reducer || 'last'}
+                        onChange={(v) =>
+                          setFormData({ ...formData, condition: { ...formData.condition!, reducer: v.value as any } })
+                        }
+                      />
+                    </Field>
+
+                    <Field label="Operator">
+                      <Select
+                        options={operatorOptions}
+                        value={formData.condition.operator}
+                        onChange={(v) =>
+                          setFormData({ ...formData, condition: { ...formData.condition!, operator: v.value as any } })
+                        }
+                      />
+                    </Field>
+
+                    <Field label="Threshold">
+                      <Input
+                        type="number"
+                        value={formData.condition.threshold}
+                        onChange={(e) =>
+                          setFormData({
+                            ...formData,
+                            condition: { ...formData.condition!, threshold: parseFloat(e.currentTarget.value) || 0 },
+                          })
+                        }
+                      />
+                    </Field>
+                  </div>
+                </>
+              )}
+            </FieldSet>
+
+            <FieldSet label="Retries">
+              <Field label="Override retry policy" description="Use a different retry policy than the one in Settings">
+                <Switch
+                  value={!!formData.retry_policy}
+                  onChange={(e) =>
+                    setFormData({
+                      ...formData,
+                      retry_policy: e.currentTarget.checked
+                        ? { max_attempts: 3, backoff_base_ms: 1000, backoff_max_ms: 30000, jitter: 0 }
+                        : undefined,
+                    })
+                  }
+                />
+              </Field>
+              {formData.retry_policy && (
+                <RetryPolicyEditor
+                  value={formData.retry_policy}
+                  onChange={(retry_policy) => setFormData({ ...formData, retry_policy })}
+                />
+              )}
+              <Field
+                label="Run Timeout (seconds)"
+                description="Deadline for rendering, PDF generation, storage and delivery including retries. 0 uses the default from Settings."
+              >
+                <Input
+                  type="number"
+                  min={0}
+                  value={formData.run_timeout_seconds || 0}
+                  onChange={(e) => setFormData({ ...formData, run_timeout_seconds: parseInt(e.currentTarget.value, 10) || 0 })}
+                />
+              </Field>
+            </FieldSet>
+
             <FieldSet label="Email">
               <Field label="Recipients" required>
                 <RecipientsEditor
@@ -209,6 +691,17 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
                 />
               </Field>
 
+              <Field
+                label="Unchanged Reports"
+                description="'Looks the same' compares screenshots perceptually, so timestamps and other small changes are ignored"
+              >
+                <Select
+                  options={skipUnchangedOptions}
+                  value={formData.skip_unchanged || ''}
+                  onChange={(v) => setFormData({ ...formData, skip_unchanged: v.value as any })}
+                />
+              </Field>
+
               <Field label="Subject">
                 <Input
                   value={formData.email_subject}
@@ -225,6 +718,93 @@ export const ScheduleEditPage: React.FC<ScheduleEditPageProps> = ({ onNavigate,
               </Field>
             </FieldSet>
 
+            {!isNew && (
+              <FieldSet label="Triggers">
+                <p>
+                  External systems, e.g. an ETL pipeline, can run this schedule as soon as their data is ready by
+                  calling <code>POST /api/plugins/sheduled-reports-app/resources/api/triggers/&lt;token&gt;</code> with
+                  a Grafana service account token of this organization.
+                </p>
+                {triggerTokens.length > 0 && (
+                  <table className={styles.preview}>
+                    <thead>
+                      <tr>
+                        <th>Name</th>
+                        <th>Created</th>
+                        <th>Last used</th>
+                        <th />
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {triggerTokens.map((token) => (
+                        <tr key={token.id}>
+                          <td>{token.name}</td>
+                          <td>{new Date(token.created_at).toLocaleString()}</td>
+                          <td>{token.last_used_at ? new Date(token.last_used_at).toLocaleString() : 'Never'}</td>
+                          <td>
+                            {/* @ts-ignore */}
+                            <Button size="sm" variant="destructive" fill="text" onClick={() => revokeTriggerToken(token.id)}>
+                              Revoke
+                            </Button>
+                          </td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </table>
+                )}
+                {newTriggerToken && (
+                  <Field label="New token" description="Copy it now: it is not shown again">
+                    <Input value={newTriggerToken} readOnly />
+                  </Field>
+                )}
+                <div className={styles.row}>
+                  <Field label="Token name">
+                    <Input
+                      value={triggerName}
+                      onChange={(e) => setTriggerName(e.currentTarget.value)}
+                      placeholder="etl-pipeline"
+                    />
+                  </Field>
+                </div>
+                {/* @ts-ignore */}
+                <Button variant="secondary" icon="plus" onClick={createTriggerToken} disabled={!triggerName.trim()}>
+                  Create Token
+                </Button>
+              </FieldSet>
+            )}
+
+            {!isNew && (
+              <FieldSet label="Backfill">
+                <p>
+                  Generate the reports this schedule would have produced between two past dates, one per occurrence, each
+                  covering its own time range. Save changes to the schedule first.
+                </p>
+                <div className={styles.row}>
+                  <Field label="From">
+                    <Input
+                      type="datetime-local"
+                      value={toLocalInput(backfillFrom)}
+                      onChange={(e) => setBackfillFrom(fromLocalInput(e.currentTarget.value))}
+                    />
+                  </Field>
+                  <Field label="To">
+                    <Input
+                      type="datetime-local"
+                      value={toLocalInput(backfillTo)}
+                      onChange={(e) => setBackfillTo(fromLocalInput(e.currentTarget.value))}
+                    />
+                  </Field>
+                  <Field label="Email as one bundle" description="Otherwise the reports are only stored">
+                    <Switch value={backfillEmail} onChange={(e) => setBackfillEmail(e.currentTarget.checked)} />
+                  </Field>
+                </div>
+                {/* @ts-ignore */}
+                <Button variant="secondary" icon="history" onClick={startBackfill} disabled={!backfillFrom || !backfillTo}>
+                  Generate Past Reports
+                </Button>
+              </FieldSet>
+            )}
+
             <div className={styles.actions}>
               {/* @ts-ignore */}
               <Button type="submit" variant="primary">
@@ -257,4 +837,16 @@ const getStyles = (theme: GrafanaTheme2) => ({
     gap: ${theme.spacing(2)};
     margin-top: ${theme.spacing(3)};
   `,
+  preview: css`
+    margin-top: ${theme.spacing(1)};
+    th,
+    td {
+      padding: ${theme.spacing(0.5, 2, 0.5, 0)};
+      text-align: left;
+    }
+  `,
+  row: css`
+    display: flex;
+    gap: ${theme.spacing(2)};
+  `,
 });
@@ -6,6 +6,11 @@ import { Schedule } from '../../types/types';
 import { getBackendSrv, getAppEvents, config } from '@grafana/runtime';
 import { AppEvents } from '@grafana/data';
 
+// A disabled schedule has ended when it used up its occurrences or passed its end date
+const hasEnded = (schedule: Schedule) =>
+  (!!schedule.max_occurrences && schedule.occurrence_count >= schedule.max_occurrences) ||
+  (!!schedule.end_date && new Date(schedule.end_date) < new Date());
+
 interface SchedulesPageProps {
   onNavigate: (page: string, id?: number) => void;
 }
@@ -107,10 +112,14 @@ export const SchedulesPage: React.FC<SchedulesPageProps> = ({ onNavigate }) => {
   const handleRunNow = async (id: number) => {
     const appEvents = getAppEvents();
     try {
-      await getBackendSrv().post(`/api/plugins/sheduled-reports-app/resources/api/schedules/${id}/run`);
+      const response = await getBackendSrv().post(`/api/plugins/sheduled-reports-app/resources/api/schedules/${id}/run`);
       appEvents.publish({
         type: AppEvents.alertSuccess.name,
-        payload: ['Report generation started'],
+        payload: [
+          response.queue_position > 1
+            ? `Report queued (#${response.queue_position} in queue)`
+            : 'Report generation started',
+        ],
       });
     } catch (error) {
       console.error('Failed to run schedule:', error);
@@ -213,7 +222,7 @@ export const SchedulesPage: React.FC<SchedulesPageProps> = ({ onNavigate }) => {
                 <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>{schedule.format.toUpperCase()}</td>
                 <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
                   <span className={schedule.enabled ? styles.statusEnabled : styles.statusDisabled}>
-                    {schedule.enabled ? 'Enabled' : 'Disabled'}
+                    {schedule.enabled ? 'Enabled' : hasEnded(schedule) ? 'Ended' : 'Disabled'}
                   </span>
                 </td>
                 <td style={{ padding: '8px', borderBottom: '1px solid #eee' }}>
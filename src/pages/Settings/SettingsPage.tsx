@@ -5,6 +5,7 @@ import { useStyles2, Button, Field, Input, Switch, FieldSet, Form, Select } from
 import { Settings, SMTPConfig, RendererConfig, Limits } from '../../types/types';
 import { getBackendSrv, getAppEvents } from '@grafana/runtime';
 import { AppEvents } from '@grafana/data';
+import { RetryPolicyEditor } from '../../components/RetryPolicyEditor';
 
 interface SettingsPageProps {
   onNavigate: (page: string) => void;
@@ -31,6 +32,8 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
       max_attachment_size_mb: 25,
       max_concurrent_renders: 5,
       retention_days: 30,
+      run_timeout_seconds: 600,
+      spread_minutes: 0,
     },
   });
 
@@ -162,13 +165,14 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
 
               <Field
                 label="Rendering Backend"
-                description="Choose between Chromium (full features, ~300MB) or wkhtmltopdf (lightweight, ~12MB)"
+                description="Choose between Chromium (full features, ~300MB), wkhtmltopdf (lightweight, ~12MB) or a remote Grafana image renderer service"
               >
                 <Select
                   value={settings.renderer_config?.backend || 'chromium'}
                   options={[
                     { label: 'Chromium (Default - Full JavaScript support)', value: 'chromium' },
                     { label: 'wkhtmltopdf (Lightweight - Direct PDF)', value: 'wkhtmltopdf' },
+                    { label: 'Grafana Image Renderer (Remote service)', value: 'image-renderer' },
                   ]}
                   onChange={(option) => updateRenderer('backend', option.value)}
                 />
@@ -182,13 +186,6 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
                   onChange={(e) => updateRenderer('timeout_ms', parseInt(e.currentTarget.value))}
                 />
               </Field>
-              <Field label="Render Delay (ms)" description="Wait time after page load to allow queries to finish">
-                <Input
-                  type="number"
-                  value={settings.renderer_config?.delay_ms || 2000}
-                  onChange={(e) => updateRenderer('delay_ms', parseInt(e.currentTarget.value))}
-                />
-              </Field>
               <Field label="Viewport Width">
                 <Input
                   type="number"
@@ -203,6 +200,17 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
                   onChange={(e) => updateRenderer('viewport_height', parseInt(e.currentTarget.value))}
                 />
               </Field>
+              {settings.renderer_config?.backend !== 'wkhtmltopdf' && (
+                <Field
+                  label="Full Page Capture"
+                  description="Scroll through the dashboard and capture its whole height instead of the viewport"
+                >
+                  <Switch
+                    value={settings.renderer_config?.full_page || false}
+                    onChange={(e) => updateRenderer('full_page', e.currentTarget.checked)}
+                  />
+                </Field>
+              )}
               <Field label="Device Scale Factor" description="Higher values (2-4) increase image quality">
                 <Input
                   type="number"
@@ -211,6 +219,20 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
                   onChange={(e) => updateRenderer('device_scale_factor', parseFloat(e.currentTarget.value))}
                 />
               </Field>
+              <Field label="Panel Width" description="Width in pixels of panels that schedules render individually">
+                <Input
+                  type="number"
+                  value={settings.renderer_config?.panel_width || 1000}
+                  onChange={(e) => updateRenderer('panel_width', parseInt(e.currentTarget.value))}
+                />
+              </Field>
+              <Field label="Panel Height" description="Height in pixels of panels that schedules render individually">
+                <Input
+                  type="number"
+                  value={settings.renderer_config?.panel_height || 500}
+                  onChange={(e) => updateRenderer('panel_height', parseInt(e.currentTarget.value))}
+                />
+              </Field>
               <Field label="Skip TLS Verification" description="Disable TLS certificate verification (use for self-signed certificates)">
                 <Switch
                   value={settings.renderer_config?.skip_tls_verify || false}
@@ -221,6 +243,24 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
               {/* Chromium-specific settings */}
               {settings.renderer_config?.backend === 'chromium' && (
                 <>
+                  <Field
+                    label="Network Idle (ms)"
+                    description="Capture once all panels finished loading and no request has been pending for this long"
+                  >
+                    <Input
+                      type="number"
+                      value={settings.renderer_config?.network_idle_ms || 500}
+                      onChange={(e) => updateRenderer('network_idle_ms', parseInt(e.currentTarget.value))}
+                    />
+                  </Field>
+                  {settings.renderer_config?.full_page && (
+                    <Field label="Expand Collapsed Rows" description="Open collapsed dashboard rows in full page captures">
+                      <Switch
+                        value={settings.renderer_config?.expand_rows || false}
+                        onChange={(e) => updateRenderer('expand_rows', e.currentTarget.checked)}
+                      />
+                    </Field>
+                  )}
                   <Field label="Chromium Path" description="Optional: Path to Chromium binary (auto-detected if empty)">
                     <Input
                       value={settings.renderer_config?.chromium_path || ''}
@@ -251,13 +291,45 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
 
               {/* wkhtmltopdf-specific settings */}
               {settings.renderer_config?.backend === 'wkhtmltopdf' && (
-                <Field label="wkhtmltopdf Path" description="Optional: Path to wkhtmltopdf binary (auto-detected if empty)">
-                  <Input
-                    value={settings.renderer_config?.wkhtmltopdf_path || ''}
-                    onChange={(e) => updateRenderer('wkhtmltopdf_path', e.currentTarget.value)}
-                    placeholder="/usr/bin/wkhtmltopdf"
-                  />
-                </Field>
+                <>
+                  <Field label="wkhtmltopdf Path" description="Optional: Path to wkhtmltopdf binary (auto-detected if empty)">
+                    <Input
+                      value={settings.renderer_config?.wkhtmltopdf_path || ''}
+                      onChange={(e) => updateRenderer('wkhtmltopdf_path', e.currentTarget.value)}
+                      placeholder="/usr/bin/wkhtmltopdf"
+                    />
+                  </Field>
+                  <Field label="Render Delay (ms)" description="Wait time after page load to allow queries to finish">
+                    <Input
+                      type="number"
+                      value={settings.renderer_config?.delay_ms || 2000}
+                      onChange={(e) => updateRenderer('delay_ms', parseInt(e.currentTarget.value))}
+                    />
+                  </Field>
+                </>
+              )}
+
+              {/* image-renderer-specific settings */}
+              {settings.renderer_config?.backend === 'image-renderer' && (
+                <>
+                  <Field label="Image Renderer URL" description="Render endpoint of the grafana-image-renderer service">
+                    <Input
+                      value={settings.renderer_config?.url || ''}
+                      onChange={(e) => updateRenderer('url', e.currentTarget.value)}
+                      placeholder="http://renderer:8081/render"
+                    />
+                  </Field>
+                  <Field
+                    label="Renderer Token"
+                    description="The service's auth token (AUTH_TOKEN, Grafana's rendering.renderer_token); empty uses the default"
+                  >
+                    <Input
+                      type="password"
+                      value={settings.renderer_config?.renderer_token || ''}
+                      onChange={(e) => updateRenderer('renderer_token', e.currentTarget.value)}
+                    />
+                  </Field>
+                </>
               )}
             </FieldSet>
 
@@ -276,13 +348,28 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
                   onChange={(e) => updateLimits('max_attachment_size_mb', parseInt(e.currentTarget.value))}
                 />
               </Field>
-              <Field label="Max Concurrent Renders">
+              <Field
+                label="Max Concurrent Renders"
+                description="Reports of this organization rendering at once; the rest wait while other organizations get their share"
+              >
                 <Input
                   type="number"
                   value={settings.limits?.max_concurrent_renders || 5}
                   onChange={(e) => updateLimits('max_concurrent_renders', parseInt(e.currentTarget.value))}
                 />
               </Field>
+              {settings.org_id === 1 && (
+                <Field
+                  label="Worker Pool Size"
+                  description="Reports each Grafana instance renders at once, across all organizations (0 = default of 5, at most 50). Only the main organization can change it."
+                >
+                  <Input
+                    type="number"
+                    value={settings.limits?.worker_pool_size || 0}
+                    onChange={(e) => updateLimits('worker_pool_size', parseInt(e.currentTarget.value) || 0)}
+                  />
+                </Field>
+              )}
               <Field label="Retention Days">
                 <Input
                   type="number"
@@ -290,6 +377,37 @@ export const SettingsPage: React.FC<SettingsPageProps> = ({ onNavigate }) => {
                   onChange={(e) => updateLimits('retention_days', parseInt(e.currentTarget.value))}
                 />
               </Field>
+              <Field
+                label="Run Timeout (seconds)"
+                description="Deadline for a whole run, from rendering to email delivery, including retries"
+              >
+                <Input
+                  type="number"
+                  value={settings.limits?.run_timeout_seconds || 600}
+                  onChange={(e) => updateLimits('run_timeout_seconds', parseInt(e.currentTarget.value))}
+                />
+              </Field>
+              <Field
+                label="Spread Window (minutes)"
+                description="Stagger schedules sharing a fire time across this many minutes (0 = off, at most 60)"
+              >
+                <Input
+                  type="number"
+                  value={settings.limits?.spread_minutes || 0}
+                  onChange={(e) => updateLimits('spread_minutes', parseInt(e.currentTarget.value) || 0)}
+                />
+              </Field>
+            </FieldSet>
+
+            <FieldSet label="Retries">
+              <p>
+                Failed runs are retried with exponential backoff. Permanent failures such as a deleted dashboard or
+                a recipient rejected by the mail server are not retried. Schedules can override this policy.
+              </p>
+              <RetryPolicyEditor
+                value={settings.retry_policy || { max_attempts: 3, backoff_base_ms: 1000, backoff_max_ms: 30000, jitter: 0 }}
+                onChange={(retry_policy) => setSettings({ ...settings, retry_policy })}
+              />
             </FieldSet>
 
             {/* @ts-ignore */}
@@ -4,12 +4,28 @@ export interface Schedule {
   name: string;
   dashboard_uid: string;
   dashboard_title?: string;
-  panel_ids?: number[];
+  panel_ids?: number[]; // Render only these panels instead of the whole dashboard
+  panel_layout?: 'page' | 'grid'; // PDF layout of the panels
   range_from: string;
   range_to: string;
   interval_type: 'cron' | 'daily' | 'weekly' | 'monthly';
   cron_expr?: string;
+  time_of_day?: string; // "HH:MM" in the schedule timezone (daily/weekly/monthly)
+  day_of_week?: number; // 0 = Sunday ... 6 = Saturday (weekly)
+  day_of_month?: number; // 1-31 (monthly)
+  business_day?: boolean; // day_of_month counts business days (monthly)
   timezone: string;
+  misfire_policy?: 'run_latest' | 'skip' | 'run_all'; // Occurrences missed while the plugin was down
+  calendar_id?: number; // Holiday calendar whose blocked days the schedule avoids
+  calendar_policy?: 'skip' | 'postpone' | 'pull_forward'; // What happens to occurrences on blocked days
+  condition?: Condition; // Only deliver when the query result satisfies the condition
+  skip_unchanged?: '' | 'identical' | 'similar'; // Skip reports unchanged since the last one sent
+  retry_policy?: RetryPolicy; // Overrides the org's retry policy
+  run_timeout_seconds?: number; // Deadline of a run including retries (0 = org default)
+  start_date?: string; // No occurrences before this instant (ISO 8601)
+  end_date?: string; // No occurrences after this instant; the schedule is disabled afterwards
+  max_occurrences?: number; // Disable the schedule after this many scheduled occurrences (0 = unlimited)
+  spread_minutes?: number; // Stagger the start within this many minutes after the fire time (0 = org default)
   format: 'pdf' | 'html';
   variables?: Record<string, string>;
   recipients: Recipients;
@@ -19,6 +35,7 @@ export interface Schedule {
   enabled: boolean;
   last_run_at?: string;
   next_run_at?: string;
+  occurrence_count: number; // Scheduled occurrences queued so far, counted against max_occurrences
   owner_user_id: number;
   created_at: string;
   updated_at: string;
@@ -36,15 +53,80 @@ export interface Run {
   org_id: number;
   started_at: string;
   finished_at?: string;
-  status: 'pending' | 'running' | 'completed' | 'failed';
+  status: 'queued' | 'pending' | 'running' | 'completed' | 'failed' | 'skipped' | 'skipped_unchanged' | 'cancelled' | 'interrupted';
   error_text?: string;
   artifact_path?: string;
   rendered_pages: number;
   bytes: number;
-  checksum?: string;
+  checksum?: string; // sha256 of the rendered dashboard
+  image_hash?: string; // Perceptual hash of the rendered screenshot
+  occurrence_key?: string;
+  scheduled_for?: string; // Planned fire time of the occurrence this run fulfils
+  range_from?: string; // Time range rendered when it differs from the schedule's (epoch ms)
+  range_to?: string;
+  condition_value?: number; // Reduced condition query value observed by the run
+  not_before?: string; // Staggered runs start no earlier than this
+  queue_position?: number; // 1-based position of a queued run; interactive runs go first
+  backfill_id?: number; // Set for runs generated by a backfill of past occurrences
+  trigger_id?: number; // Trigger token that queued the run
+  trigger_source?: string; // Caller of the trigger, defaults to the token name
+  variables?: Record<string, string>; // Dashboard variables overriding the schedule's
+  render_wait_ms?: number; // Time spent waiting for the dashboard to finish loading (Chromium)
   created_at: string;
 }
 
+export interface Backfill {
+  id: number;
+  schedule_id: number;
+  org_id: number;
+  from: string;
+  to: string;
+  send_email: boolean;
+  status: 'running' | 'delivering' | 'completed' | 'failed';
+  run_count: number;
+  error_text?: string;
+  created_at: string;
+  finished_at?: string;
+}
+
+export interface TriggerToken {
+  id: number;
+  schedule_id: number;
+  name: string;
+  token?: string; // Only returned when the token is created
+  created_at: string;
+  last_used_at?: string;
+}
+
+export interface Condition {
+  datasource_uid: string;
+  query: Record<string, any>; // Datasource query model, e.g. { "expr": "..." } for Prometheus
+  reducer?: 'last' | 'mean' | 'min' | 'max' | 'sum' | 'count';
+  operator: '>' | '>=' | '<' | '<=' | '==' | '!=';
+  threshold: number;
+}
+
+export interface Calendar {
+  id: number;
+  org_id: number;
+  name: string;
+  holidays: CalendarDay[];
+  blackouts: BlackoutWindow[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CalendarDay {
+  date: string; // YYYY-MM-DD
+  name?: string;
+}
+
+export interface BlackoutWindow {
+  start: string; // YYYY-MM-DD
+  end: string; // YYYY-MM-DD (inclusive)
+  name?: string;
+}
+
 export interface Template {
   id: number;
   org_id: number;
@@ -77,10 +159,18 @@ export interface Settings {
   smtp_config?: SMTPConfig;
   renderer_config: RendererConfig;
   limits: Limits;
+  retry_policy?: RetryPolicy; // Default retry policy of the org's schedules
   created_at: string;
   updated_at: string;
 }
 
+export interface RetryPolicy {
+  max_attempts: number; // Including the first attempt (default 3)
+  backoff_base_ms: number; // Delay before the first retry, doubled for every further retry (default 1000)
+  backoff_max_ms: number; // Upper bound of the delay (default 30000)
+  jitter: number; // Fraction by which each delay is randomized, e.g. 0.2 for ±20%
+}
+
 export interface SMTPConfig {
   host: string;
   port: number;
@@ -91,17 +181,23 @@ export interface SMTPConfig {
 }
 
 export interface RendererConfig {
-  backend?: 'chromium' | 'wkhtmltopdf';
+  backend?: 'chromium' | 'wkhtmltopdf' | 'image-renderer';
   grafana_url?: string; // Grafana base URL (e.g., https://127.0.0.1:3000/dna)
-  url?: string; // DEPRECATED - kept for backward compatibility
+  url?: string; // Render endpoint of the image-renderer backend (e.g., http://renderer:8081/render)
+  renderer_token?: string; // Auth token of the image renderer service
   timeout_ms: number;
-  delay_ms: number;
+  delay_ms: number; // JavaScript delay of wkhtmltopdf; Chromium waits until the dashboard is ready instead
   viewport_width: number;
   viewport_height: number;
   device_scale_factor?: number;
   skip_tls_verify?: boolean;
+  panel_width?: number; // Size of individually rendered panels in pixels
+  panel_height?: number;
+  full_page?: boolean; // Capture the whole dashboard height instead of the viewport (Chromium, image-renderer)
+  expand_rows?: boolean; // Full page captures expand collapsed rows (Chromium)
 
   // Chromium-specific
+  network_idle_ms?: number; // No request may be pending for this long before capturing (default 500)
   chromium_path?: string;
   headless?: boolean;
   disable_gpu?: boolean;
@@ -111,23 +207,50 @@ export interface RendererConfig {
   wkhtmltopdf_path?: string;
 }
 
+// A future fire time of a schedule with the absolute time range its report will cover
+export interface UpcomingRun {
+  at: string;
+  start_at?: string; // Staggered start of saved schedules with a spread window
+  range_from: string;
+  range_to: string;
+}
+
 export interface Limits {
   max_recipients: number;
   max_attachment_size_mb: number;
-  max_concurrent_renders: number;
+  max_concurrent_renders: number; // Runs of the org executing at once across all instances
   retention_days: number;
+  run_timeout_seconds: number; // Default deadline of a run including retries
+  spread_minutes: number; // Default window schedules are staggered in after their fire time (0 = off)
+  worker_pool_size?: number; // Runs each instance executes at once; main org only (0 = default)
 }
 
 export interface ScheduleFormData {
   name: string;
   dashboard_uid: string;
   dashboard_title?: string;
-  panel_ids?: number[];
+  panel_ids?: number[]; // Render only these panels instead of the whole dashboard
+  panel_layout?: 'page' | 'grid'; // PDF layout of the panels
   range_from: string;
   range_to: string;
   interval_type: 'cron' | 'daily' | 'weekly' | 'monthly';
   cron_expr?: string;
+  time_of_day?: string; // "HH:MM" in the schedule timezone (daily/weekly/monthly)
+  day_of_week?: number; // 0 = Sunday ... 6 = Saturday (weekly)
+  day_of_month?: number; // 1-31 (monthly)
+  business_day?: boolean; // day_of_month counts business days (monthly)
   timezone: string;
+  misfire_policy?: 'run_latest' | 'skip' | 'run_all'; // Occurrences missed while the plugin was down
+  calendar_id?: number; // Holiday calendar whose blocked days the schedule avoids
+  calendar_policy?: 'skip' | 'postpone' | 'pull_forward'; // What happens to occurrences on blocked days
+  condition?: Condition; // Only deliver when the query result satisfies the condition
+  skip_unchanged?: '' | 'identical' | 'similar'; // Skip reports unchanged since the last one sent
+  retry_policy?: RetryPolicy; // Overrides the org's retry policy
+  run_timeout_seconds?: number; // Deadline of a run including retries (0 = org default)
+  start_date?: string;
+  end_date?: string;
+  max_occurrences?: number;
+  spread_minutes?: number;
   format: 'pdf' | 'html';
   variables?: Record<string, string>;
   recipients: Recipients;